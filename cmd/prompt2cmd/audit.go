@@ -0,0 +1,114 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/user"
+
+	"github.com/elecmonkey/prompt2cmd/internal/auditlog"
+	"github.com/elecmonkey/prompt2cmd/internal/config"
+	"github.com/elecmonkey/prompt2cmd/internal/i18n"
+	"github.com/elecmonkey/prompt2cmd/internal/llm"
+	"github.com/elecmonkey/prompt2cmd/internal/processor"
+	"github.com/elecmonkey/prompt2cmd/internal/security"
+)
+
+// newAuditEntry 根据命令和风险评估创建审计日志记录
+func newAuditEntry(prompt, generatedCommand, command string, assessment *security.RiskAssessment) *auditlog.Entry {
+	entry := &auditlog.Entry{
+		Prompt:           prompt,
+		GeneratedCommand: generatedCommand,
+		Command:          command,
+		Edited:           command != generatedCommand,
+		RiskLevel:        assessment.Level.String(),
+		RiskReasons:      assessment.Reasons,
+		Blocked:          assessment.Blocked,
+	}
+	if currentUser, err := user.Current(); err == nil {
+		entry.User = currentUser.Username
+	}
+	entry.Host, _ = os.Hostname()
+	entry.Cwd, _ = os.Getwd()
+	return entry
+}
+
+// setAuditExecution 记录命令的退出码和审计结果
+func setAuditExecution(entry *auditlog.Entry, execution *processor.ExecutionResult, auditResult *llm.ExecutionAuditResult) {
+	if execution != nil {
+		exitCode := execution.ExitCode
+		entry.ExitCode = &exitCode
+	}
+
+	if auditResult != nil {
+		success := auditResult.Success
+		entry.AuditSuccess = &success
+		entry.AuditDescription = auditResult.Description
+	}
+}
+
+// appendAuditEntry 写入审计日志，logger 为 nil 时不记录
+func appendAuditEntry(logger *auditlog.Logger, entry *auditlog.Entry) {
+	if logger == nil {
+		return
+	}
+	if err := logger.Append(entry); err != nil {
+		fmt.Printf("⚠️ %s\n", i18n.T("写入审计日志失败: %s", err.Error()))
+	}
+}
+
+// configuredAuditLogFile 返回配置的审计日志路径
+// 只合并配置层而不构建完整配置，避免为了读取路径运行凭据命令或访问系统密钥环
+func configuredAuditLogFile() string {
+	configManager, err := newConfigManager()
+	if err != nil {
+		return config.DefaultAuditLogFile()
+	}
+	resolved, err := configManager.Resolve()
+	if err != nil {
+		return config.DefaultAuditLogFile()
+	}
+	if language, err := i18n.Parse(resolved.Values["UI_LANGUAGE"]); err == nil {
+		i18n.SetLanguage(language)
+	}
+	if path := resolved.Values["AUDIT_LOG_FILE"]; path != "" {
+		return path
+	}
+	return config.DefaultAuditLogFile()
+}
+
+// runAuditCommand 执行 audit 子命令
+func runAuditCommand(args []string) int {
+	if len(args) == 0 || args[0] != "verify" {
+		fmt.Println(i18n.T(`用法: prompt2cmd audit verify [--file 审计日志文件]`))
+		return 2
+	}
+
+	flags := flag.NewFlagSet("audit verify", flag.ContinueOnError)
+	file := flags.String("file", "", i18n.T("审计日志文件（默认使用配置中的 AUDIT_LOG_FILE）"))
+	if err := flags.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	path := *file
+	if path == "" {
+		path = configuredAuditLogFile()
+	}
+
+	result, err := auditlog.Verify(path)
+	if err != nil {
+		fmt.Printf("❌ %s\n", err.Error())
+		return 1
+	}
+
+	fmt.Printf("📄 %s\n", i18n.T("审计日志: %s", path))
+	if !result.OK() {
+		fmt.Printf("❌ %s\n", i18n.T("审计日志已被篡改（前 %d 条记录校验通过）", result.Entries))
+		for _, problem := range result.Problems {
+			fmt.Printf("  - %s\n", problem)
+		}
+		return 1
+	}
+	fmt.Printf("✅ %s\n", i18n.T("审计日志完整，共 %d 条记录", result.Entries))
+	return 0
+}
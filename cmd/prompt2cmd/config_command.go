@@ -0,0 +1,261 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"golang.org/x/term"
+
+	"github.com/elecmonkey/prompt2cmd/internal/config"
+	"github.com/elecmonkey/prompt2cmd/internal/i18n"
+	"github.com/elecmonkey/prompt2cmd/internal/secret"
+)
+
+// configUsage config 子命令的用法
+const configUsage = `用法:
+  prompt2cmd config show [--origin]              显示生效的配置，--origin 同时显示每一项来自哪个配置层
+  prompt2cmd config set-key [--provider <名称>]   将API密钥保存到系统密钥环，可以从管道读取密钥
+  prompt2cmd config delete-key [--provider <名称>] 从系统密钥环删除API密钥`
+
+// runConfigCommand 执行 config 子命令
+func runConfigCommand(args []string) int {
+	if len(args) == 0 {
+		fmt.Println(i18n.Translate(configUsage))
+		return 2
+	}
+	switch args[0] {
+	case "show":
+		return runConfigShow(args[1:])
+	case "set-key":
+		return runConfigSetKey(args[1:])
+	case "delete-key":
+		return runConfigDeleteKey(args[1:])
+	default:
+		fmt.Printf("❌ %s\n", i18n.T("未知的 config 子命令: %s", args[0]))
+		fmt.Println(i18n.Translate(configUsage))
+		return 2
+	}
+}
+
+// runConfigShow 显示合并后的配置，配置无效时仍显示各个配置层中设置的值
+func runConfigShow(args []string) int {
+	flags := flag.NewFlagSet("config show", flag.ContinueOnError)
+	showOrigin := flags.Bool("origin", false, i18n.T("显示每个配置项的来源"))
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	configManager, err := newConfigManager()
+	if err != nil {
+		fmt.Printf("❌ %s\n", err.Error())
+		return 1
+	}
+	resolved, err := configManager.Resolve()
+	if err != nil {
+		fmt.Printf("❌ %s\n", err.Error())
+		return 1
+	}
+	cfg, loadErr := resolved.Config()
+	if cfg != nil {
+		i18n.SetLanguage(cfg.Language)
+	}
+
+	if resolved.Profile != "" {
+		fmt.Println(i18n.T("配置档案: %s", resolved.Profile))
+	}
+	if len(resolved.Files) == 0 {
+		fmt.Println(i18n.T("配置文件: 无"))
+	} else {
+		fmt.Println(i18n.T("配置文件（后面的覆盖前面的）:"))
+		for _, file := range resolved.Files {
+			fmt.Printf("  %s\n", file.String())
+		}
+	}
+	for _, warning := range resolved.Warnings {
+		fmt.Printf("⚠️ %s\n", warning)
+	}
+	fmt.Println()
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	if *showOrigin {
+		fmt.Fprintln(writer, i18n.T("配置项\t值\t来源"))
+	} else {
+		fmt.Fprintln(writer, i18n.T("配置项\t值"))
+	}
+	for _, setting := range config.Settings {
+		value, ok := resolved.Values[setting.Env]
+		if cfg != nil {
+			value = cfg.SettingValue(setting.Env)
+		} else if !ok {
+			value = i18n.T("（未设置）")
+		}
+		if setting.Env == "LLM_API_KEY" {
+			value = maskSecret(value)
+			if cfg != nil && value == "" && cfg.APIKeySource != "" {
+				// 凭据命令和密钥环中的密钥只在使用时获取
+				value = i18n.T("（使用时获取）")
+			}
+		}
+		if value == "" {
+			value = "-"
+		}
+		if *showOrigin {
+			origin := resolved.Origin(setting.Env).String()
+			if setting.Env == "LLM_API_KEY" && cfg != nil {
+				origin = apiKeyOrigin(cfg, origin)
+			}
+			fmt.Fprintf(writer, "%s\t%s\t%s\n", setting.Path, value, origin)
+		} else {
+			fmt.Fprintf(writer, "%s\t%s\n", setting.Path, value)
+		}
+	}
+	writer.Flush()
+
+	if loadErr != nil {
+		fmt.Printf("\n❌ %s\n", i18n.T("配置无效: %s", loadErr.Error()))
+		return 1
+	}
+	return 0
+}
+
+// maskSecret 只显示密钥的开头和结尾
+func maskSecret(secret string) string {
+	runes := []rune(secret)
+	if len(runes) == 0 || secret == i18n.T("（未设置）") {
+		return secret
+	}
+	if len(runes) <= 10 {
+		return "****"
+	}
+	return string(runes[:3]) + "****" + string(runes[len(runes)-4:])
+}
+
+// apiKeyOrigin 返回API密钥的来源说明
+func apiKeyOrigin(cfg *config.Config, origin string) string {
+	switch cfg.APIKeySource {
+	case config.APIKeyFromCommand:
+		return i18n.T("凭据命令 %s", cfg.APIKeyCommand)
+	case config.APIKeyFromKeyring:
+		return i18n.T("系统密钥环 %s", secret.DefaultKeyring().Name())
+	default:
+		return origin
+	}
+}
+
+// keyProvider 解析 set-key 和 delete-key 的参数，返回要操作的提供商
+// 未指定时使用当前配置中的提供商
+func keyProvider(name string, args []string) (string, *config.Resolved, bool) {
+	flags := flag.NewFlagSet("config "+name, flag.ContinueOnError)
+	provider := flags.String("provider", "", i18n.T("LLM提供商，默认为当前配置的提供商"))
+	if err := flags.Parse(args); err != nil {
+		return "", nil, false
+	}
+
+	configManager, err := newConfigManager()
+	if err != nil {
+		fmt.Printf("❌ %s\n", err.Error())
+		return "", nil, false
+	}
+	resolved, err := configManager.Resolve()
+	if err != nil {
+		fmt.Printf("❌ %s\n", err.Error())
+		return "", nil, false
+	}
+	if *provider == "" {
+		*provider = resolved.Values["LLM_PROVIDER"]
+	}
+	if *provider == "" {
+		*provider = "deepseek"
+	}
+	preset, ok := config.FindProviderPreset(*provider)
+	if !ok {
+		fmt.Printf("❌ %s\n", i18n.T("不支持的LLM提供商: %s", *provider))
+		return "", nil, false
+	}
+	if preset.Local {
+		fmt.Printf("❌ %s\n", i18n.T("%s 是本地模型，不需要API密钥", *provider))
+		return "", nil, false
+	}
+	return *provider, resolved, true
+}
+
+// runConfigSetKey 将API密钥保存到系统密钥环
+// 在终端中输入时不回显；也可以从管道读取，例如 pass show deepseek | prompt2cmd config set-key
+func runConfigSetKey(args []string) int {
+	provider, resolved, ok := keyProvider("set-key", args)
+	if !ok {
+		return 2
+	}
+
+	key, err := readAPIKey(provider, bufio.NewReader(os.Stdin))
+	if err != nil {
+		fmt.Printf("❌ %s\n", err.Error())
+		return 1
+	}
+
+	keyring := secret.DefaultKeyring()
+	if err := keyring.Set(provider, key); err != nil {
+		fmt.Printf("❌ %s\n", i18n.T("保存到%s失败: %s", keyring.Name(), err.Error()))
+		fmt.Println(i18n.T("也可以在配置文件中设置凭据命令 llm.api_key_command，例如 pass show %s", provider))
+		return 1
+	}
+	fmt.Printf("✅ %s\n", i18n.T("已将 %s 的API密钥保存到%s", provider, keyring.Name()))
+
+	// 直接配置的密钥优先于密钥环，提醒删除明文密钥
+	if origin, ok := resolved.Origins["LLM_API_KEY"]; ok {
+		fmt.Printf("⚠️ %s\n", i18n.T("%s 中设置的 llm.api_key 优先于密钥环，建议删除其中的明文密钥", origin.String()))
+	}
+	if origin, ok := resolved.Origins["LLM_API_KEY_COMMAND"]; ok {
+		fmt.Printf("⚠️ %s\n", i18n.T("%s 中设置了凭据命令 llm.api_key_command，设置凭据命令时不会读取密钥环", origin.String()))
+	}
+	return 0
+}
+
+// readAPIKey 读取API密钥，在终端中输入时不回显，否则从 reader 读取一行
+func readAPIKey(provider string, reader *bufio.Reader) (string, error) {
+	var key string
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		fmt.Printf("🔑 %s", i18n.T("请输入 %s 的API密钥（输入时不显示）: ", provider))
+		input, err := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Println()
+		if err != nil {
+			return "", errors.New(i18n.T("读取API密钥失败: %s", err.Error()))
+		}
+		key = string(input)
+	} else {
+		line, err := reader.ReadString('\n')
+		if err != nil && line == "" {
+			return "", errors.New(i18n.T("读取API密钥失败: %s", err.Error()))
+		}
+		key = line
+	}
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return "", errors.New(i18n.T("API密钥不能为空"))
+	}
+	return key, nil
+}
+
+// runConfigDeleteKey 从系统密钥环删除API密钥
+func runConfigDeleteKey(args []string) int {
+	provider, _, ok := keyProvider("delete-key", args)
+	if !ok {
+		return 2
+	}
+	keyring := secret.DefaultKeyring()
+	if err := keyring.Delete(provider); err != nil {
+		if errors.Is(err, secret.ErrNotFound) {
+			fmt.Println(i18n.T("%s中没有 %s 的API密钥", keyring.Name(), provider))
+			return 0
+		}
+		fmt.Printf("❌ %s\n", i18n.T("删除失败: %s", err.Error()))
+		return 1
+	}
+	fmt.Printf("✅ %s\n", i18n.T("已从%s删除 %s 的API密钥", keyring.Name(), provider))
+	return 0
+}
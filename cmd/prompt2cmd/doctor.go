@@ -0,0 +1,354 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/elecmonkey/prompt2cmd/internal/config"
+	"github.com/elecmonkey/prompt2cmd/internal/history"
+	"github.com/elecmonkey/prompt2cmd/internal/i18n"
+	"github.com/elecmonkey/prompt2cmd/internal/llm"
+	"github.com/elecmonkey/prompt2cmd/internal/llm/prompts"
+	"github.com/elecmonkey/prompt2cmd/internal/snippet"
+)
+
+// doctorTimeout 检查模型接口的超时时间
+const doctorTimeout = 10 * time.Second
+
+// checkStatus 检查结果
+type checkStatus int
+
+const (
+	checkPass checkStatus = iota
+	checkWarn
+	checkFail
+	checkSkip
+)
+
+// doctorCheck 一项检查的结果
+type doctorCheck struct {
+	Name   string // 检查项名称，显示时翻译
+	Status checkStatus
+	Detail string
+	Hint   string // 未通过时的修复建议
+}
+
+// doctor 依次执行各项检查并收集结果
+type doctor struct {
+	offline bool
+	checks  []doctorCheck
+}
+
+// add 记录一项检查的结果
+func (d *doctor) add(name string, status checkStatus, detail, hint string) {
+	d.checks = append(d.checks, doctorCheck{Name: name, Status: status, Detail: detail, Hint: hint})
+}
+
+// runDoctorCommand 执行 doctor 子命令，检查配置、文件权限、模型接口、shell和历史记录
+// 有检查未通过时返回 1
+func runDoctorCommand(args []string) int {
+	flags := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	offline := flags.Bool("offline", false, i18n.T("不连接模型接口"))
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	d := &doctor{offline: *offline}
+	cfg := d.checkConfig()
+	d.checkWritable("配置目录", filepath.Dir(config.DefaultConfigFile()), i18n.T("检查目录权限，或通过 XDG_CONFIG_HOME 指定其他配置目录"))
+	if cfg != nil {
+		d.checkProvider(cfg)
+		d.checkShell(cfg)
+		d.checkHistory(cfg)
+		d.checkOptionalFiles(cfg)
+	}
+	return d.report()
+}
+
+// checkConfig 检查配置文件能否解析、各个配置项是否有效，配置无效时返回 nil
+func (d *doctor) checkConfig() *config.Config {
+	configManager, err := newConfigManager()
+	if err != nil {
+		d.add("配置文件", checkFail, err.Error(), "")
+		return nil
+	}
+	resolved, err := configManager.Resolve()
+	if err != nil {
+		d.add("配置文件", checkFail, err.Error(), i18n.T("修改配置文件中报错的行，或运行 prompt2cmd init 重新生成配置文件"))
+		return nil
+	}
+	if len(resolved.Files) == 0 {
+		d.add("配置文件", checkWarn, i18n.T("没有找到配置文件，只使用环境变量和默认值"), i18n.T("运行 prompt2cmd init 生成配置文件"))
+	} else {
+		files := make([]string, len(resolved.Files))
+		for i, file := range resolved.Files {
+			files[i] = file.Source
+		}
+		d.add("配置文件", checkPass, strings.Join(files, ", "), "")
+	}
+	for _, warning := range resolved.Warnings {
+		d.add("配置文件", checkWarn, warning, "")
+	}
+
+	cfg, err := resolved.Config()
+	if err != nil {
+		d.add("配置项", checkFail, err.Error(), configHint(resolved, err))
+		return nil
+	}
+	i18n.SetLanguage(cfg.Language)
+	detail := i18n.T("提供商 %s，模型 %s，地址 %s", cfg.LLMProvider, cfg.LLMModel, cfg.LLMBaseURL)
+	if cfg.Profile != "" {
+		detail += i18n.T("，配置档案 %s", cfg.Profile)
+	}
+	d.add("配置项", checkPass, detail, "")
+
+	// 凭据命令和密钥环中的密钥平时在使用时才获取，这里获取一次以确认可用
+	if err := cfg.ResolveAPIKey(); err != nil {
+		d.add("API密钥", checkFail, err.Error(), "")
+		return nil
+	}
+	switch {
+	case cfg.APIKeySource == config.APIKeyFromCommand:
+		d.add("API密钥", checkPass, i18n.T("来自凭据命令 %s", cfg.APIKeyCommand), "")
+	case cfg.APIKeySource == config.APIKeyFromKeyring:
+		d.add("API密钥", checkPass, i18n.T("来自系统密钥环"), "")
+	case cfg.LLMAPIKey != "":
+		// 明文写在配置文件中的密钥可以正常使用，但建议改为密钥环或凭据命令
+		origin := resolved.Origin("LLM_API_KEY")
+		if origin.Layer == config.LayerEnv || origin.Layer == config.LayerFlag {
+			d.add("API密钥", checkPass, i18n.T("来自%s", origin.String()), "")
+		} else {
+			d.add("API密钥", checkWarn, i18n.T("明文保存在%s", origin.String()), i18n.T("运行 prompt2cmd config set-key 将密钥保存到系统密钥环，并从配置文件中删除明文密钥"))
+		}
+	default:
+		d.add("API密钥", checkSkip, i18n.T("本地模型不需要API密钥"), "")
+	}
+	return cfg
+}
+
+// configHint 根据错误信息中的配置项名称指出它的来源
+func configHint(resolved *config.Resolved, err error) string {
+	for _, setting := range config.Settings {
+		if strings.Contains(err.Error(), setting.Env) {
+			origin := resolved.Origin(setting.Env)
+			if origin.Layer == config.LayerDefault {
+				break
+			}
+			return i18n.T("%s（%s）来自%s", setting.Path, setting.Env, origin.String())
+		}
+	}
+	return i18n.T("运行 prompt2cmd config show --origin 查看每个配置项的来源")
+}
+
+// checkProvider 请求模型列表接口，检查地址、API密钥和模型名称
+func (d *doctor) checkProvider(cfg *config.Config) {
+	if d.offline {
+		d.add("模型接口", checkSkip, i18n.T("离线模式，未连接 %s", cfg.LLMBaseURL), "")
+		return
+	}
+
+	models, err := llm.ListModels(cfg.LLMBaseURL, cfg.LLMAPIKey, doctorTimeout)
+	if err != nil {
+		hint := i18n.T("检查网络连接和 llm.base_url")
+		var statusErr *llm.StatusError
+		if errors.As(err, &statusErr) {
+			switch statusErr.StatusCode {
+			case 401, 403:
+				hint = i18n.T("API密钥无效或没有权限，运行 prompt2cmd config set-key 重新保存密钥")
+			case 404:
+				hint = i18n.T("地址不是 OpenAI 兼容接口，检查 llm.base_url 是否缺少 /v1 等路径")
+			}
+		} else if cfg.LLMProvider == "ollama" {
+			hint = i18n.T("确认 Ollama 正在运行（ollama serve），并检查 llm.base_url")
+		}
+		d.add("模型接口", checkFail, err.Error(), hint)
+		return
+	}
+	d.add("模型接口", checkPass, i18n.T("%s 可用，共 %d 个模型", cfg.LLMBaseURL, len(models)), "")
+
+	d.checkModel("模型", "llm.model", cfg.LLMModel, cfg.LLMProvider, models)
+	if cfg.AuditModel != "" {
+		d.checkModel("审计模型", "llm.audit_model", cfg.AuditModel, cfg.LLMProvider, models)
+	}
+}
+
+// checkModel 检查模型是否在提供商的模型列表中，列表为空时不检查
+func (d *doctor) checkModel(name, setting, model, provider string, models []string) {
+	if len(models) > 0 && !slices.Contains(models, model) {
+		hint := i18n.T("检查 %s，可用的模型: %s", setting, strings.Join(truncateList(models, 8), ", "))
+		if provider == "ollama" {
+			hint = i18n.T("运行 ollama pull %s 下载模型，或修改 %s", model, setting)
+		}
+		d.add(name, checkFail, i18n.T("模型列表中没有 %s", model), hint)
+		return
+	}
+	d.add(name, checkPass, model, "")
+}
+
+// truncateList 最多保留 limit 项
+func truncateList(items []string, limit int) []string {
+	if len(items) <= limit {
+		return items
+	}
+	return append(items[:limit:limit], "...")
+}
+
+// checkShell 检查执行命令使用的shell是否存在
+func (d *doctor) checkShell(cfg *config.Config) {
+	path, err := exec.LookPath(cfg.Shell)
+	if err != nil {
+		d.add("Shell", checkFail, i18n.T("找不到 %s", cfg.Shell), i18n.T("安装该shell或修改配置项 shell"))
+		return
+	}
+	d.add("Shell", checkPass, path, "")
+}
+
+// checkHistory 检查历史记录文件所在目录是否可写，以及已有的文件能否解析
+func (d *doctor) checkHistory(cfg *config.Config) {
+	var status *history.FileStatus
+	var err error
+	if cfg.HistoryBackend == "sqlite" {
+		status, err = history.CheckSQLiteHistory(history.DefaultSQLitePath())
+	} else {
+		status, err = history.CheckJSONHistory(history.DefaultJSONPath())
+	}
+
+	if !d.checkWritable("历史记录目录", filepath.Dir(status.Path), i18n.T("检查目录权限，或通过 XDG_DATA_HOME 指定其他数据目录")) {
+		return
+	}
+	switch {
+	case err != nil:
+		hint := i18n.T("备份后删除该文件，程序会重新创建")
+		if cfg.HistoryBackend == "json" {
+			hint = i18n.T("修复或删除该文件；程序下次启动时会备份损坏的文件并使用空历史记录")
+		}
+		d.add("历史记录", checkFail, status.Path+": "+err.Error(), hint)
+	case !status.Exists:
+		d.add("历史记录", checkPass, i18n.T("%s 尚未创建", status.Path), "")
+	default:
+		d.add("历史记录", checkPass, i18n.T("%s，%d 条记录", status.Path, status.Records), "")
+	}
+}
+
+// checkOptionalFiles 检查本地模型、安全策略、审计日志和团队配方
+func (d *doctor) checkOptionalFiles(cfg *config.Config) {
+	if cfg.UseLocalModel {
+		if _, err := os.Stat(cfg.LocalModelPath); err != nil {
+			d.add("本地模型", checkFail, i18n.T("无法访问 %s", cfg.LocalModelPath), i18n.T("检查 llm.local_model_path"))
+		} else {
+			d.add("本地模型", checkPass, cfg.LocalModelPath, "")
+		}
+	}
+
+	if cfg.SecurityPolicy != nil {
+		d.add("安全策略", checkPass, i18n.T("%s，%d 条规则", cfg.SecurityPolicy.File, len(cfg.SecurityPolicy.Rules)), "")
+	}
+
+	d.checkPromptTemplates(cfg)
+
+	if cfg.AuditLogEnabled {
+		d.checkWritable("审计日志", filepath.Dir(cfg.AuditLogFile), i18n.T("检查目录权限，或修改 audit.file"))
+	}
+
+	if _, err := os.Stat(cfg.RecipesDir); err == nil {
+		catalog, problems := snippet.LoadCatalog(cfg.RecipesDir)
+		if len(problems) > 0 {
+			d.add("团队配方", checkWarn, i18n.T("%d 个配方无效: %s", len(problems), problems[0].Error()), i18n.T("运行 prompt2cmd recipe list 查看全部问题"))
+		} else {
+			d.add("团队配方", checkPass, i18n.T("%s，%d 个配方", cfg.RecipesDir, len(catalog.Recipes)), "")
+		}
+	} else if cfg.RecipesRepo != "" {
+		d.add("团队配方", checkWarn, i18n.T("尚未同步 %s", cfg.RecipesRepo), i18n.T("运行 prompt2cmd recipe sync"))
+	}
+}
+
+// checkPromptTemplates 检查用户自定义的提示词模板能否渲染
+func (d *doctor) checkPromptTemplates(cfg *config.Config) {
+	cwd, _ := os.Getwd()
+	for _, name := range prompts.Names {
+		tmpl, err := prompts.Load(name)
+		if err != nil {
+			d.add("提示词模板", checkFail, err.Error(), i18n.T("检查 %s 的权限", prompts.Dir()))
+			continue
+		}
+		if tmpl.Source == "" {
+			continue
+		}
+		if _, err := tmpl.Render(prompts.NewData(cfg.Shell, cwd)); err != nil {
+			d.add("提示词模板", checkFail, err.Error(), i18n.T("修改模板，或删除该文件以使用内置模板"))
+			continue
+		}
+		d.add("提示词模板", checkPass, tmpl.Source, "")
+	}
+}
+
+// checkWritable 检查目录是否可写，目录不存在时检查能否创建
+func (d *doctor) checkWritable(name, dir, hint string) bool {
+	existing := dir
+	for {
+		if _, err := os.Stat(existing); err == nil {
+			break
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			break
+		}
+		existing = parent
+	}
+
+	file, err := os.CreateTemp(existing, ".prompt2cmd-doctor-*")
+	if err != nil {
+		d.add(name, checkFail, i18n.T("%s 不可写: %s", dir, err.Error()), hint)
+		return false
+	}
+	file.Close()
+	os.Remove(file.Name())
+
+	detail := dir
+	if existing != dir {
+		detail += i18n.T(" 尚未创建，将在首次使用时创建")
+	}
+	d.add(name, checkPass, detail, "")
+	return true
+}
+
+// report 输出检查结果，有检查未通过时返回 1
+func (d *doctor) report() int {
+	failed, warned := 0, 0
+	for _, check := range d.checks {
+		icon := "✅"
+		switch check.Status {
+		case checkWarn:
+			icon = "⚠️"
+			warned++
+		case checkFail:
+			icon = "❌"
+			failed++
+		case checkSkip:
+			icon = "➖"
+		}
+		fmt.Printf("%s %s: %s\n", icon, i18n.Translate(check.Name), check.Detail)
+		if check.Hint != "" {
+			fmt.Printf("   💡 %s\n", check.Hint)
+		}
+	}
+
+	fmt.Println()
+	switch {
+	case failed > 0:
+		fmt.Println(i18n.T("有 %d 项检查未通过，%d 项警告", failed, warned))
+		return 1
+	case warned > 0:
+		fmt.Println(i18n.T("检查通过，%d 项警告", warned))
+	default:
+		fmt.Println(i18n.T("检查全部通过"))
+	}
+	return 0
+}
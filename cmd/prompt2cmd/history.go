@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/elecmonkey/prompt2cmd/internal/config"
+	"github.com/elecmonkey/prompt2cmd/internal/history"
+	"github.com/elecmonkey/prompt2cmd/internal/i18n"
+	"github.com/elecmonkey/prompt2cmd/internal/llm"
+	"github.com/elecmonkey/prompt2cmd/internal/processor"
+	"github.com/elecmonkey/prompt2cmd/internal/security"
+)
+
+// newHistoryManager 按配置创建历史记录存储，SQLite不可用时回退到JSON文件
+func newHistoryManager(cfg *config.Config) history.HistoryStore {
+	if cfg.HistoryBackend == "sqlite" {
+		sqliteHistory, err := history.NewSQLiteCommandHistory("", cfg.MaxHistorySize)
+		if err == nil {
+			return sqliteHistory
+		}
+		fmt.Printf("⚠️ %s\n", i18n.T("SQLite历史记录不可用，改用JSON文件: %s", err.Error()))
+	}
+
+	fileHistory, err := history.NewFileCommandHistory("", cfg.MaxHistorySize)
+	if err != nil {
+		fmt.Printf("⚠️ %s\n", i18n.T("历史记录功能不可用: %s", err.Error()))
+		fmt.Println(i18n.T("程序将继续运行，但不会记录命令历史"))
+		// 创建一个临时的内存历史记录管理器
+		return &history.FileCommandHistory{
+			MaxRecords: cfg.MaxHistorySize,
+		}
+	}
+	return fileHistory
+}
+
+// selectHistoryContext 选择与需求相关的历史记录作为生成命令的上下文
+func selectHistoryContext(store history.CommandHistory, cfg *config.Config, prompt string) ([]history.HistoryRecord, error) {
+	budget := cfg.HistoryContextTokens
+	if budget == 0 {
+		// 配置为 0 表示不使用历史记录
+		budget = -1
+	}
+	return history.SelectContext(store, history.ContextOptions{
+		Prompt:      prompt,
+		Cwd:         currentWorkingDir(),
+		TokenBudget: budget,
+	})
+}
+
+// newHistoryRecord 根据一次执行创建历史记录
+func newHistoryRecord(cfg *config.Config, prompt, generatedCommand, command string, assessment *security.RiskAssessment,
+	execution *processor.ExecutionResult, auditResult *llm.ExecutionAuditResult) history.HistoryRecord {
+	record := history.HistoryRecord{
+		Prompt:           prompt,
+		Command:          command,
+		GeneratedCommand: generatedCommand,
+		Edited:           command != generatedCommand,
+		RiskLevel:        assessment.Level.String(),
+		RiskReasons:      assessment.Reasons,
+		Provider:         cfg.LLMProvider,
+		Model:            cfg.LLMModel,
+	}
+	if execution != nil {
+		exitCode := execution.ExitCode
+		record.Executed = true
+		record.Shell = execution.Shell
+		record.ExitCode = &exitCode
+		record.DurationMs = execution.Duration.Milliseconds()
+		record.Stdout = history.Excerpt(execution.Stdout)
+		record.Stderr = history.Excerpt(execution.Stderr)
+	}
+	if auditResult != nil {
+		record.Audit = &history.AuditVerdict{
+			Success:     auditResult.Success,
+			Description: auditResult.Description,
+		}
+	}
+	return record
+}
+
+// showRecentHistory 显示最近的历史记录，用于 /history 指令
+func (s *session) showRecentHistory(limit int) {
+	result, err := s.historyManager.Search(history.SearchOptions{Limit: limit})
+	if err != nil {
+		s.userInterface.DisplayError(err)
+		return
+	}
+	fmt.Println()
+	printHistoryTable(result.Records, 0)
+	if len(result.Records) > 0 {
+		fmt.Println("\n" + i18n.T("使用 /rerun <编号> 重新执行（仍需确认）"))
+	}
+}
+
+// rerunHistory 重新执行历史记录中的命令，不再请求模型生成，但仍然经过安全检查和确认
+func (s *session) rerunHistory(ref string) bool {
+	record, err := resolveHistoryRecord(s.historyManager, ref)
+	if err != nil {
+		s.userInterface.DisplayError(err)
+		return false
+	}
+
+	fmt.Printf("\n🔁 %s\n", i18n.T("重新执行历史记录 %s（原需求：%s）", record.ID, record.Prompt))
+	if record.Cwd != "" && record.Cwd != currentWorkingDir() {
+		fmt.Printf("⚠️ %s\n", i18n.T("该命令原先在 %s 中执行，当前目录为 %s", record.Cwd, currentWorkingDir()))
+	}
+	s.userInterface.DisplayGeneratedCommand(record.Command, i18n.T("来自历史记录"))
+	s.runCommand(record.Prompt, record.Command)
+	return true
+}
+
+// currentWorkingDir 返回当前工作目录，获取失败时返回空字符串
+func currentWorkingDir() string {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	return cwd
+}
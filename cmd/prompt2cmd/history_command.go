@@ -0,0 +1,392 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+	"unicode/utf8"
+
+	"github.com/elecmonkey/prompt2cmd/internal/config"
+	"github.com/elecmonkey/prompt2cmd/internal/history"
+	"github.com/elecmonkey/prompt2cmd/internal/i18n"
+)
+
+// historyUsage history 子命令的用法
+const historyUsage = `用法:
+  prompt2cmd history list   [--limit N] [--offset N] [--json]
+  prompt2cmd history search <关键词> [--since 日期] [--until 日期] [--cwd 目录] [--success|--failed] [--limit N] [--offset N] [--json]
+  prompt2cmd history show   <编号|ID> [--json]
+  prompt2cmd history rerun  <编号|ID>
+  prompt2cmd history delete <编号|ID>
+  prompt2cmd history clear  [--yes]
+  prompt2cmd history export [--format bash|zsh|fish] [--output 文件|--histfile] [--since 日期]
+  prompt2cmd history import [--format bash|zsh|fish] [--file 文件] [--limit N] [--dry-run]
+
+编号是 list 输出中的 # 列，1 表示最近一条`
+
+// runHistoryCommand 执行 history 子命令
+func runHistoryCommand(args []string) int {
+	if len(args) == 0 {
+		fmt.Println(i18n.Translate(historyUsage))
+		return 2
+	}
+
+	switch args[0] {
+	case "list", "search":
+		return runHistorySearch(args[0], args[1:])
+	case "show":
+		return runHistoryShow(args[1:])
+	case "rerun":
+		return runHistoryRerun(args[1:])
+	case "delete":
+		return runHistoryDelete(args[1:])
+	case "clear":
+		return runHistoryClear(args[1:])
+	case "export":
+		return runHistoryExport(args[1:])
+	case "import":
+		return runHistoryImport(args[1:])
+	default:
+		fmt.Printf("❌ %s\n", i18n.T("未知的 history 子命令: %s", args[0]))
+		fmt.Println(i18n.Translate(historyUsage))
+		return 2
+	}
+}
+
+// openHistoryStore 按配置打开历史记录；配置无法加载时（例如未设置API密钥）使用默认存储
+func openHistoryStore() history.HistoryStore {
+	cfg, err := loadConfig()
+	if err != nil {
+		cfg = &config.Config{HistoryBackend: "sqlite", MaxHistorySize: 50}
+	}
+	return newHistoryManager(cfg)
+}
+
+// parseInterspersed 解析参数，允许选项出现在位置参数之后，返回位置参数
+func parseInterspersed(flags *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, err
+		}
+		args = flags.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// parseDate 解析日期，支持 2006-01-02 和 RFC3339 格式
+// endOfDay 为 true 时，只有日期的参数表示当天结束，便于 --until 包含当天
+func parseDate(value string, endOfDay bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, errors.New(i18n.T("无法解析日期 %s，请使用 2006-01-02 或 RFC3339 格式", value))
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+// runHistorySearch 执行 history list / history search
+func runHistorySearch(name string, args []string) int {
+	flags := flag.NewFlagSet("history "+name, flag.ContinueOnError)
+	limit := flags.Int("limit", 20, i18n.T("每页数量，0 表示全部"))
+	offset := flags.Int("offset", 0, i18n.T("跳过的记录数"))
+	jsonOutput := flags.Bool("json", false, i18n.T("以JSON格式输出"))
+	since := flags.String("since", "", i18n.T("不早于该日期"))
+	until := flags.String("until", "", i18n.T("不晚于该日期"))
+	cwd := flags.String("cwd", "", i18n.T("只显示在该目录或其子目录中执行的记录"))
+	success := flags.Bool("success", false, i18n.T("只显示执行成功的记录"))
+	failed := flags.Bool("failed", false, i18n.T("只显示未执行或执行失败的记录"))
+	positional, err := parseInterspersed(flags, args)
+	if err != nil {
+		return 2
+	}
+
+	options := history.SearchOptions{
+		Query:  strings.Join(positional, " "),
+		Cwd:    *cwd,
+		Limit:  *limit,
+		Offset: *offset,
+	}
+	if name == "search" && strings.TrimSpace(options.Query) == "" && *since == "" && *until == "" && *cwd == "" && !*success && !*failed {
+		fmt.Println("❌ " + i18n.T("请提供搜索关键词或过滤条件"))
+		return 2
+	}
+	if *since != "" {
+		if options.Since, err = parseDate(*since, false); err != nil {
+			fmt.Printf("❌ %s\n", err.Error())
+			return 2
+		}
+	}
+	if *until != "" {
+		if options.Until, err = parseDate(*until, true); err != nil {
+			fmt.Printf("❌ %s\n", err.Error())
+			return 2
+		}
+	}
+	if *success && *failed {
+		fmt.Println("❌ " + i18n.T("--success 和 --failed 不能同时使用"))
+		return 2
+	}
+	if *success || *failed {
+		options.Success = success
+	}
+
+	result, err := openHistoryStore().Search(options)
+	if err != nil {
+		fmt.Printf("❌ %s\n", err.Error())
+		return 1
+	}
+
+	if *jsonOutput {
+		return printJSON(result)
+	}
+	printHistoryTable(result.Records, options.Offset)
+	if result.Total > options.Offset+len(result.Records) {
+		fmt.Printf("\n%s\n", i18n.T("共 %d 条，显示第 %d-%d 条（使用 --offset %d 查看更多）",
+			result.Total, options.Offset+1, options.Offset+len(result.Records), options.Offset+len(result.Records)))
+	}
+	return 0
+}
+
+// printHistoryTable 以表格显示记录，start 为第一条记录之前的记录数，用于计算编号
+func printHistoryTable(records []history.HistoryRecord, start int) {
+	if len(records) == 0 {
+		fmt.Println(i18n.T("没有历史记录"))
+		return
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, i18n.T("#\tID\t时间\t状态\t命令\t需求"))
+	for i, record := range records {
+		timestamp := record.Timestamp
+		if t, err := time.Parse(time.RFC3339, record.Timestamp); err == nil {
+			timestamp = t.Local().Format("2006-01-02 15:04")
+		}
+		fmt.Fprintf(writer, "%d\t%s\t%s\t%s\t%s\t%s\n", start+i+1, record.ID, timestamp,
+			recordStatus(record), truncateText(record.Command, 50), truncateText(record.Prompt, 30))
+	}
+	writer.Flush()
+}
+
+// recordStatus 返回记录的执行状态
+func recordStatus(record history.HistoryRecord) string {
+	switch {
+	case record.Source != "":
+		return i18n.T("已导入")
+	case !record.Executed:
+		return i18n.T("未执行")
+	case record.ExitCode == nil:
+		return i18n.T("未知")
+	case *record.ExitCode == 0:
+		return i18n.T("成功")
+	default:
+		return i18n.T("失败(%d)", *record.ExitCode)
+	}
+}
+
+// truncateText 截断过长的单行文本
+func truncateText(text string, limit int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if utf8.RuneCountInString(text) <= limit {
+		return text
+	}
+	return string([]rune(text)[:limit-1]) + "…"
+}
+
+// printJSON 以JSON格式输出
+func printJSON(value any) int {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		fmt.Printf("❌ %s\n", i18n.T("序列化失败: %s", err.Error()))
+		return 1
+	}
+	fmt.Println(string(data))
+	return 0
+}
+
+// resolveHistoryRecord 按编号（1 表示最近一条）或ID查找记录
+// 记录ID是纳秒时间戳，不会与较短的编号混淆，因此不超过 9 位的数字按编号处理
+func resolveHistoryRecord(store history.HistoryStore, ref string) (*history.HistoryRecord, error) {
+	ref = strings.TrimPrefix(strings.TrimSpace(ref), "#")
+	if n, err := strconv.Atoi(ref); err == nil && n >= 1 && len(ref) <= 9 {
+		result, err := store.Search(history.SearchOptions{Limit: 1, Offset: n - 1})
+		if err != nil {
+			return nil, err
+		}
+		if len(result.Records) == 0 {
+			return nil, errors.New(i18n.T("%s: 编号 %d 超出范围，共 %d 条记录", history.ErrRecordNotFound.Error(), n, result.Total))
+		}
+		return &result.Records[0], nil
+	}
+
+	record, err := store.Get(ref)
+	if errors.Is(err, history.ErrRecordNotFound) {
+		return nil, fmt.Errorf("%s: %s", err.Error(), ref)
+	}
+	return record, err
+}
+
+// singleRecordArgs 解析只需要一个编号或ID的子命令参数
+func singleRecordArgs(name string, args []string, jsonFlag bool) (string, bool, bool) {
+	flags := flag.NewFlagSet("history "+name, flag.ContinueOnError)
+	jsonOutput := false
+	if jsonFlag {
+		flags.BoolVar(&jsonOutput, "json", false, i18n.T("以JSON格式输出"))
+	}
+	positional, err := parseInterspersed(flags, args)
+	if err != nil {
+		return "", false, false
+	}
+	if len(positional) != 1 {
+		fmt.Printf("❌ %s\n", i18n.T("请提供一个记录编号或ID")+"\n\n"+i18n.Translate(historyUsage))
+		return "", false, false
+	}
+	return positional[0], jsonOutput, true
+}
+
+// runHistoryShow 显示一条记录的详情
+func runHistoryShow(args []string) int {
+	ref, jsonOutput, ok := singleRecordArgs("show", args, true)
+	if !ok {
+		return 2
+	}
+	record, err := resolveHistoryRecord(openHistoryStore(), ref)
+	if err != nil {
+		fmt.Printf("❌ %s\n", err.Error())
+		return 1
+	}
+	if jsonOutput {
+		return printJSON(record)
+	}
+	printHistoryRecord(record)
+	return 0
+}
+
+// printHistoryRecord 显示记录的全部字段
+func printHistoryRecord(record *history.HistoryRecord) {
+	fmt.Printf("ID:       %s\n", record.ID)
+	fmt.Println(i18n.T("时间:     %s", record.Timestamp))
+	fmt.Println(i18n.T("目录:     %s", record.Cwd))
+	if record.Source != "" {
+		fmt.Println(i18n.T("来源:     从 %s 历史导入", record.Source))
+	} else {
+		fmt.Println(i18n.T("需求:     %s", record.Prompt))
+	}
+	fmt.Println(i18n.T("命令:     %s", record.Command))
+	if record.Edited {
+		fmt.Println(i18n.T("原始命令: %s（已编辑）", record.GeneratedCommand))
+	}
+	fmt.Println(i18n.T("状态:     %s", recordStatus(*record)))
+	if record.Shell != "" {
+		fmt.Printf("Shell:    %s\n", record.Shell)
+	}
+	if record.Executed && record.DurationMs > 0 {
+		fmt.Println(i18n.T("耗时:     %s", time.Duration(record.DurationMs)*time.Millisecond))
+	}
+	if record.RiskLevel != "" {
+		fmt.Println(i18n.T("风险等级: %s", record.RiskLevel))
+		for _, reason := range record.RiskReasons {
+			fmt.Printf("          - %s\n", reason)
+		}
+	}
+	if record.Provider != "" {
+		fmt.Println(i18n.T("模型:     %s/%s", record.Provider, record.Model))
+	}
+	if record.Audit != nil {
+		fmt.Println(i18n.T("审计:     %v，%s", record.Audit.Success, record.Audit.Description))
+	}
+	if record.Stdout != "" {
+		fmt.Printf("\n%s\n", i18n.T("标准输出:\n%s", strings.TrimRight(record.Stdout, "\n")))
+	}
+	if record.Stderr != "" {
+		fmt.Printf("\n%s\n", i18n.T("标准错误:\n%s", strings.TrimRight(record.Stderr, "\n")))
+	}
+}
+
+// runHistoryRerun 重新执行一条记录中的命令，仍然经过安全检查和确认
+func runHistoryRerun(args []string) int {
+	ref, _, ok := singleRecordArgs("rerun", args, false)
+	if !ok {
+		return 2
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Printf("❌ %s\n", i18n.T("加载配置失败: %s", err.Error()))
+		return 1
+	}
+	app, err := newSession(cfg)
+	if err != nil {
+		fmt.Printf("❌ %s\n", err.Error())
+		return 1
+	}
+	if !app.rerunHistory(ref) {
+		return 1
+	}
+	return 0
+}
+
+// runHistoryDelete 删除一条记录
+func runHistoryDelete(args []string) int {
+	ref, _, ok := singleRecordArgs("delete", args, false)
+	if !ok {
+		return 2
+	}
+	store := openHistoryStore()
+	record, err := resolveHistoryRecord(store, ref)
+	if err != nil {
+		fmt.Printf("❌ %s\n", err.Error())
+		return 1
+	}
+	if err := store.Delete(record.ID); err != nil {
+		fmt.Printf("❌ %s\n", err.Error())
+		return 1
+	}
+	fmt.Printf("✅ %s\n", i18n.T("已删除记录 %s: %s", record.ID, record.Command))
+	return 0
+}
+
+// runHistoryClear 清空全部记录
+func runHistoryClear(args []string) int {
+	flags := flag.NewFlagSet("history clear", flag.ContinueOnError)
+	yes := flags.Bool("yes", false, i18n.T("不询问直接清空"))
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if !*yes {
+		fmt.Print("❓ " + i18n.T("确定要清空全部历史记录吗? (y/n): "))
+		input, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil {
+			return 1
+		}
+		input = strings.TrimSpace(strings.ToLower(input))
+		if input != "y" && input != "yes" && input != "是" {
+			fmt.Println("❌ " + i18n.T("已取消"))
+			return 1
+		}
+	}
+
+	if err := openHistoryStore().Clear(); err != nil {
+		fmt.Printf("❌ %s\n", err.Error())
+		return 1
+	}
+	fmt.Println("✅ " + i18n.T("已清空历史记录"))
+	return 0
+}
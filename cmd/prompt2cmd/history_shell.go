@@ -0,0 +1,245 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/elecmonkey/prompt2cmd/internal/history"
+	"github.com/elecmonkey/prompt2cmd/internal/i18n"
+	"github.com/elecmonkey/prompt2cmd/internal/redact"
+)
+
+// maxImportedCommandLength 导入时忽略超过该长度的命令（通常是粘贴的脚本）
+const maxImportedCommandLength = 1000
+
+// shellReloadHints 追加到历史文件后，在已打开的 shell 中加载新记录的命令
+var shellReloadHints = map[string]string{
+	history.ShellBash: "history -r",
+	history.ShellZsh:  "fc -R",
+	history.ShellFish: "history merge",
+}
+
+// shellFormatFlag 解析 --format，未指定时根据 $SHELL 判断，无法判断时使用 bash
+func shellFormatFlag(format string) (string, error) {
+	if format == "" {
+		format = history.DetectShellFormat(os.Getenv("SHELL"))
+		if format == "" {
+			format = history.ShellBash
+		}
+	}
+	if !history.ValidShellFormat(format) {
+		return "", errors.New(i18n.T("不支持的格式 %s，可选: %s", format, strings.Join(history.ShellFormats, ", ")))
+	}
+	return format, nil
+}
+
+// runHistoryExport 将执行过的命令导出为 shell 历史格式，便于在普通终端中用 Ctrl-R 找到
+func runHistoryExport(args []string) int {
+	flags := flag.NewFlagSet("history export", flag.ContinueOnError)
+	format := flags.String("format", "", i18n.T("历史格式：bash、zsh 或 fish，默认根据 $SHELL 判断"))
+	output := flags.String("output", "", i18n.T("追加到该文件，默认输出到标准输出"))
+	histfile := flags.Bool("histfile", false, i18n.T("追加到 shell 默认的历史文件"))
+	since := flags.String("since", "", i18n.T("只导出不早于该日期的记录"))
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	shellFormat, err := shellFormatFlag(*format)
+	if err != nil {
+		fmt.Printf("❌ %s\n", err.Error())
+		return 2
+	}
+	if *histfile && *output != "" {
+		fmt.Println("❌ " + i18n.T("--output 和 --histfile 不能同时使用"))
+		return 2
+	}
+
+	options := history.SearchOptions{}
+	if *since != "" {
+		if options.Since, err = parseDate(*since, false); err != nil {
+			fmt.Printf("❌ %s\n", err.Error())
+			return 2
+		}
+	}
+	result, err := openHistoryStore().Search(options)
+	if err != nil {
+		fmt.Printf("❌ %s\n", err.Error())
+		return 1
+	}
+
+	// 只导出由 prompt2cmd 执行过的命令，按时间由旧到新排列
+	var records []history.HistoryRecord
+	for i := len(result.Records) - 1; i >= 0; i-- {
+		record := result.Records[i]
+		if record.Executed && record.Source == "" {
+			records = append(records, record)
+		}
+	}
+
+	path := *output
+	if *histfile {
+		if path, err = history.DefaultShellHistoryFile(shellFormat); err != nil {
+			fmt.Printf("❌ %s\n", err.Error())
+			return 1
+		}
+	}
+	if path == "" {
+		if err := history.WriteShellHistory(os.Stdout, records, shellFormat); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %s\n", i18n.T("导出失败: %s", err.Error()))
+			return 1
+		}
+		return 0
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		fmt.Printf("❌ %s\n", i18n.T("创建目录失败: %s", err.Error()))
+		return 1
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		fmt.Printf("❌ %s\n", i18n.T("打开历史文件失败: %s", err.Error()))
+		return 1
+	}
+	defer file.Close()
+	if err := history.WriteShellHistory(file, records, shellFormat); err != nil {
+		fmt.Printf("❌ %s\n", i18n.T("导出失败: %s", err.Error()))
+		return 1
+	}
+	fmt.Printf("✅ %s\n", i18n.T("已将 %d 条命令追加到 %s", len(records), path))
+	fmt.Printf("💡 %s\n", i18n.T("在已打开的 %s 中执行 %s 即可加载", shellFormat, shellReloadHints[shellFormat]))
+	return 0
+}
+
+// importSkipCounts 导入时跳过的命令数
+type importSkipCounts struct {
+	sensitive int // 疑似包含敏感信息
+	trivial   int // 过于简单或过长，不适合作为示例
+	duplicate int // 重复的命令
+}
+
+// runHistoryImport 从 shell 历史中导入命令，作为生成命令时的参考示例
+func runHistoryImport(args []string) int {
+	flags := flag.NewFlagSet("history import", flag.ContinueOnError)
+	format := flags.String("format", "", i18n.T("历史格式：bash、zsh 或 fish，默认根据 $SHELL 判断"))
+	file := flags.String("file", "", i18n.T("历史文件，默认为 shell 的默认历史文件"))
+	limit := flags.Int("limit", 200, i18n.T("最多导入最近的多少条不重复的命令"))
+	dryRun := flags.Bool("dry-run", false, i18n.T("只显示将要导入的命令"))
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	shellFormat, err := shellFormatFlag(*format)
+	if err != nil {
+		fmt.Printf("❌ %s\n", err.Error())
+		return 2
+	}
+	path := *file
+	if path == "" {
+		if path, err = history.DefaultShellHistoryFile(shellFormat); err != nil {
+			fmt.Printf("❌ %s\n", err.Error())
+			return 1
+		}
+	}
+
+	entries, modTime, err := readShellHistoryFile(path, shellFormat)
+	if err != nil {
+		fmt.Printf("❌ %s\n", i18n.T("读取 %s 失败: %s", path, err.Error()))
+		return 1
+	}
+
+	records, skipped := selectShellCommands(entries, modTime, shellFormat, *limit)
+	if *dryRun {
+		for _, record := range records {
+			fmt.Println(record.Command)
+		}
+		fmt.Printf("\n%s\n", i18n.T("将导入 %d 条命令；跳过疑似包含敏感信息的 %d 条、不适合作为示例的 %d 条、重复的 %d 条",
+			len(records), skipped.sensitive, skipped.trivial, skipped.duplicate))
+		return 0
+	}
+
+	store := openHistoryStore()
+	if _, ok := store.(*history.FileCommandHistory); ok {
+		fmt.Println("⚠️ " + i18n.T("当前使用JSON存储，只保留最近 MAX_HISTORY_SIZE 条记录，较早的命令可能不会保留"))
+	}
+	added, err := store.Import(records)
+	if err != nil {
+		fmt.Printf("❌ %s\n", err.Error())
+		return 1
+	}
+	fmt.Printf("✅ %s\n", i18n.T("已从 %s 导入 %d 条命令（%d 条已存在）", path, added, len(records)-added))
+	fmt.Printf("   %s\n", i18n.T("跳过疑似包含敏感信息的 %d 条、不适合作为示例的 %d 条、重复的 %d 条",
+		skipped.sensitive, skipped.trivial, skipped.duplicate))
+	return 0
+}
+
+// readShellHistoryFile 读取 shell 历史文件，同时返回文件的修改时间
+func readShellHistoryFile(path, format string) ([]history.ShellHistoryEntry, time.Time, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	entries, err := history.ReadShellHistory(file, format)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	return entries, info.ModTime(), nil
+}
+
+// selectShellCommands 从最近的命令开始选取不重复、不含敏感信息的命令，返回的记录按时间由旧到新排列
+// 历史文件中没有时间的命令以文件修改时间为最后一条，向前每条递减一秒，以保持原有顺序
+func selectShellCommands(entries []history.ShellHistoryEntry, modTime time.Time, format string, limit int) ([]history.HistoryRecord, importSkipCounts) {
+	var skipped importSkipCounts
+	seen := make(map[string]bool)
+	var records []history.HistoryRecord
+	for i := len(entries) - 1; i >= 0 && (limit <= 0 || len(records) < limit); i-- {
+		entry := entries[i]
+		entry.Command = strings.TrimSpace(entry.Command)
+		if entry.Time.IsZero() {
+			entry.Time = modTime.Add(-time.Duration(len(entries)-1-i) * time.Second)
+		}
+
+		switch {
+		case seen[entry.Command]:
+			skipped.duplicate++
+			continue
+		case !usefulShellCommand(entry.Command):
+			skipped.trivial++
+			continue
+		case redact.LooksSensitive(entry.Command):
+			skipped.sensitive++
+			continue
+		}
+		seen[entry.Command] = true
+		records = append(records, history.NewShellRecord(format, entry))
+	}
+
+	for i, j := 0, len(records)-1; i < j; i, j = i+1, j-1 {
+		records[i], records[j] = records[j], records[i]
+	}
+	return records, skipped
+}
+
+// usefulShellCommand 判断命令是否适合作为生成命令的示例
+// 不带参数的命令、切换目录和 prompt2cmd 自身的调用没有参考价值，过长的命令通常是粘贴的脚本
+func usefulShellCommand(command string) bool {
+	fields := strings.Fields(command)
+	if len(fields) < 2 || len(command) > maxImportedCommandLength {
+		return false
+	}
+	switch filepath.Base(fields[0]) {
+	case "cd", "prompt2cmd", "history", "fc":
+		return false
+	}
+	return true
+}
@@ -0,0 +1,501 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/elecmonkey/prompt2cmd/internal/config"
+	"github.com/elecmonkey/prompt2cmd/internal/i18n"
+	"github.com/elecmonkey/prompt2cmd/internal/secret"
+)
+
+// initUsage init 子命令的用法
+const initUsage = `用法:
+  prompt2cmd init [--offline] [--force] [--file <路径>]
+
+选项:
+  --offline   不连接模型，使用本地模拟服务测试配置
+  --force     配置文件已存在时直接覆盖
+  --file      配置文件路径，默认为 ~/.config/prompt2cmd/config.yaml`
+
+// 保存API密钥的方式
+const (
+	keyStorageKeyring = iota + 1
+	keyStorageCommand
+	keyStorageFile
+)
+
+// securityPreset 初始化向导提供的安全设置
+type securityPreset struct {
+	Name        string
+	Description string
+	Entries     []configEntry
+}
+
+// securityPresets 可选的安全设置，第一个为默认值
+var securityPresets = []securityPreset{
+	{
+		Name:        "标准",
+		Description: "所有命令执行前都需要确认，禁止修改受保护的系统路径",
+		Entries: []configEntry{
+			{Path: "security.auto_confirm_readonly", Value: "false"},
+			{Path: "security.protected_path_action", Value: "deny"},
+			{Path: "llm.risk_review", Value: "off"},
+		},
+	},
+	{
+		Name:        "严格",
+		Description: "在标准设置的基础上，静态规则无法确定风险时让模型复核",
+		Entries: []configEntry{
+			{Path: "security.auto_confirm_readonly", Value: "false"},
+			{Path: "security.protected_path_action", Value: "deny"},
+			{Path: "llm.risk_review", Value: "auto"},
+		},
+	},
+	{
+		Name:        "宽松",
+		Description: "只读命令自动执行，修改受保护路径时输入路径确认即可",
+		Entries: []configEntry{
+			{Path: "security.auto_confirm_readonly", Value: "true"},
+			{Path: "security.protected_path_action", Value: "confirm"},
+			{Path: "llm.risk_review", Value: "off"},
+		},
+	},
+}
+
+// configEntry 写入配置文件的一个配置项，Value 为 YAML 格式的值
+type configEntry struct {
+	Path  string
+	Value string
+}
+
+// initWizard 初始化向导的状态
+type initWizard struct {
+	reader  *bufio.Reader
+	offline bool
+	entries []configEntry
+}
+
+// runInitCommand 执行 init 子命令，逐步询问设置并生成配置文件
+func runInitCommand(args []string) int {
+	flags := flag.NewFlagSet("init", flag.ContinueOnError)
+	offline := flags.Bool("offline", false, i18n.T("不连接模型，使用本地模拟服务测试配置"))
+	force := flags.Bool("force", false, i18n.T("配置文件已存在时直接覆盖"))
+	file := flags.String("file", "", i18n.T("配置文件路径"))
+	flags.Usage = func() { fmt.Println(i18n.Translate(initUsage)) }
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	path := *file
+	if path == "" {
+		path = config.DefaultConfigFile()
+	}
+
+	wizard := &initWizard{reader: bufio.NewReader(os.Stdin), offline: *offline}
+	fmt.Printf("🧭 %s\n", i18n.T("Prompt2Cmd 初始设置，配置将写入 %s", path))
+	if _, err := os.Stat(path); err == nil && !*force {
+		overwrite, err := wizard.confirm(i18n.T("%s 已存在，是否覆盖?", path), false)
+		if err != nil {
+			fmt.Printf("❌ %s\n", err.Error())
+			return 1
+		}
+		if !overwrite {
+			fmt.Println(i18n.T("已取消，配置文件没有修改"))
+			return 0
+		}
+	}
+
+	if err := wizard.run(path); err != nil {
+		fmt.Printf("❌ %s\n", err.Error())
+		return 1
+	}
+	return 0
+}
+
+// run 依次完成各个步骤并写入配置文件
+func (w *initWizard) run(path string) error {
+	// 第一步：选择LLM提供商
+	fmt.Println("\n" + i18n.T("1. 选择LLM提供商:"))
+	for i, preset := range config.ProviderPresets {
+		note := ""
+		if preset.Local {
+			note = i18n.T("（本地模型，不需要API密钥）")
+		}
+		fmt.Printf("  %d. %-9s %-28s %s%s\n", i+1, preset.Name, preset.BaseURL, preset.Model, note)
+	}
+	var preset config.ProviderPreset
+	for {
+		answer, err := w.ask(i18n.T("请输入编号或名称"), "1")
+		if err != nil {
+			return err
+		}
+		if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(config.ProviderPresets) {
+			preset = config.ProviderPresets[n-1]
+			break
+		}
+		if found, ok := config.FindProviderPreset(answer); ok {
+			preset = found
+			break
+		}
+		fmt.Println(i18n.T("不支持的LLM提供商: %s", answer))
+	}
+
+	baseURL, err := w.ask(i18n.T("API基础URL"), preset.BaseURL)
+	if err != nil {
+		return err
+	}
+	model, err := w.ask(i18n.T("模型名称"), preset.Model)
+	if err != nil {
+		return err
+	}
+	w.add("llm.provider", yamlString(preset.Name))
+	if baseURL != preset.BaseURL {
+		w.add("llm.base_url", yamlString(baseURL))
+	}
+	w.add("llm.model", yamlString(model))
+
+	// 第二步：API密钥
+	key, storage := "", 0
+	if !preset.Local {
+		key, storage, err = w.askAPIKey(preset.Name)
+		if err != nil {
+			return err
+		}
+	}
+
+	// 第三步：测试调用
+	if err := w.testProvider(&config.Config{
+		LLMProvider: preset.Name,
+		LLMAPIKey:   key,
+		LLMBaseURL:  baseURL,
+		LLMModel:    model,
+	}); err != nil {
+		fmt.Printf("❌ %s\n", i18n.T("测试失败: %s", err.Error()))
+		save, err := w.confirm(i18n.T("仍然保存配置?"), false)
+		if err != nil {
+			return err
+		}
+		if !save {
+			return errors.New(i18n.T("已取消，配置文件没有修改"))
+		}
+	}
+
+	// 第四步：选择shell
+	shell, err := w.askShell()
+	if err != nil {
+		return err
+	}
+	w.add("shell", yamlString(shell))
+
+	// 第五步：选择安全设置
+	fmt.Println("\n" + i18n.T("5. 选择安全设置:"))
+	for i, security := range securityPresets {
+		fmt.Printf("  %d. %s\n", i+1, i18n.T("%s：%s", i18n.Translate(security.Name), i18n.Translate(security.Description)))
+	}
+	for {
+		answer, err := w.ask(i18n.T("请输入编号"), "1")
+		if err != nil {
+			return err
+		}
+		if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(securityPresets) {
+			w.entries = append(w.entries, securityPresets[n-1].Entries...)
+			break
+		}
+		fmt.Println(i18n.T("无效的编号: %s", answer))
+	}
+
+	// 保存API密钥并写入配置文件
+	if storage == keyStorageKeyring {
+		keyring := secret.DefaultKeyring()
+		if err := keyring.Set(preset.Name, key); err != nil {
+			fmt.Printf("⚠️ %s\n", i18n.T("保存到%s失败: %s", keyring.Name(), err.Error()))
+			plaintext, err := w.confirm(i18n.T("改为明文写入配置文件?"), false)
+			if err != nil {
+				return err
+			}
+			if plaintext {
+				w.add("llm.api_key", yamlString(key))
+			} else {
+				fmt.Println("   " + i18n.T("可以稍后运行 prompt2cmd config set-key 保存，或在配置文件中设置 llm.api_key_command"))
+			}
+		} else {
+			fmt.Printf("✅ %s\n", i18n.T("已将 %s 的API密钥保存到%s", preset.Name, keyring.Name()))
+		}
+	}
+	if err := writeConfigFile(path, w.entries); err != nil {
+		return err
+	}
+	fmt.Printf("✅ %s\n", i18n.T("配置已写入 %s", path))
+
+	// 重新加载配置，确认生成的文件有效
+	if path == config.DefaultConfigFile() {
+		if _, err := loadConfig(); err != nil {
+			fmt.Printf("⚠️ %s\n", i18n.T("加载配置失败: %s", err.Error()))
+			return nil
+		}
+	}
+	fmt.Println(i18n.T("运行 prompt2cmd 开始使用，prompt2cmd config show --origin 可以查看生效的配置"))
+	return nil
+}
+
+// add 添加一个配置项，已存在时覆盖
+func (w *initWizard) add(path, value string) {
+	for i, entry := range w.entries {
+		if entry.Path == path {
+			w.entries[i].Value = value
+			return
+		}
+	}
+	w.entries = append(w.entries, configEntry{Path: path, Value: value})
+}
+
+// ask 显示问题并读取回答，直接回车时使用默认值
+func (w *initWizard) ask(question, defaultValue string) (string, error) {
+	if defaultValue != "" {
+		fmt.Printf("❓ %s [%s]: ", question, defaultValue)
+	} else {
+		fmt.Printf("❓ %s: ", question)
+	}
+	input, err := w.reader.ReadString('\n')
+	if err != nil && input == "" {
+		return "", errors.New(i18n.T("读取输入失败: %s", err.Error()))
+	}
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return defaultValue, nil
+	}
+	return input, nil
+}
+
+// confirm 询问是否继续
+func (w *initWizard) confirm(question string, defaultYes bool) (bool, error) {
+	defaultValue := "n"
+	if defaultYes {
+		defaultValue = "y"
+	}
+	answer, err := w.ask(question+" (y/n)", defaultValue)
+	if err != nil {
+		return false, err
+	}
+	answer = strings.ToLower(answer)
+	return answer == "y" || answer == "yes" || answer == "是", nil
+}
+
+// askAPIKey 询问API密钥的保存方式并获取密钥，返回密钥和保存方式
+func (w *initWizard) askAPIKey(provider string) (string, int, error) {
+	keyring := secret.DefaultKeyring()
+	fmt.Println("\n" + i18n.T("2. 选择API密钥的保存方式:"))
+	fmt.Printf("  %s\n", i18n.T("1. 系统密钥环（%s，推荐）", keyring.Name()))
+	fmt.Printf("  %s\n", i18n.T("2. 凭据命令，例如 pass show %s", provider))
+	fmt.Println("  " + i18n.T("3. 明文写入配置文件"))
+	for {
+		answer, err := w.ask(i18n.T("请输入编号"), "1")
+		if err != nil {
+			return "", 0, err
+		}
+		switch answer {
+		case "1", "3":
+			key, err := readAPIKey(provider, w.reader)
+			if err != nil {
+				return "", 0, err
+			}
+			if answer == "3" {
+				w.add("llm.api_key", yamlString(key))
+				return key, keyStorageFile, nil
+			}
+			return key, keyStorageKeyring, nil
+		case "2":
+			command, err := w.ask(i18n.T("凭据命令"), "pass show "+provider)
+			if err != nil {
+				return "", 0, err
+			}
+			key, err := secret.RunHelper(command)
+			if err != nil {
+				return "", 0, errors.New(i18n.T("通过凭据命令获取API密钥失败: %s", err.Error()))
+			}
+			w.add("llm.api_key_command", yamlString(command))
+			return key, keyStorageCommand, nil
+		default:
+			fmt.Println(i18n.T("无效的编号: %s", answer))
+		}
+	}
+}
+
+// testProvider 让模型生成一条简单的命令，确认地址、模型和API密钥可用
+// 离线模式下请求发往本地的模拟服务，只检查请求能否正确发出和解析
+func (w *initWizard) testProvider(cfg *config.Config) error {
+	fmt.Println("\n" + i18n.T("3. 测试模型调用"))
+	if w.offline {
+		server, err := newMockLLMServer(cfg.LLMAPIKey != "")
+		if err != nil {
+			return errors.New(i18n.T("启动本地模拟服务失败: %s", err.Error()))
+		}
+		defer server.Close()
+		cfg.LLMBaseURL = server.URL
+		fmt.Printf("🔌 %s\n", i18n.T("离线模式，使用本地模拟服务 %s", server.URL))
+	} else {
+		fmt.Printf("🔌 %s\n", i18n.T("正在连接 %s（模型 %s）...", cfg.LLMBaseURL, cfg.LLMModel))
+	}
+
+	provider, err := newLLMProvider(cfg)
+	if err != nil {
+		return err
+	}
+	command, explanation, err := provider.GenerateCommand(i18n.T("显示当前所在的目录"), nil)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("✅ %s\n", i18n.T("测试成功，模型生成的命令: %s（%s）", command, explanation))
+	return nil
+}
+
+// mockLLMServer 离线模式使用的本地模拟服务
+type mockLLMServer struct {
+	URL    string
+	server *http.Server
+}
+
+// newMockLLMServer 在回环地址的随机端口上启动模拟聊天补全接口的服务，requireKey 为 true 时要求请求带有API密钥
+func newMockLLMServer(requireKey bool) (*mockLLMServer, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	server := &http.Server{
+		Handler:           mockLLMHandler(requireKey),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	go server.Serve(listener)
+	return &mockLLMServer{URL: "http://" + listener.Addr().String(), server: server}, nil
+}
+
+// Close 停止模拟服务
+func (m *mockLLMServer) Close() {
+	m.server.Close()
+}
+
+// mockLLMHandler 返回固定命令的聊天补全接口
+func mockLLMHandler(requireKey bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method != http.MethodPost || !strings.HasSuffix(r.URL.Path, "/chat/completions") {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error":{"message":"not found"}}`)
+			return
+		}
+		if requireKey && strings.TrimSpace(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer")) == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"error":{"message":"missing api key"}}`)
+			return
+		}
+		content := fmt.Sprintf(`{"command":"pwd","explanation":%q}`, i18n.T("离线模式的模拟响应"))
+		fmt.Fprintf(w, `{"choices":[{"message":{"role":"assistant","content":%q}}]}`, content)
+	})
+}
+
+// askShell 询问执行命令使用的shell，默认为 $SHELL
+func (w *initWizard) askShell() (string, error) {
+	var shells []string
+	seen := make(map[string]bool)
+	for _, name := range []string{os.Getenv("SHELL"), "sh", "bash", "zsh", "fish"} {
+		if name == "" {
+			continue
+		}
+		path, err := exec.LookPath(name)
+		if err != nil {
+			continue
+		}
+		// /bin 可能是 /usr/bin 的符号链接，同一个shell只显示一次
+		target, err := filepath.EvalSymlinks(path)
+		if err != nil {
+			target = path
+		}
+		if seen[target] {
+			continue
+		}
+		seen[target] = true
+		shells = append(shells, path)
+	}
+
+	fmt.Println("\n" + i18n.T("4. 选择执行命令使用的shell:"))
+	for i, shell := range shells {
+		fmt.Printf("  %d. %s\n", i+1, shell)
+	}
+	defaultValue := config.DefaultShell
+	if len(shells) > 0 {
+		defaultValue = "1"
+	}
+	for {
+		answer, err := w.ask(i18n.T("请输入编号或shell路径"), defaultValue)
+		if err != nil {
+			return "", err
+		}
+		if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(shells) {
+			return shells[n-1], nil
+		}
+		if path, err := exec.LookPath(answer); err == nil {
+			return path, nil
+		}
+		fmt.Println(i18n.T("找不到shell: %s", answer))
+	}
+}
+
+// yamlString 将字符串转换为 YAML 标量，需要时加引号
+func yamlString(value string) string {
+	data, err := yaml.Marshal(value)
+	if err != nil {
+		return strconv.Quote(value)
+	}
+	return strings.TrimSuffix(string(data), "\n")
+}
+
+// renderConfigFile 生成 YAML 配置文件的内容，点号分隔的路径按第一段分组
+func renderConfigFile(entries []configEntry) string {
+	var builder strings.Builder
+	builder.WriteString(i18n.T("# prompt2cmd 配置文件，由 prompt2cmd init 生成") + "\n")
+	builder.WriteString(i18n.T("# prompt2cmd config show --origin 可以查看生效的配置及每一项的来源") + "\n")
+
+	var sections []string
+	grouped := make(map[string][]configEntry)
+	for _, entry := range entries {
+		section, key, nested := strings.Cut(entry.Path, ".")
+		if !nested {
+			fmt.Fprintf(&builder, "%s: %s\n", entry.Path, entry.Value)
+			continue
+		}
+		if _, ok := grouped[section]; !ok {
+			sections = append(sections, section)
+		}
+		grouped[section] = append(grouped[section], configEntry{Path: key, Value: entry.Value})
+	}
+	for _, section := range sections {
+		fmt.Fprintf(&builder, "\n%s:\n", section)
+		for _, entry := range grouped[section] {
+			fmt.Fprintf(&builder, "  %s: %s\n", entry.Path, entry.Value)
+		}
+	}
+	return builder.String()
+}
+
+// writeConfigFile 写入配置文件，文件中可能有API密钥，只允许当前用户读写
+func writeConfigFile(path string, entries []configEntry) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return errors.New(i18n.T("创建配置目录失败: %s", err.Error()))
+	}
+	if err := os.WriteFile(path, []byte(renderConfigFile(entries)), 0600); err != nil {
+		return errors.New(i18n.T("写入配置文件失败: %s", err.Error()))
+	}
+	return nil
+}
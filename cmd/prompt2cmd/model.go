@@ -0,0 +1,145 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+
+	"github.com/elecmonkey/prompt2cmd/internal/config"
+	"github.com/elecmonkey/prompt2cmd/internal/i18n"
+)
+
+// parseModelPrefix 解析 @模型 前缀，例如 @deepseek-reasoner 改写这个awk管道
+// 返回模型名称和去掉前缀后的需求
+func parseModelPrefix(prompt string) (string, string, bool) {
+	if !strings.HasPrefix(prompt, "@") {
+		return "", "", false
+	}
+	model, rest, _ := strings.Cut(strings.TrimPrefix(prompt, "@"), " ")
+	rest = strings.TrimSpace(rest)
+	if model == "" || rest == "" {
+		return "", "", false
+	}
+	return model, rest, true
+}
+
+// withModel 只在执行 run 期间使用指定的模型，生成、风险复核和历史记录都使用该模型
+// 配置了 llm.audit_model 时结果审计仍使用审计模型
+func (s *session) withModel(model string, run func()) {
+	cfg := *s.cfg
+	cfg.LLMModel = model
+	provider, err := newSessionProvider(&cfg)
+	if err != nil {
+		s.userInterface.DisplayError(err)
+		return
+	}
+
+	oldCfg, oldProvider := s.cfg, s.llmProvider
+	s.cfg, s.llmProvider = &cfg, provider
+	defer func() {
+		s.cfg, s.llmProvider = oldCfg, oldProvider
+	}()
+
+	fmt.Printf("🧠 %s\n", i18n.T("本次使用模型 %s", model))
+	run()
+}
+
+// switchModel 处理 /model [名称]，切换本次会话使用的模型，不带参数时显示当前模型
+func (s *session) switchModel(args []string) {
+	if len(args) == 0 {
+		fmt.Printf("🧠 %s\n", i18n.T("当前模型: %s/%s", s.cfg.LLMProvider, s.cfg.LLMModel))
+		fmt.Println(i18n.T("使用 /model <名称> 切换模型，/provider <名称> 切换提供商，或在需求前加上 @模型 只对这一条需求使用其他模型"))
+		return
+	}
+	if len(args) != 1 {
+		s.userInterface.DisplayError(errors.New(i18n.T("用法: /model <名称>")))
+		return
+	}
+
+	overrides := maps.Clone(s.overrides)
+	if overrides == nil {
+		overrides = make(map[string]string)
+	}
+	overrides["LLM_MODEL"] = args[0]
+	if s.applyOverrides(overrides) {
+		fmt.Printf("✅ %s\n", i18n.T("已切换到模型 %s/%s", s.cfg.LLMProvider, s.cfg.LLMModel))
+	}
+}
+
+// switchProvider 处理 /provider [名称] [模型]，切换本次会话使用的提供商
+// 地址和模型使用该提供商的默认值，API密钥从系统密钥环读取；切换回配置文件中的提供商时恢复原来的配置
+func (s *session) switchProvider(args []string) {
+	if len(args) == 0 {
+		names := make([]string, len(config.ProviderPresets))
+		for i, preset := range config.ProviderPresets {
+			names[i] = preset.Name
+		}
+		fmt.Printf("🧠 %s\n", i18n.T("当前提供商: %s（模型 %s）", s.cfg.LLMProvider, s.cfg.LLMModel))
+		fmt.Println(i18n.T("可用的提供商: %s", strings.Join(names, ", ")))
+		return
+	}
+	if len(args) > 2 {
+		s.userInterface.DisplayError(errors.New(i18n.T("用法: /provider <名称> [模型]")))
+		return
+	}
+	preset, ok := config.FindProviderPreset(args[0])
+	if !ok {
+		s.userInterface.DisplayError(errors.New(i18n.T("不支持的LLM提供商: %s", args[0])))
+		return
+	}
+
+	var overrides map[string]string
+	if configured, err := s.configuredProvider(); err == nil && configured == preset.Name {
+		// 切换回配置文件中的提供商，使用配置文件中的地址、模型和API密钥
+		overrides = map[string]string{}
+	} else {
+		// 其他提供商的地址、模型和API密钥都不能沿用当前的配置
+		overrides = map[string]string{
+			"LLM_PROVIDER":        preset.Name,
+			"LLM_BASE_URL":        preset.BaseURL,
+			"LLM_MODEL":           preset.Model,
+			"LLM_API_KEY":         "",
+			"LLM_API_KEY_COMMAND": "",
+		}
+	}
+	if len(args) == 2 {
+		overrides["LLM_MODEL"] = args[1]
+	}
+
+	if s.applyOverrides(overrides) {
+		fmt.Printf("✅ %s\n", i18n.T("已切换到 %s/%s", s.cfg.LLMProvider, s.cfg.LLMModel))
+	}
+}
+
+// configuredProvider 返回不考虑会话中切换时，配置中的提供商
+func (s *session) configuredProvider() (string, error) {
+	configManager, err := newConfigManager()
+	if err != nil {
+		return "", err
+	}
+	resolved, err := configManager.Resolve()
+	if err != nil {
+		return "", err
+	}
+	if provider := resolved.Values["LLM_PROVIDER"]; provider != "" {
+		return provider, nil
+	}
+	return "deepseek", nil
+}
+
+// applyOverrides 使用新的会话配置项重建组件，失败时恢复原来的设置
+func (s *session) applyOverrides(overrides map[string]string) bool {
+	previous := s.overrides
+	s.overrides = overrides
+	cfg, err := s.loadConfig()
+	if err == nil {
+		err = s.configure(cfg)
+	}
+	if err != nil {
+		s.overrides = previous
+		s.userInterface.DisplayError(errors.New(i18n.T("切换失败，继续使用 %s/%s: %s", s.cfg.LLMProvider, s.cfg.LLMModel, err.Error())))
+		return false
+	}
+	return true
+}
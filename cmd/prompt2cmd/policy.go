@@ -0,0 +1,113 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/elecmonkey/prompt2cmd/internal/config"
+	"github.com/elecmonkey/prompt2cmd/internal/i18n"
+	"github.com/elecmonkey/prompt2cmd/internal/security"
+)
+
+// runPolicyCommand 处理 policy 子命令
+func runPolicyCommand(args []string) int {
+	if len(args) == 0 || args[0] != "test" {
+		fmt.Println(i18n.T(`用法: prompt2cmd policy test [--file 策略文件] "<命令>"`))
+		return 2
+	}
+
+	flags := flag.NewFlagSet("policy test", flag.ContinueOnError)
+	policyFile := flags.String("file", "", i18n.T("要测试的安全策略文件（默认使用配置中的策略）"))
+	if err := flags.Parse(args[1:]); err != nil {
+		return 2
+	}
+	command := strings.Join(flags.Args(), " ")
+	if strings.TrimSpace(command) == "" {
+		fmt.Println("❌ " + i18n.T("请提供要测试的命令"))
+		return 2
+	}
+
+	checker, err := newPolicyTestChecker(*policyFile)
+	if err != nil {
+		fmt.Printf("❌ %s\n", err.Error())
+		return 1
+	}
+
+	assessment := checker.AssessCommand(command)
+
+	fmt.Println(i18n.T("命令: %s", command))
+	if checker.Policy != nil {
+		fmt.Println(i18n.T("策略文件: %s", checker.Policy.File))
+	} else {
+		fmt.Println(i18n.T("策略文件: 未配置（仅使用内置规则）"))
+	}
+
+	if len(assessment.PolicyMatches) == 0 {
+		fmt.Println(i18n.T("命中规则: 无"))
+	} else {
+		fmt.Println(i18n.T("命中规则:"))
+		for _, match := range assessment.PolicyMatches {
+			fmt.Printf("  - %s [%s] %s", match.Rule, match.Action, match.Command)
+			if match.Message != "" {
+				fmt.Printf(": %s", match.Message)
+			}
+			fmt.Println()
+		}
+	}
+
+	fmt.Println(i18n.T("风险等级: %s (%s)", assessment.Level.Label(), assessment.Level))
+	if assessment.Paths != nil {
+		fmt.Println(assessment.Paths.Summary(20))
+	}
+	if len(assessment.Reasons) > 0 {
+		fmt.Println(i18n.T("判定理由:"))
+		for _, reason := range assessment.Reasons {
+			fmt.Printf("  - %s\n", reason)
+		}
+	}
+
+	switch {
+	case assessment.Blocked:
+		fmt.Println(i18n.T("结果: ⛔ 禁止执行"))
+	case assessment.Level == security.RiskCritical:
+		fmt.Println(i18n.T("结果: ❗ 需要完整输入 %s 确认", assessment.ConfirmPhrase))
+	case assessment.ReadOnly:
+		fmt.Println(i18n.T("结果: ✅ 只读命令"))
+	default:
+		fmt.Println(i18n.T("结果: ❓ 需要确认后执行"))
+	}
+	return 0
+}
+
+// newPolicyTestChecker 创建用于策略测试的安全检查器
+// 配置不完整（例如缺少API密钥）时仍然可以测试策略
+func newPolicyTestChecker(policyFile string) (*security.DefaultSecurityChecker, error) {
+	var checker *security.DefaultSecurityChecker
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Printf("⚠️ %s\n", i18n.T("加载配置失败，使用默认危险命令列表: %s", err.Error()))
+		checker = security.NewSecurityChecker(config.DefaultDangerousCommands)
+		checker.PathGuard = security.NewPathGuard(config.DefaultProtectedPaths, true, true)
+		if policyFile == "" {
+			policyFile, err = config.FindSecurityPolicyFile(os.Getenv("SECURITY_POLICY_FILE"))
+			if err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		checker = security.NewSecurityChecker(cfg.DangerousCommands)
+		checker.Policy = cfg.SecurityPolicy
+		checker.PathGuard = security.NewPathGuard(cfg.ProtectedPaths, cfg.BlockProtectedPaths, cfg.ProtectMountPoints)
+	}
+
+	if policyFile != "" {
+		policy, err := config.LoadSecurityPolicy(policyFile)
+		if err != nil {
+			return nil, err
+		}
+		checker.Policy = policy
+	}
+	return checker, nil
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/elecmonkey/prompt2cmd/internal/i18n"
+	"github.com/elecmonkey/prompt2cmd/internal/llm/prompts"
+)
+
+// handlePromptDirective 处理 /prompt show [名称]，显示渲染后的系统提示词，用于调试自定义模板
+func (s *session) handlePromptDirective(args []string) {
+	usage := i18n.T("用法: /prompt show [%s]", strings.Join(prompts.Names, "|"))
+	if len(args) == 0 || args[0] != "show" || len(args) > 2 {
+		s.userInterface.DisplayError(errors.New(usage))
+		return
+	}
+	name := prompts.System
+	if len(args) == 2 {
+		name = args[1]
+	}
+	if !slices.Contains(prompts.Names, name) {
+		s.userInterface.DisplayError(errors.New(i18n.T("未知的提示词模板: %s（%s）", name, usage)))
+		return
+	}
+
+	tmpl, err := prompts.Load(name)
+	if err != nil {
+		s.userInterface.DisplayError(err)
+		return
+	}
+	cwd, _ := os.Getwd()
+	text, err := tmpl.Render(prompts.NewData(s.cfg.Shell, cwd))
+	if err != nil {
+		s.userInterface.DisplayError(err)
+		return
+	}
+
+	source := i18n.T("内置模板")
+	if tmpl.Source != "" {
+		source = tmpl.Source
+	}
+	fmt.Printf("\n📄 %s\n", i18n.T("提示词模板 %s（%s）:", name, source))
+	fmt.Println("--------------------------------------------------")
+	fmt.Println(text)
+	fmt.Println("--------------------------------------------------")
+	if tmpl.Source == "" {
+		fmt.Println(i18n.T("在 %s 中创建 %s.tmpl 可以覆盖内置模板", prompts.Dir(), name))
+	}
+}
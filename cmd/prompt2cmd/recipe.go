@@ -0,0 +1,250 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/elecmonkey/prompt2cmd/internal/config"
+	"github.com/elecmonkey/prompt2cmd/internal/i18n"
+	"github.com/elecmonkey/prompt2cmd/internal/security"
+	"github.com/elecmonkey/prompt2cmd/internal/snippet"
+)
+
+// recipeUsage recipe 子命令的用法
+const recipeUsage = `用法:
+  prompt2cmd recipe list            列出团队配方，并检查配方文件是否有效
+  prompt2cmd recipe show <名称>     显示配方详情
+  prompt2cmd recipe sync            从 RECIPES_REPO 克隆或更新配方目录`
+
+// loadRecipes 加载团队配方，无效的配方文件只显示警告
+func loadRecipes(dir string) *snippet.Catalog {
+	catalog, problems := snippet.LoadCatalog(dir)
+	for _, problem := range problems {
+		fmt.Printf("⚠️ %s\n", i18n.T("忽略无效的团队配方: %s", problem.Error()))
+	}
+	return catalog
+}
+
+// offerRecipe 在请求模型之前查找与需求匹配的团队配方，用户选择了配方时执行并返回 true
+// 没有匹配的配方或用户选择让模型生成时返回 false
+func (s *session) offerRecipe(prompt string) bool {
+	if !s.cfg.RecipeMatching || s.recipes == nil {
+		return false
+	}
+
+	var matches []snippet.Recipe
+	for _, recipe := range s.recipes.Match(prompt, snippet.DefaultMatchThreshold) {
+		// 缺少依赖程序的配方在本机无法使用
+		if len(recipe.MissingTools()) == 0 {
+			matches = append(matches, recipe)
+		}
+	}
+	if len(matches) == 0 {
+		return false
+	}
+
+	fmt.Println("\n📚 " + i18n.T("找到经过团队审核的配方:"))
+	for i, recipe := range matches {
+		fmt.Printf("  %d. %s — %s\n", i+1, recipe.Name, recipe.Description)
+		fmt.Printf("     %s\n", recipe.Command)
+	}
+	if len(matches) == 1 {
+		fmt.Print("❓ " + i18n.T("使用该配方? (y/n，n 表示让模型生成): "))
+	} else {
+		fmt.Printf("❓ %s", i18n.T("输入编号使用配方（1-%d），直接回车或 n 让模型生成: ", len(matches)))
+	}
+	input, err := s.reader.ReadString('\n')
+	if err != nil {
+		return false
+	}
+	input = strings.TrimSpace(strings.ToLower(input))
+
+	choice := 0
+	switch {
+	case len(matches) == 1 && (input == "y" || input == "yes" || input == "是" || input == "1"):
+		choice = 1
+	case len(matches) > 1:
+		if n, err := strconv.Atoi(input); err == nil && n >= 1 && n <= len(matches) {
+			choice = n
+		}
+	}
+	if choice == 0 {
+		return false
+	}
+
+	s.runRecipe(&matches[choice-1], map[string]string{})
+	return true
+}
+
+// runRecipe 填写参数后执行团队配方，配方声明的风险等级作为风险评估的下限
+func (s *session) runRecipe(recipe *snippet.Recipe, values map[string]string) {
+	if missing := recipe.MissingTools(); len(missing) > 0 {
+		s.userInterface.DisplayError(errors.New(i18n.T("配方 %s 需要的程序不存在: %s", recipe.Name, strings.Join(missing, ", "))))
+		return
+	}
+	command, ok := s.renderTemplate(recipe.Command, values)
+	if !ok {
+		return
+	}
+
+	var adjust func(assessment *security.RiskAssessment)
+	if level, ok := recipe.RiskLevel(); ok {
+		adjust = func(assessment *security.RiskAssessment) {
+			if level > assessment.Level {
+				assessment.Escalate(level, i18n.T("团队配方 %s 声明的风险等级为%s", recipe.Name, level.Label()))
+			}
+		}
+	}
+
+	s.userInterface.DisplayGeneratedCommand(command, i18n.T("团队配方 %s：%s", recipe.Name, recipe.Description))
+	s.runCommandWithRisk(recipe.Description, command, adjust)
+}
+
+// recipeSettings 返回配方目录和仓库；配置无法加载时（例如未设置API密钥）使用环境变量和默认值
+func recipeSettings() (string, string) {
+	cfg, err := loadConfig()
+	if err == nil {
+		return cfg.RecipesDir, cfg.RecipesRepo
+	}
+	dir := os.Getenv("RECIPES_DIR")
+	if dir == "" {
+		dir = config.DefaultRecipesDir()
+	}
+	return dir, os.Getenv("RECIPES_REPO")
+}
+
+// runRecipeCommand 执行 recipe 子命令
+func runRecipeCommand(args []string) int {
+	if len(args) == 0 {
+		fmt.Println(i18n.Translate(recipeUsage))
+		return 2
+	}
+
+	dir, repo := recipeSettings()
+	switch args[0] {
+	case "list":
+		return runRecipeList(dir)
+	case "show":
+		if len(args) != 2 {
+			fmt.Println(i18n.Translate(recipeUsage))
+			return 2
+		}
+		return runRecipeShow(dir, args[1])
+	case "sync":
+		flags := flag.NewFlagSet("recipe sync", flag.ContinueOnError)
+		if err := flags.Parse(args[1:]); err != nil {
+			return 2
+		}
+		if err := syncRecipes(dir, repo); err != nil {
+			fmt.Printf("❌ %s\n", err.Error())
+			return 1
+		}
+		return runRecipeList(dir)
+	default:
+		fmt.Printf("❌ %s\n", i18n.T("未知的 recipe 子命令: %s", args[0]))
+		fmt.Println(i18n.Translate(recipeUsage))
+		return 2
+	}
+}
+
+// runRecipeList 列出配方，存在无效的配方时返回 1，便于在配方仓库的CI中检查
+func runRecipeList(dir string) int {
+	catalog, problems := snippet.LoadCatalog(dir)
+	if len(catalog.Recipes) == 0 && len(problems) == 0 {
+		fmt.Println(i18n.T("%s 中没有团队配方", dir))
+		return 0
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, i18n.T("名称\t风险\t说明\t依赖"))
+	for _, recipe := range catalog.Recipes {
+		risk := "-"
+		if level, ok := recipe.RiskLevel(); ok {
+			risk = level.Label()
+		}
+		requires := "-"
+		if len(recipe.Requires) > 0 {
+			requires = strings.Join(recipe.Requires, ", ")
+			if missing := recipe.MissingTools(); len(missing) > 0 {
+				requires += i18n.T("（缺少 %s）", strings.Join(missing, ", "))
+			}
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n", recipe.Name, risk, truncateText(recipe.Description, 40), requires)
+	}
+	writer.Flush()
+
+	if len(problems) > 0 {
+		fmt.Println()
+		for _, problem := range problems {
+			fmt.Printf("❌ %s\n", problem.Error())
+		}
+		return 1
+	}
+	return 0
+}
+
+// runRecipeShow 显示一个配方
+func runRecipeShow(dir, name string) int {
+	catalog, _ := snippet.LoadCatalog(dir)
+	recipe, ok := catalog.Get(name)
+	if !ok {
+		fmt.Printf("❌ %s\n", i18n.T("配方不存在: %s", name))
+		return 1
+	}
+	fmt.Println(i18n.T("名称:     %s", recipe.Name))
+	fmt.Println(i18n.T("说明:     %s", recipe.Description))
+	fmt.Println(i18n.T("命令:     %s", recipe.Command))
+	if params := snippet.Params(recipe.Command); len(params) > 0 {
+		fmt.Println(i18n.T("参数:     %s", formatParams(params)))
+	}
+	if recipe.Risk != "" {
+		fmt.Println(i18n.T("风险等级: %s", recipe.Risk))
+	}
+	if len(recipe.Requires) > 0 {
+		fmt.Println(i18n.T("依赖:     %s", strings.Join(recipe.Requires, ", ")))
+	}
+	if len(recipe.Keywords) > 0 {
+		fmt.Println(i18n.T("关键词:   %s", strings.Join(recipe.Keywords, ", ")))
+	}
+	fmt.Println(i18n.T("文件:     %s", recipe.File))
+	return 0
+}
+
+// syncRecipes 将配方仓库克隆到配方目录，已克隆时以 fast-forward 方式更新
+func syncRecipes(dir, repo string) error {
+	if repo == "" {
+		return errors.New(i18n.T("未设置 RECIPES_REPO，无法同步团队配方"))
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		return errors.New(i18n.T("同步团队配方需要 git"))
+	}
+
+	var cmd *exec.Cmd
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+		fmt.Printf("🔄 %s\n", i18n.T("正在更新 %s ...", dir))
+		cmd = exec.Command("git", "-C", dir, "pull", "--ff-only")
+	} else {
+		entries, err := os.ReadDir(dir)
+		if err == nil && len(entries) > 0 {
+			return errors.New(i18n.T("%s 已存在且不是git仓库，请清空该目录或修改 RECIPES_DIR", dir))
+		}
+		if err := os.MkdirAll(filepath.Dir(dir), 0755); err != nil {
+			return errors.New(i18n.T("创建配方目录失败: %s", err.Error()))
+		}
+		fmt.Printf("🔄 %s\n", i18n.T("正在克隆 %s 到 %s ...", repo, dir))
+		cmd = exec.Command("git", "clone", "--depth", "1", repo, dir)
+	}
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return errors.New(i18n.T("同步团队配方失败: %s", err.Error()))
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"time"
+
+	"github.com/elecmonkey/prompt2cmd/internal/config"
+	"github.com/elecmonkey/prompt2cmd/internal/i18n"
+)
+
+// fileState 检测文件变化时比较的属性，不存在的文件为零值
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// configWatcher 通过比较修改时间和大小检测配置文件的变化
+// 只在处理每条输入之前检查一次，不需要后台线程，也不会在命令执行期间替换组件
+type configWatcher struct {
+	files  []string
+	states map[string]fileState
+}
+
+// newConfigWatcher 记录文件的当前状态
+func newConfigWatcher(files []string) *configWatcher {
+	watcher := &configWatcher{files: files}
+	watcher.states = watcher.snapshot()
+	return watcher
+}
+
+// snapshot 读取所有文件的当前状态
+func (w *configWatcher) snapshot() map[string]fileState {
+	states := make(map[string]fileState, len(w.files))
+	for _, file := range w.files {
+		if info, err := os.Stat(file); err == nil {
+			states[file] = fileState{modTime: info.ModTime(), size: info.Size()}
+		} else {
+			states[file] = fileState{}
+		}
+	}
+	return states
+}
+
+// changed 返回自上次检查以来是否有文件被修改、创建或删除
+func (w *configWatcher) changed() bool {
+	states := w.snapshot()
+	changed := false
+	for file, state := range states {
+		if w.states[file] != state {
+			changed = true
+			break
+		}
+	}
+	w.states = states
+	return changed
+}
+
+// watchConfig 开启 config.watch 时记录当前配置涉及的文件，否则停止检测
+func (s *session) watchConfig() {
+	s.watcher = nil
+	if !s.cfg.WatchConfig {
+		return
+	}
+	configManager, err := newConfigManagerWith(s.configFlags())
+	if err != nil {
+		return
+	}
+	files := configManager.WatchFiles()
+	if s.cfg.SecurityPolicy != nil {
+		files = append(files, s.cfg.SecurityPolicy.File)
+	}
+	s.watcher = newConfigWatcher(files)
+}
+
+// checkConfigChanged 配置文件变化时重新加载
+func (s *session) checkConfigChanged() {
+	if s.watcher == nil || !s.watcher.changed() {
+		return
+	}
+	fmt.Println("🔄 " + i18n.T("检测到配置文件变化，正在重新加载..."))
+	s.reload()
+}
+
+// reload 重新加载配置并重建模型、安全检查器等组件，新的配置无效时继续使用原来的配置
+// 历史记录和会话状态保持不变
+func (s *session) reload() {
+	cfg, err := s.loadConfig()
+	if err == nil {
+		old := s.cfg
+		if err = s.configure(cfg); err == nil {
+			printConfigWarnings(cfg, old)
+			s.reportReload(old, cfg)
+			s.watchConfig()
+			return
+		}
+	}
+	s.userInterface.DisplayError(errors.New(i18n.T("新的配置无效，继续使用原来的配置: %s", err.Error())))
+}
+
+// configFlags 返回全局选项和会话中切换的配置项，后者优先
+func (s *session) configFlags() map[string]string {
+	flags := maps.Clone(configFlags)
+	maps.Copy(flags, s.overrides)
+	return flags
+}
+
+// loadConfig 重新加载配置，保留会话中通过 /model 和 /provider 切换的设置
+func (s *session) loadConfig() (*config.Config, error) {
+	configManager, err := newConfigManagerWith(s.configFlags())
+	if err != nil {
+		return nil, err
+	}
+	return configManager.LoadConfig()
+}
+
+// reportReload 显示重新加载后发生变化的配置项
+func (s *session) reportReload(old, cfg *config.Config) {
+	var changes []string
+	for _, setting := range config.Settings {
+		before, after := old.SettingValue(setting.Env), cfg.SettingValue(setting.Env)
+		if before == after {
+			continue
+		}
+		if setting.Env == "LLM_API_KEY" {
+			before, after = maskSecret(before), maskSecret(after)
+		}
+		changes = append(changes, fmt.Sprintf("%s: %s → %s", setting.Path, displayValue(before), displayValue(after)))
+	}
+
+	if len(changes) == 0 {
+		fmt.Println("✅ " + i18n.T("已重新加载配置，配置项没有变化"))
+	} else {
+		fmt.Println("✅ " + i18n.T("已重新加载配置:"))
+		for _, change := range changes {
+			fmt.Printf("   %s\n", change)
+		}
+	}
+	if cfg.SecurityPolicy != nil {
+		fmt.Printf("   %s\n", i18n.T("安全策略 %s: %d 条规则", cfg.SecurityPolicy.File, len(cfg.SecurityPolicy.Rules)))
+	}
+	if cfg.HistoryBackend != old.HistoryBackend || cfg.MaxHistorySize != old.MaxHistorySize {
+		fmt.Println("⚠️ " + i18n.T("历史记录存储方式的修改在重新启动后生效"))
+	}
+}
+
+// displayValue 显示配置项的值，空值显示为 -
+func displayValue(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
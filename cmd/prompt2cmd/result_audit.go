@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/elecmonkey/prompt2cmd/internal/i18n"
+	"github.com/elecmonkey/prompt2cmd/internal/llm"
+	"github.com/elecmonkey/prompt2cmd/internal/processor"
+)
+
+// auditExecution 按审计策略请模型审计命令的执行结果并显示
+// 退出码已经足以判断结果时不请求模型，返回 nil
+func (s *session) auditExecution(command, result, prompt string, execution *processor.ExecutionResult) *llm.ExecutionAuditResult {
+	if !needsAudit(s.cfg.AuditPolicy, execution) {
+		if execution != nil {
+			statusEmoji := "✅"
+			if execution.ExitCode != 0 {
+				statusEmoji = "❌"
+			}
+			fmt.Printf("\n%s\n", i18n.T("%s 退出码 %d，按审计策略 %s 跳过模型审计", statusEmoji, execution.ExitCode, s.cfg.AuditPolicy))
+		}
+		return nil
+	}
+
+	provider := s.llmProvider
+	if s.auditProvider != nil {
+		provider = s.auditProvider
+	}
+
+	fmt.Println("\n🔍 " + i18n.T("正在审计执行结果..."))
+	auditResult, err := provider.AuditExecutionResult(command, result, prompt)
+	if err != nil {
+		fmt.Printf("❌ %s\n", i18n.T("审计失败: %s", err.Error()))
+		return nil
+	}
+
+	// 显示审计结果
+	statusEmoji := "✅"
+	if !auditResult.Success {
+		statusEmoji = "❌"
+	}
+	fmt.Printf("\n%s\n", i18n.T("%s 执行状态: %v", statusEmoji, auditResult.Success))
+	fmt.Printf("📋 %s\n", i18n.T("审计结果: %s", auditResult.Description))
+	return auditResult
+}
+
+// needsAudit 判断执行结果是否需要模型审计
+// on-failure 审计退出码非 0 或有错误输出的命令，on-nonzero-exit 只审计退出码非 0 的命令，
+// on-empty-output 只审计没有任何输出的命令；命令未能执行时除 never 外都会审计
+func needsAudit(policy string, execution *processor.ExecutionResult) bool {
+	if policy == "never" {
+		return false
+	}
+	if execution == nil {
+		return true
+	}
+	switch policy {
+	case "on-failure":
+		return execution.ExitCode != 0 || strings.TrimSpace(execution.Stderr) != ""
+	case "on-nonzero-exit":
+		return execution.ExitCode != 0
+	case "on-empty-output":
+		return strings.TrimSpace(execution.Output) == ""
+	default:
+		return true
+	}
+}
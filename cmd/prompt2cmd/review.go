@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/elecmonkey/prompt2cmd/internal/i18n"
+	"github.com/elecmonkey/prompt2cmd/internal/llm"
+	"github.com/elecmonkey/prompt2cmd/internal/security"
+)
+
+// reviewCommandRisk 按配置让模型复核命令风险，并与静态检查结果合并
+func reviewCommandRisk(provider llm.Provider, mode string, command string, prompt string, assessment *security.RiskAssessment) {
+	if !shouldReviewRisk(mode, assessment) {
+		return
+	}
+	reviewer, ok := provider.(llm.RiskReviewer)
+	if !ok {
+		return
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		cwd = i18n.T("未知路径")
+	}
+
+	fmt.Println("\n🧐 " + i18n.T("正在请模型复核命令风险..."))
+	review, err := reviewer.ReviewCommandRisk(command, cwd, prompt)
+	if err != nil {
+		fmt.Printf("⚠️ %s\n", i18n.T("风险复核失败，仅使用静态检查结果: %s", err.Error()))
+		return
+	}
+
+	level, err := security.ParseRiskLevel(review.Level)
+	if err != nil {
+		fmt.Printf("⚠️ %s\n", i18n.T("风险复核结果无效，仅使用静态检查结果: %s", err.Error()))
+		return
+	}
+
+	reversible := i18n.T("可恢复")
+	if !review.Reversible {
+		reversible = i18n.T("不可恢复")
+	}
+	assessment.CombineReview(level, i18n.T("评估为「%s」（%s），影响范围: %s。%s",
+		level.Label(), reversible, review.BlastRadius, review.Reason))
+}
+
+// shouldReviewRisk 判断是否需要模型复核
+// auto 模式下跳过只读命令、已被阻止的命令和已经是严重级别的命令
+func shouldReviewRisk(mode string, assessment *security.RiskAssessment) bool {
+	if assessment.Blocked {
+		return false
+	}
+	switch mode {
+	case "always":
+		return true
+	case "auto":
+		return !assessment.ReadOnly && assessment.Level < security.RiskCritical
+	default:
+		return false
+	}
+}
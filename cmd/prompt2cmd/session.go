@@ -0,0 +1,326 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/elecmonkey/prompt2cmd/internal/auditlog"
+	"github.com/elecmonkey/prompt2cmd/internal/config"
+	"github.com/elecmonkey/prompt2cmd/internal/history"
+	"github.com/elecmonkey/prompt2cmd/internal/i18n"
+	"github.com/elecmonkey/prompt2cmd/internal/llm"
+	"github.com/elecmonkey/prompt2cmd/internal/llm/deepseek"
+	"github.com/elecmonkey/prompt2cmd/internal/llm/moonshot"
+	"github.com/elecmonkey/prompt2cmd/internal/llm/ollama"
+	"github.com/elecmonkey/prompt2cmd/internal/processor"
+	"github.com/elecmonkey/prompt2cmd/internal/redact"
+	"github.com/elecmonkey/prompt2cmd/internal/security"
+	"github.com/elecmonkey/prompt2cmd/internal/snippet"
+	"github.com/elecmonkey/prompt2cmd/internal/ui"
+)
+
+// session 执行命令所需的各个组件，交互模式和 history rerun 共用
+type session struct {
+	cfg             *config.Config
+	llmProvider     llm.Provider
+	auditProvider   llm.Provider // 配置了 llm.audit_model 时用于审计执行结果，否则为 nil
+	cmdProcessor    processor.CommandProcessor
+	securityChecker *security.DefaultSecurityChecker
+	historyManager  history.HistoryStore
+	auditLogger     *auditlog.Logger
+	userInterface   ui.UserInterface
+	reader          *bufio.Reader
+	snippets        *snippet.Store
+	recipes         *snippet.Catalog
+	// 本次会话中最近一条命令及其需求，供 /save 使用
+	lastRunPrompt  string
+	lastRunCommand string
+	// 检测配置文件的变化，未开启 config.watch 时为 nil
+	watcher *configWatcher
+	// 通过 /model 和 /provider 切换的配置项，以环境变量名为键，优先于配置文件和命令行选项
+	overrides map[string]string
+}
+
+// newSession 根据配置初始化各个组件
+func newSession(cfg *config.Config) (*session, error) {
+	s := &session{
+		userInterface: ui.NewTerminalUI(),
+		reader:        bufio.NewReader(os.Stdin),
+		snippets:      snippet.NewStore(""),
+	}
+	if err := s.configure(cfg); err != nil {
+		return nil, err
+	}
+	s.historyManager = newHistoryManager(cfg)
+	s.watchConfig()
+	return s, nil
+}
+
+// configure 根据配置创建模型、安全检查器、命令处理器、审计日志和团队配方
+// 所有组件都创建成功后才替换当前的组件，失败时会话保持不变
+func (s *session) configure(cfg *config.Config) error {
+	// 初始化 LLM 提供商
+	llmProvider, err := newSessionProvider(cfg)
+	if err != nil {
+		return err
+	}
+
+	// 审计执行结果使用单独的模型，通常是更便宜、更快的模型
+	var auditProvider llm.Provider
+	if cfg.AuditModel != "" && cfg.AuditModel != cfg.LLMModel {
+		auditCfg := *cfg
+		auditCfg.LLMModel = cfg.AuditModel
+		if auditProvider, err = newSessionProvider(&auditCfg); err != nil {
+			return err
+		}
+	}
+
+	// 初始化安全检查器
+	securityChecker := security.NewSecurityChecker(cfg.DangerousCommands)
+	securityChecker.Policy = cfg.SecurityPolicy
+	securityChecker.PathGuard = security.NewPathGuard(cfg.ProtectedPaths, cfg.BlockProtectedPaths, cfg.ProtectMountPoints)
+
+	// 初始化命令处理器
+	cmdProcessor := processor.NewOSCommandProcessor()
+	cmdProcessor.Shell = cfg.Shell
+
+	// 初始化审计日志
+	var auditLogger *auditlog.Logger
+	if cfg.AuditLogEnabled {
+		auditLogger = auditlog.NewLogger(cfg.AuditLogFile)
+	}
+
+	s.cfg = cfg
+	s.llmProvider = llmProvider
+	s.auditProvider = auditProvider
+	s.cmdProcessor = cmdProcessor
+	s.securityChecker = securityChecker
+	s.auditLogger = auditLogger
+	s.recipes = loadRecipes(cfg.RecipesDir)
+	i18n.SetLanguage(cfg.Language)
+	return nil
+}
+
+// newLLMProvider 根据配置创建 LLM 提供商
+func newLLMProvider(cfg *config.Config) (llm.Provider, error) {
+	if err := cfg.ResolveAPIKey(); err != nil {
+		return nil, err
+	}
+	switch cfg.LLMProvider {
+	case "deepseek":
+		return deepseek.NewProvider(cfg), nil
+	case "moonshot":
+		return moonshot.NewProvider(cfg), nil
+	case "ollama":
+		return ollama.NewProvider(cfg), nil
+	default:
+		return nil, errors.New(i18n.T("不支持的LLM提供商: %s", cfg.LLMProvider))
+	}
+}
+
+// newSessionProvider 创建会话使用的 LLM 提供商，远程模型在发送前对敏感信息脱敏
+func newSessionProvider(cfg *config.Config) (llm.Provider, error) {
+	llmProvider, err := newLLMProvider(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.RedactSecrets && !llmProvider.IsLocal() {
+		redactingProvider := redact.NewProvider(llmProvider)
+		redactingProvider.OnRedact = func(stage string, report redact.Report) {
+			fmt.Printf("🔒 %s\n", i18n.T("已在发送前脱敏 %d 处敏感信息: %s", report.Total(), report.String()))
+		}
+		llmProvider = redactingProvider
+	}
+	return llmProvider, nil
+}
+
+// handleDirective 处理以 / 开头的会话指令
+func (s *session) handleDirective(input string) {
+	fields := strings.Fields(input)
+	switch fields[0] {
+	case "/history":
+		limit := 10
+		if len(fields) > 1 {
+			n, err := strconv.Atoi(fields[1])
+			if err != nil || n < 1 {
+				s.userInterface.DisplayError(errors.New(i18n.T("无效的数量: %s", fields[1])))
+				return
+			}
+			limit = n
+		}
+		s.showRecentHistory(limit)
+	case "/rerun":
+		if len(fields) != 2 {
+			s.userInterface.DisplayError(errors.New(i18n.T("用法: /rerun <编号|ID>")))
+			return
+		}
+		s.rerunHistory(fields[1])
+	case "/save":
+		s.saveSnippet(strings.TrimSpace(strings.TrimPrefix(input, fields[0])))
+	case "/run":
+		s.runSnippet(strings.TrimSpace(strings.TrimPrefix(input, fields[0])))
+	case "/snippets":
+		s.listSnippets(fields[1:])
+	case "/reload":
+		s.reload()
+	case "/model":
+		s.switchModel(fields[1:])
+	case "/provider":
+		s.switchProvider(fields[1:])
+	case "/prompt":
+		s.handlePromptDirective(fields[1:])
+	default:
+		s.userInterface.DisplayError(errors.New(i18n.T("未知的指令: %s（可用指令: /history [数量]、/rerun <编号>、/save <名称>、/run <名称>、/snippets、/reload、/model [名称]、/provider [名称]、/prompt show [模板]）", fields[0])))
+	}
+}
+
+// handlePrompt 为一条需求生成命令，经确认后执行；与需求匹配的团队配方优先
+func (s *session) handlePrompt(prompt string) {
+	// 优先使用与需求匹配的团队配方
+	if s.offerRecipe(prompt) {
+		return
+	}
+
+	// 按与当前需求的相关度选择历史记录
+	historyRecords, err := selectHistoryContext(s.historyManager, s.cfg, prompt)
+	if err != nil {
+		fmt.Printf("⚠️ %s\n", i18n.T("无法获取历史记录: %s", err.Error()))
+		fmt.Println(i18n.T("将继续生成命令，但不使用历史上下文"))
+		historyRecords = []history.HistoryRecord{}
+	}
+
+	// 生成命令
+	fmt.Println("\n🔄 " + i18n.T("正在生成命令..."))
+	// 使用历史记录作为上下文
+	command, explanation, err := s.llmProvider.GenerateCommand(prompt, historyRecords)
+	if err != nil {
+		s.userInterface.DisplayError(err)
+		return
+	}
+
+	// 根据操作系统处理命令
+	command, err = s.cmdProcessor.ProcessCommand(command)
+	if err != nil {
+		s.userInterface.DisplayError(err)
+		return
+	}
+
+	// 显示生成的命令和解释
+	s.userInterface.DisplayGeneratedCommand(command, explanation)
+
+	// 评估风险、确认并执行
+	s.runCommand(prompt, command)
+}
+
+// runCommand 评估命令风险，经用户确认后执行，并记录审计日志和历史记录
+// generatedCommand 为模型生成（或从历史记录中取出）的原始命令，用于判断用户是否编辑过
+func (s *session) runCommand(prompt, generatedCommand string) {
+	s.runCommandWithRisk(prompt, generatedCommand, nil)
+}
+
+// runCommandWithRisk 与 runCommand 相同，adjust 在每次评估风险后调用，用于合并团队配方声明的风险等级
+func (s *session) runCommandWithRisk(prompt, generatedCommand string, adjust func(assessment *security.RiskAssessment)) {
+	command := generatedCommand
+
+	// 评估命令风险
+	assessment := s.assessCommand(command, prompt, adjust)
+
+	// 获取用户确认
+	for {
+		s.lastRunPrompt, s.lastRunCommand = prompt, command
+		if assessment.Blocked {
+			fmt.Println("\n❌ " + i18n.T("命令已被安全策略阻止"))
+			appendAuditEntry(s.auditLogger, newAuditEntry(prompt, generatedCommand, command, assessment))
+			return
+		}
+
+		var confirmed bool
+		var err error
+		if s.cfg.AutoConfirmReadOnly && assessment.ReadOnly {
+			// 只读命令按配置跳过确认
+			fmt.Println("\n✅ " + i18n.T("只读命令，已自动确认执行"))
+			confirmed = true
+		} else {
+			confirmed, err = s.userInterface.GetRiskConfirmation(assessment)
+		}
+		if err != nil {
+			if err.Error() == "EDIT_COMMAND" {
+				// 用户要求编辑命令
+				fmt.Print("\n✏️ " + i18n.T("请编辑命令: "))
+				command, err = s.reader.ReadString('\n')
+				if err != nil {
+					s.userInterface.DisplayError(err)
+					return
+				}
+				command = strings.TrimSpace(command)
+				if command == "" {
+					s.userInterface.DisplayError(errors.New(i18n.T("命令不能为空")))
+					return
+				}
+				// 编辑后的命令需要重新评估风险
+				assessment = s.assessCommand(command, prompt, adjust)
+				continue
+			}
+			s.userInterface.DisplayError(err)
+			return
+		}
+
+		auditEntry := newAuditEntry(prompt, generatedCommand, command, assessment)
+		auditEntry.Confirmed = confirmed
+		if !confirmed {
+			fmt.Println("\n❌ " + i18n.T("命令已取消"))
+			appendAuditEntry(s.auditLogger, auditEntry)
+			return
+		}
+
+		s.executeCommand(prompt, generatedCommand, command, assessment, auditEntry)
+		return
+	}
+}
+
+// assessCommand 评估命令风险并显示评估结果
+func (s *session) assessCommand(command, prompt string, adjust func(assessment *security.RiskAssessment)) *security.RiskAssessment {
+	assessment := s.securityChecker.AssessCommand(command)
+	if adjust != nil {
+		adjust(assessment)
+	}
+	reviewCommandRisk(s.llmProvider, s.cfg.RiskReviewMode, command, prompt, assessment)
+	s.userInterface.DisplayRiskAssessment(assessment)
+	return assessment
+}
+
+// executeCommand 执行已确认的命令，审计执行结果并记录
+func (s *session) executeCommand(prompt, generatedCommand, command string, assessment *security.RiskAssessment, auditEntry *auditlog.Entry) {
+	// 执行命令
+	fmt.Println("\n⚙️ " + i18n.T("正在执行命令..."))
+	execution, execErr := s.cmdProcessor.Execute(command)
+	result := ""
+	if execution != nil {
+		result = execution.Output
+	}
+
+	// 显示执行结果（无论成功还是失败）
+	if execErr != nil {
+		s.userInterface.DisplayError(execErr)
+		result = i18n.T("执行失败: %s", execErr.Error())
+	} else {
+		s.userInterface.DisplayExecutionResult(result)
+	}
+
+	// 按审计策略使用LLM审计执行结果
+	auditResult := s.auditExecution(command, result, prompt, execution)
+
+	// 写入审计日志
+	setAuditExecution(auditEntry, execution, auditResult)
+	appendAuditEntry(s.auditLogger, auditEntry)
+
+	// 添加到历史记录
+	record := newHistoryRecord(s.cfg, prompt, generatedCommand, command, assessment, execution, auditResult)
+	if err := s.historyManager.AddRecord(record); err != nil {
+		fmt.Printf("⚠️ %s\n", i18n.T("保存历史记录失败: %s", err.Error()))
+	}
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/elecmonkey/prompt2cmd/internal/i18n"
+	"github.com/elecmonkey/prompt2cmd/internal/snippet"
+)
+
+// lastCommand 返回本次会话中最近的命令及其需求；会话中还没有命令时使用最近一条历史记录
+func (s *session) lastCommand() (string, string, bool) {
+	if s.lastRunCommand != "" {
+		return s.lastRunPrompt, s.lastRunCommand, true
+	}
+	records, err := s.historyManager.GetHistory(20)
+	if err != nil {
+		return "", "", false
+	}
+	for i := len(records) - 1; i >= 0; i-- {
+		if records[i].Source == "" {
+			return records[i].Prompt, records[i].Command, true
+		}
+	}
+	return "", "", false
+}
+
+// saveSnippet 处理 /save <名称> [命令模板]，将最近的命令（或指定的模板）保存为片段
+func (s *session) saveSnippet(input string) {
+	if input == "" {
+		s.userInterface.DisplayError(errors.New(i18n.T("用法: /save <名称> [命令模板]")))
+		return
+	}
+	name, template, _ := strings.Cut(input, " ")
+	if err := snippet.ValidateName(name); err != nil {
+		s.userInterface.DisplayError(err)
+		return
+	}
+
+	prompt, command, ok := s.lastCommand()
+	if template = strings.TrimSpace(template); template != "" {
+		// 直接在指令中给出模板，与最近的需求无关
+		prompt, command = "", template
+	} else {
+		if !ok {
+			s.userInterface.DisplayError(errors.New(i18n.T("还没有可以保存的命令")))
+			return
+		}
+		fmt.Printf("📌 %s\n", i18n.T("最近的命令: %s", command))
+		fmt.Print("✏️ " + i18n.T("用 {{名称}} 或 {{名称:默认值}} 标记可变部分（直接回车保存原命令）: "))
+		input, err := s.reader.ReadString('\n')
+		if err != nil {
+			s.userInterface.DisplayError(err)
+			return
+		}
+		if input = strings.TrimSpace(input); input != "" {
+			command = input
+		}
+	}
+
+	replaced, err := s.snippets.Save(snippet.Snippet{Name: name, Command: command, Description: prompt})
+	if err != nil {
+		s.userInterface.DisplayError(err)
+		return
+	}
+	action := i18n.T("已保存")
+	if replaced {
+		action = i18n.T("已更新")
+	}
+	fmt.Printf("✅ %s\n", i18n.T("%s片段 %s: %s", action, name, command))
+	if params := snippet.Params(command); len(params) > 0 {
+		fmt.Printf("   %s\n", i18n.T("参数: %s（使用 /run %s 名称=值 运行）", formatParams(params), name))
+	}
+}
+
+// runSnippet 处理 /run <名称> [名称=值 ...]，不调用模型，直接经过安全检查和确认后执行片段
+// 含有空格的值可以用引号括起来，例如 message="hello world"
+func (s *session) runSnippet(input string) {
+	args, err := snippet.SplitArgs(input)
+	if err != nil {
+		s.userInterface.DisplayError(err)
+		return
+	}
+	if len(args) == 0 {
+		s.userInterface.DisplayError(errors.New(i18n.T("用法: /run <名称> [参数名=值 ...]")))
+		return
+	}
+	values, err := snippet.ParseAssignments(args[1:])
+	if err != nil {
+		s.userInterface.DisplayError(err)
+		return
+	}
+	saved, err := s.snippets.Get(args[0])
+	if errors.Is(err, snippet.ErrNotFound) {
+		// 个人片段中没有时查找团队配方
+		if recipe, ok := s.recipes.Get(args[0]); ok {
+			s.runRecipe(recipe, values)
+			return
+		}
+	}
+	if err != nil {
+		s.userInterface.DisplayError(err)
+		return
+	}
+
+	command, ok := s.renderTemplate(saved.Command, values)
+	if !ok {
+		return
+	}
+
+	prompt := saved.Description
+	if prompt == "" {
+		prompt = i18n.T("运行片段 %s", saved.Name)
+	}
+	s.userInterface.DisplayGeneratedCommand(command, i18n.T("来自片段 %s", saved.Name))
+	s.runCommand(prompt, command)
+}
+
+// renderTemplate 逐个询问未提供的参数后生成命令，失败时显示错误并返回 false
+func (s *session) renderTemplate(template string, values map[string]string) (string, bool) {
+	for _, name := range snippet.MissingParams(template, values) {
+		fmt.Printf("❓ %s", i18n.T("请输入参数 %s: ", name))
+		input, err := s.reader.ReadString('\n')
+		if err != nil {
+			s.userInterface.DisplayError(err)
+			return "", false
+		}
+		values[name] = strings.TrimSpace(input)
+	}
+
+	command, err := snippet.Render(template, values)
+	if err != nil {
+		s.userInterface.DisplayError(err)
+		return "", false
+	}
+	return command, true
+}
+
+// listSnippets 处理 /snippets [delete <名称>]
+func (s *session) listSnippets(args []string) {
+	if len(args) > 0 {
+		if args[0] != "delete" || len(args) != 2 {
+			s.userInterface.DisplayError(errors.New(i18n.T("用法: /snippets [delete <名称>]")))
+			return
+		}
+		if err := s.snippets.Delete(args[1]); err != nil {
+			s.userInterface.DisplayError(err)
+			return
+		}
+		fmt.Printf("✅ %s\n", i18n.T("已删除片段 %s", args[1]))
+		return
+	}
+
+	snippets, err := s.snippets.List()
+	if err != nil {
+		s.userInterface.DisplayError(err)
+		return
+	}
+	if len(snippets) == 0 {
+		fmt.Println(i18n.T("还没有保存的片段，使用 /save <名称> 保存最近的命令"))
+		return
+	}
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, i18n.T("名称\t参数\t命令"))
+	for _, saved := range snippets {
+		fmt.Fprintf(writer, "%s\t%s\t%s\n", saved.Name, formatParams(snippet.Params(saved.Command)), truncateText(saved.Command, 60))
+	}
+	writer.Flush()
+}
+
+// formatParams 显示参数列表，有默认值的参数显示为 名称=默认值
+func formatParams(params []snippet.Param) string {
+	if len(params) == 0 {
+		return "-"
+	}
+	names := make([]string, len(params))
+	for i, param := range params {
+		names[i] = param.Name
+		if param.HasDefault {
+			names[i] += "=" + param.Default
+		}
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/elecmonkey/prompt2cmd/internal/config"
+	"github.com/elecmonkey/prompt2cmd/internal/i18n"
+)
+
+// profileName 通过 --profile 选择的配置档案
+var profileName string
+
+// configFlags 通过全局选项设置的配置项，以环境变量名为键
+var configFlags = map[string]string{}
+
+// globalFlags 设置配置项的全局选项
+var globalFlags = map[string]string{
+	"--provider": "LLM_PROVIDER",
+	"--model":    "LLM_MODEL",
+}
+
+// parseGlobalFlags 解析子命令之前的全局选项，返回剩余的参数
+func parseGlobalFlags(args []string) ([]string, error) {
+	for len(args) > 0 && strings.HasPrefix(args[0], "--") {
+		name, value, hasValue := strings.Cut(args[0], "=")
+		env, isSetting := globalFlags[name]
+		if name != "--profile" && !isSetting {
+			// 不是全局选项，交给子命令处理
+			return args, nil
+		}
+		if hasValue {
+			args = args[1:]
+		} else if len(args) > 1 {
+			value = args[1]
+			args = args[2:]
+		}
+		if value == "" {
+			return nil, errors.New(i18n.T("%s 需要指定一个值", name))
+		}
+		if name == "--profile" {
+			profileName = value
+		} else {
+			configFlags[env] = value
+		}
+	}
+	return args, nil
+}
+
+// runSubcommand 执行子命令，返回进程退出码
+func runSubcommand(args []string) int {
+	switch args[0] {
+	case "policy":
+		return runPolicyCommand(args[1:])
+	case "history":
+		return runHistoryCommand(args[1:])
+	case "audit":
+		return runAuditCommand(args[1:])
+	case "recipe":
+		return runRecipeCommand(args[1:])
+	case "config":
+		return runConfigCommand(args[1:])
+	case "init":
+		return runInitCommand(args[1:])
+	case "doctor":
+		return runDoctorCommand(args[1:])
+	case "help", "-h", "--help":
+		printUsage()
+		return 0
+	case "version", "-v", "--version":
+		fmt.Printf("prompt2cmd v%s\n", appVersion)
+		return 0
+	default:
+		fmt.Printf("❌ %s\n", i18n.T("未知的子命令: %s", args[0]))
+		printUsage()
+		return 2
+	}
+}
+
+// printUsage 打印命令行用法
+func printUsage() {
+	fmt.Print(i18n.T(`Prompt2Cmd v%s - 自然语言转终端命令工具
+
+用法:
+  prompt2cmd [全局选项] [子命令]
+  prompt2cmd                       启动交互模式
+  prompt2cmd init [--offline]      运行初始设置向导，生成配置文件
+  prompt2cmd policy test "<命令>"   显示命令命中的安全策略规则和风险等级
+  prompt2cmd history <list|search|show|rerun|delete|clear|export|import>  浏览和管理历史记录
+  prompt2cmd recipe <list|show|sync>  查看和同步团队配方
+  prompt2cmd audit verify           校验命令审计日志是否被篡改
+  prompt2cmd config show [--origin]  显示生效的配置及每一项的来源
+  prompt2cmd config set-key         将API密钥保存到系统密钥环
+  prompt2cmd doctor [--offline]     检查配置、文件权限、模型接口、shell和历史记录
+  prompt2cmd version               显示版本
+  prompt2cmd help                  显示帮助
+
+全局选项:
+  --profile <名称>                 使用配置文件中的配置档案，也可以通过 PROMPT2CMD_PROFILE 环境变量指定
+  --provider <名称>                本次运行使用的LLM提供商
+  --model <名称>                   本次运行使用的模型
+`, appVersion))
+}
+
+// newConfigManager 创建合并各个配置层的配置管理器，项目配置从当前目录开始查找
+func newConfigManager() (*config.LayeredConfigManager, error) {
+	return newConfigManagerWith(configFlags)
+}
+
+// newConfigManagerWith 与 newConfigManager 相同，flags 代替全局选项设置的配置项
+func newConfigManagerWith(flags map[string]string) (*config.LayeredConfigManager, error) {
+	workingDir, err := os.Getwd()
+	if err != nil {
+		return nil, errors.New(i18n.T("获取当前工作目录失败: %s", err.Error()))
+	}
+	return config.NewConfigManager(workingDir, profileName, flags), nil
+}
+
+// loadConfig 合并系统、用户、项目配置、环境变量和命令行选项并加载配置
+func loadConfig() (*config.Config, error) {
+	configManager, err := newConfigManager()
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := configManager.LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+	i18n.SetLanguage(cfg.Language)
+	printConfigWarnings(cfg, nil)
+	return cfg, nil
+}
+
+// printConfigWarnings 显示读取配置时产生的警告，跳过 previous 中已经显示过的警告
+func printConfigWarnings(cfg *config.Config, previous *config.Config) {
+	for _, warning := range cfg.Warnings {
+		if previous != nil && slices.Contains(previous.Warnings, warning) {
+			continue
+		}
+		fmt.Println(warning)
+	}
+}
+
+// hasConfigFile 返回是否存在任何配置文件，配置文件无效时也返回 true
+func hasConfigFile() bool {
+	configManager, err := newConfigManager()
+	if err != nil {
+		return true
+	}
+	resolved, err := configManager.Resolve()
+	return err != nil || len(resolved.Files) > 0
+}
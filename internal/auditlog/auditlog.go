@@ -0,0 +1,289 @@
+package auditlog
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/elecmonkey/prompt2cmd/internal/filelock"
+	"github.com/elecmonkey/prompt2cmd/internal/i18n"
+)
+
+// genesisHash 第一条记录的 prev_hash
+var genesisHash = strings.Repeat("0", 64)
+
+// Entry 一条审计日志记录
+// 每条记录包含上一条记录的哈希，任何修改、删除或插入都会破坏哈希链
+type Entry struct {
+	Seq              int64    `json:"seq"`
+	Timestamp        string   `json:"timestamp"`
+	User             string   `json:"user"`
+	Host             string   `json:"host"`
+	Cwd              string   `json:"cwd"`
+	Prompt           string   `json:"prompt"`
+	GeneratedCommand string   `json:"generated_command"`
+	Command          string   `json:"command"` // 实际执行的命令（可能被用户编辑过）
+	Edited           bool     `json:"edited"`
+	RiskLevel        string   `json:"risk_level"`
+	RiskReasons      []string `json:"risk_reasons,omitempty"`
+	Blocked          bool     `json:"blocked"`
+	Confirmed        bool     `json:"confirmed"`
+	ExitCode         *int     `json:"exit_code,omitempty"` // 未执行时为空
+	AuditSuccess     *bool    `json:"audit_success,omitempty"`
+	AuditDescription string   `json:"audit_description,omitempty"`
+	PrevHash         string   `json:"prev_hash"`
+	Hash             string   `json:"hash"`
+}
+
+// computeHash 计算记录的哈希（不含 hash 字段本身）
+func (e *Entry) computeHash() (string, error) {
+	unhashed := *e
+	unhashed.Hash = ""
+	data, err := json.Marshal(&unhashed)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// head 记录最后一条日志的序号和哈希，用于发现尾部被截断
+type head struct {
+	Seq  int64  `json:"seq"`
+	Hash string `json:"hash"`
+}
+
+// Logger 只追加写入的审计日志
+type Logger struct {
+	path string
+}
+
+// NewLogger 创建审计日志，path 为 JSONL 文件路径
+func NewLogger(path string) *Logger {
+	return &Logger{path: path}
+}
+
+// Path 返回审计日志文件路径
+func (l *Logger) Path() string {
+	return l.path
+}
+
+// headPath 返回记录链头的文件路径
+func headPath(path string) string {
+	return path + ".head"
+}
+
+// Append 追加一条记录，自动填写序号、时间和哈希链
+// 记录和链头文件分两步写入，两步之间中断时链头会落后一条，Verify 会容忍这种情况
+func (l *Logger) Append(entry *Entry) error {
+	if err := os.MkdirAll(filepath.Dir(l.path), 0700); err != nil {
+		return errors.New(i18n.T("创建审计日志目录失败: %s", err.Error()))
+	}
+
+	// 多个会话同时写入时保证哈希链连续
+	lock := filelock.New(l.path + ".lock")
+	if err := lock.Lock(); err != nil {
+		return errors.New(i18n.T("锁定审计日志失败: %s", err.Error()))
+	}
+	defer lock.Unlock()
+
+	file, err := os.OpenFile(l.path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return errors.New(i18n.T("打开审计日志失败: %s", err.Error()))
+	}
+	defer file.Close()
+
+	last, err := readLastEntry(file)
+	if err != nil {
+		return errors.New(i18n.T("审计日志已损坏，拒绝继续追加（可运行 prompt2cmd audit verify 检查）: %s", err.Error()))
+	}
+
+	entry.Seq = 1
+	entry.PrevHash = genesisHash
+	if last != nil {
+		entry.Seq = last.Seq + 1
+		entry.PrevHash = last.Hash
+	}
+	if entry.Timestamp == "" {
+		entry.Timestamp = time.Now().Format(time.RFC3339Nano)
+	}
+	entry.Hash, err = entry.computeHash()
+	if err != nil {
+		return errors.New(i18n.T("计算审计日志哈希失败: %s", err.Error()))
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return errors.New(i18n.T("序列化审计日志失败: %s", err.Error()))
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return errors.New(i18n.T("写入审计日志失败: %s", err.Error()))
+	}
+	if err := file.Sync(); err != nil {
+		return errors.New(i18n.T("同步审计日志失败: %s", err.Error()))
+	}
+
+	return writeHead(l.path, head{Seq: entry.Seq, Hash: entry.Hash})
+}
+
+// readLastEntry 读取文件的最后一条记录，文件为空时返回 nil
+func readLastEntry(file *os.File) (*Entry, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	size := info.Size()
+	if size == 0 {
+		return nil, nil
+	}
+
+	// 从文件末尾向前读取，直到找到完整的最后一行
+	const chunk = 4096
+	var tail []byte
+	offset := size
+	for offset > 0 {
+		readSize := int64(chunk)
+		if offset < readSize {
+			readSize = offset
+		}
+		offset -= readSize
+		buf := make([]byte, readSize)
+		if _, err := file.ReadAt(buf, offset); err != nil && err != io.EOF {
+			return nil, err
+		}
+		tail = append(buf, tail...)
+		if bytes.Count(tail, []byte{'\n'}) >= 2 || offset == 0 {
+			break
+		}
+	}
+
+	if tail[len(tail)-1] != '\n' {
+		return nil, errors.New(i18n.T("最后一条记录不完整"))
+	}
+	lines := bytes.Split(bytes.TrimRight(tail, "\n"), []byte{'\n'})
+	var entry Entry
+	if err := json.Unmarshal(lines[len(lines)-1], &entry); err != nil {
+		return nil, errors.New(i18n.T("解析最后一条记录失败: %s", err.Error()))
+	}
+	return &entry, nil
+}
+
+// writeHead 原子地更新链头文件
+func writeHead(path string, h head) error {
+	data, err := json.Marshal(h)
+	if err != nil {
+		return err
+	}
+	tmp := headPath(path) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return errors.New(i18n.T("写入审计日志链头失败: %s", err.Error()))
+	}
+	if err := os.Rename(tmp, headPath(path)); err != nil {
+		return errors.New(i18n.T("写入审计日志链头失败: %s", err.Error()))
+	}
+	return nil
+}
+
+// VerifyResult 审计日志校验结果
+type VerifyResult struct {
+	Entries  int      // 校验通过的记录数
+	Problems []string // 发现的问题，为空表示日志完整
+}
+
+// OK 返回日志是否完整
+func (r *VerifyResult) OK() bool {
+	return len(r.Problems) == 0
+}
+
+// Verify 校验审计日志的哈希链，发现修改、插入、删除和截断
+func Verify(path string) (*VerifyResult, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, errors.New(i18n.T("打开审计日志失败: %s", err.Error()))
+	}
+	defer file.Close()
+
+	result := &VerifyResult{}
+	prevHash := genesisHash
+	var prevSeq int64
+	// 最后一条记录的 prev_hash，用于识别链头落后一条的情况
+	lastPrevHash := genesisHash
+
+	reader := bufio.NewReader(file)
+	lineNo := 0
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) == 0 && err == io.EOF {
+			break
+		}
+		if err != nil && err != io.EOF {
+			return nil, errors.New(i18n.T("读取审计日志失败: %s", err.Error()))
+		}
+		lineNo++
+
+		if line[len(line)-1] != '\n' {
+			result.Problems = append(result.Problems, i18n.T("第 %d 行: 记录不完整，文件可能被截断", lineNo))
+			break
+		}
+
+		var entry Entry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			result.Problems = append(result.Problems, i18n.T("第 %d 行: 无法解析: %s", lineNo, err.Error()))
+			break
+		}
+
+		if entry.Seq != prevSeq+1 {
+			result.Problems = append(result.Problems, i18n.T("第 %d 行: 序号应为 %d，实际为 %d，记录可能被删除或插入", lineNo, prevSeq+1, entry.Seq))
+		}
+		if entry.PrevHash != prevHash {
+			result.Problems = append(result.Problems, i18n.T("第 %d 行: prev_hash 与上一条记录不一致，哈希链已断开", lineNo))
+		}
+		hash, err := entry.computeHash()
+		if err != nil {
+			return nil, err
+		}
+		if hash != entry.Hash {
+			result.Problems = append(result.Problems, i18n.T("第 %d 行: 哈希不匹配，记录内容被修改", lineNo))
+		}
+		if len(result.Problems) > 0 {
+			break
+		}
+
+		result.Entries++
+		prevSeq = entry.Seq
+		lastPrevHash = prevHash
+		prevHash = entry.Hash
+	}
+
+	// 与链头比较，发现尾部记录被删除
+	// 链头比日志少一条时说明追加时在写入链头前中断，不视为问题
+	if result.OK() {
+		data, err := os.ReadFile(headPath(path))
+		switch {
+		case os.IsNotExist(err):
+			if result.Entries > 1 {
+				result.Problems = append(result.Problems, i18n.T("缺少链头文件，无法确认尾部记录是否被删除"))
+			}
+		case err != nil:
+			return nil, errors.New(i18n.T("读取审计日志链头失败: %s", err.Error()))
+		default:
+			var h head
+			if err := json.Unmarshal(data, &h); err != nil {
+				result.Problems = append(result.Problems, i18n.T("链头文件无法解析: %s", err.Error()))
+			} else if (h.Seq != prevSeq || h.Hash != prevHash) && (h.Seq != prevSeq-1 || h.Hash != lastPrevHash) {
+				result.Problems = append(result.Problems,
+					i18n.T("日志最后一条记录为 #%d，但链头记录为 #%d，尾部记录可能被删除", prevSeq, h.Seq))
+			}
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,160 @@
+package auditlog
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// writeEntries 写入 n 条记录并返回日志路径
+func writeEntries(t *testing.T, n int) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	logger := NewLogger(path)
+	for i := 0; i < n; i++ {
+		if err := logger.Append(&Entry{Command: fmt.Sprintf("echo %d", i), RiskLevel: "safe"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return path
+}
+
+// readLines 读取日志的各行（不含换行符）
+func readLines(t *testing.T, path string) [][]byte {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bytes.Split(bytes.TrimRight(data, "\n"), []byte{'\n'})
+}
+
+// writeLines 用给定的行覆盖日志
+func writeLines(t *testing.T, path string, lines [][]byte) {
+	t.Helper()
+	data := append(bytes.Join(lines, []byte{'\n'}), '\n')
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAppendThenVerify(t *testing.T) {
+	path := writeEntries(t, 3)
+
+	result, err := Verify(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.OK() || result.Entries != 3 {
+		t.Errorf("Verify = %d entries, problems %v, want 3 and none", result.Entries, result.Problems)
+	}
+}
+
+func TestVerifyDetectsTampering(t *testing.T) {
+	tests := []struct {
+		name   string
+		tamper func(lines [][]byte) [][]byte
+	}{
+		{"edited field", func(lines [][]byte) [][]byte {
+			lines[1] = bytes.Replace(lines[1], []byte("echo 1"), []byte("echo X"), 1)
+			return lines
+		}},
+		{"deleted middle line", func(lines [][]byte) [][]byte {
+			return append(lines[:1:1], lines[2:]...)
+		}},
+		{"truncated tail", func(lines [][]byte) [][]byte {
+			return lines[:len(lines)-2]
+		}},
+		{"reordered lines", func(lines [][]byte) [][]byte {
+			lines[1], lines[2] = lines[2], lines[1]
+			return lines
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeEntries(t, 4)
+			writeLines(t, path, tt.tamper(readLines(t, path)))
+
+			result, err := Verify(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.OK() {
+				t.Errorf("Verify passed after %s", tt.name)
+			}
+		})
+	}
+}
+
+func TestVerifyToleratesHeadOneBehind(t *testing.T) {
+	path := writeEntries(t, 1)
+	var heads [][]byte
+	for i := 1; i <= 2; i++ {
+		head, err := os.ReadFile(headPath(path))
+		if err != nil {
+			t.Fatal(err)
+		}
+		heads = append(heads, head)
+		if err := NewLogger(path).Append(&Entry{Command: fmt.Sprintf("echo %d", i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		head []byte
+		ok   bool
+	}{
+		// 写入第三条记录后、更新链头前中断
+		{heads[1], true},
+		// 链头落后两条说明尾部状态不可信
+		{heads[0], false},
+	}
+	for _, tt := range tests {
+		if err := os.WriteFile(headPath(path), tt.head, 0600); err != nil {
+			t.Fatal(err)
+		}
+		result, err := Verify(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.OK() != tt.ok {
+			t.Errorf("Verify with head %s = problems %v, want ok %v", tt.head, result.Problems, tt.ok)
+		}
+	}
+}
+
+func TestConcurrentAppends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+
+	const workers, perWorker = 8, 10
+	var wg sync.WaitGroup
+	errs := make(chan error, workers*perWorker)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			logger := NewLogger(path)
+			for i := 0; i < perWorker; i++ {
+				if err := logger.Append(&Entry{Command: fmt.Sprintf("echo %d-%d", w, i)}); err != nil {
+					errs <- err
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	result, err := Verify(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.OK() || result.Entries != workers*perWorker {
+		t.Errorf("Verify = %d entries, problems %v, want %d and none", result.Entries, result.Problems, workers*perWorker)
+	}
+}
@@ -2,208 +2,213 @@ package config
 
 import (
 	"errors"
-	"fmt"
-	"os"
-	"path/filepath"
+	"net/url"
 	"strconv"
 	"strings"
 
-	"github.com/joho/godotenv"
+	"github.com/elecmonkey/prompt2cmd/internal/i18n"
+	"github.com/elecmonkey/prompt2cmd/internal/secret"
+	"github.com/elecmonkey/prompt2cmd/internal/xdg"
 )
 
 // Config 存储应用程序配置
 type Config struct {
-	LLMProvider       string // deepseek, moonshot
-	LLMAPIKey         string
-	LLMBaseURL        string
-	LLMModel          string
-	UseLocalModel     bool
-	LocalModelPath    string
-	MaxHistorySize    int
-	DangerousCommands []string
+	LLMProvider    string // deepseek, moonshot
+	LLMAPIKey      string
+	LLMBaseURL     string
+	LLMModel       string
+	UseLocalModel  bool
+	LocalModelPath string
+	MaxHistorySize int
+	HistoryBackend string // sqlite, json
+	// 生成命令时作为上下文的历史记录 token 预算，0 表示不使用历史记录
+	HistoryContextTokens int
+	DangerousCommands    []string
+	// 只读命令是否跳过执行确认
+	AutoConfirmReadOnly bool
+	// 声明式安全策略，未配置策略文件时为 nil
+	SecurityPolicy *SecurityPolicy
+	// 受保护路径，命令写入或删除这些路径时会被阻止或要求严格确认
+	ProtectedPaths      []string
+	BlockProtectedPaths bool // true 表示直接阻止，false 表示要求完整输入路径确认
+	ProtectMountPoints  bool // 是否保护当前挂载的卷
+	// 发送给远程LLM之前是否对密钥等敏感信息脱敏
+	RedactSecrets bool
+	// 模型风险复核模式：off（关闭）、auto（静态规则无法确定时）、always（总是）
+	RiskReviewMode string
+	// 审计执行结果使用的模型，为空时使用 LLMModel
+	AuditModel string
+	// 何时请模型审计执行结果：always、on-failure、on-nonzero-exit、on-empty-output、never
+	AuditPolicy string
+	// 执行命令使用的shell，以 -c 参数传入命令
+	Shell string
+	// 是否记录防篡改的审计日志
+	AuditLogEnabled bool
+	AuditLogFile    string
+	// 团队配方目录，RecipesRepo 不为空时该目录是仓库的本地副本
+	RecipesDir  string
+	RecipesRepo string
+	// 生成命令前是否先匹配团队配方
+	RecipeMatching bool
+	// 交互模式中是否在配置文件或安全策略文件变化后自动重新加载
+	WatchConfig bool
+	// 界面语言，配置为 auto 或未配置时按 LC_ALL、LC_MESSAGES、LANG 检测
+	Language i18n.Language
+	// 获取API密钥的凭据命令，例如 pass show deepseek
+	APIKeyCommand string
+	// API密钥的来源：空表示直接配置，command 表示凭据命令，keyring 表示系统密钥环
+	// 后两种来源的密钥在创建LLM提供商时才通过 ResolveAPIKey 获取
+	APIKeySource string
+	// 读取配置时产生的警告，例如被忽略的项目配置项
+	Warnings []string
 	// 添加一个配置文件路径，以便后续可能的配置保存
 	ConfigFile string
+	// 使用的配置档案，仅 YAML 配置文件有效
+	Profile string
 }
 
-// ConfigManager 接口定义配置管理器的行为
-type ConfigManager interface {
-	LoadConfig() (*Config, error)
-}
+// API密钥的来源
+const (
+	// APIKeyFromCommand 通过凭据命令获取
+	APIKeyFromCommand = "command"
+	// APIKeyFromKeyring 从系统密钥环读取
+	APIKeyFromKeyring = "keyring"
+)
 
-// EnvConfigManager 从环境变量加载配置
-type EnvConfigManager struct {
-	envFile string
+// ProviderPreset 内置LLM提供商的默认设置
+type ProviderPreset struct {
+	Name    string
+	BaseURL string
+	Model   string
+	// 本地运行的模型不需要API密钥，发送前也不脱敏
+	Local bool
 }
 
-// NewEnvConfigManager 创建一个新的环境变量配置管理器
-func NewEnvConfigManager(envFile string) *EnvConfigManager {
-	return &EnvConfigManager{
-		envFile: envFile,
-	}
+// ProviderPresets 支持的LLM提供商
+var ProviderPresets = []ProviderPreset{
+	{Name: "deepseek", BaseURL: "https://api.deepseek.com", Model: "deepseek-chat"},
+	{Name: "moonshot", BaseURL: "https://api.moonshot.cn/v1", Model: "kimi-k2-0711-preview"},
+	{Name: "ollama", BaseURL: "http://localhost:11434/v1", Model: "qwen2.5-coder", Local: true},
 }
 
-// 寻找配置文件的可能位置
-func findConfigFile(envFile string) (string, error) {
-	// 如果提供了明确的配置文件路径，直接使用
-	if envFile != "" {
-		if _, err := os.Stat(envFile); err == nil {
-			fmt.Printf("使用指定的配置文件: %s\n", envFile)
-			return envFile, nil
+// FindProviderPreset 按名称查找LLM提供商的默认设置
+func FindProviderPreset(name string) (ProviderPreset, bool) {
+	for _, preset := range ProviderPresets {
+		if preset.Name == name {
+			return preset, true
 		}
 	}
+	return ProviderPreset{}, false
+}
 
-	// 定义可能的配置文件位置
-	var configPaths []string
+// DefaultShell 默认执行命令使用的shell
+const DefaultShell = "sh"
 
-	// 1. 当前工作目录
-	currentDir, err := os.Getwd()
-	if err == nil {
-		configPaths = append(configPaths, filepath.Join(currentDir, ".env"))
-	}
+// DefaultDangerousCommands 默认的危险命令列表
+var DefaultDangerousCommands = []string{"rm -rf", "rm", "chmod", "chown", "mkfs", "dd", "mv", "reboot", "shutdown"}
 
-	// 2. 用户主目录
-	homeDir, err := os.UserHomeDir()
-	if err == nil {
-		// ~/.prompt2cmd/.env
-		promptConfigDir := filepath.Join(homeDir, ".prompt2cmd")
-		configPaths = append(configPaths, filepath.Join(promptConfigDir, ".env"))
-		// 直接放在~目录下的.prompt2cmd_env（兼容性考虑）
-		configPaths = append(configPaths, filepath.Join(homeDir, ".prompt2cmd_env"))
-	}
+// DefaultProtectedPaths 默认的受保护路径
+// 以 = 开头的条目只保护路径本身，不含 / 的条目匹配任意同名的路径组成部分
+var DefaultProtectedPaths = []string{"=/", "=~", "/etc", "/boot", "/usr", "/bin", "/sbin", "/lib", "/System", ".git"}
 
-	// 3. 系统配置目录
-	configPaths = append(configPaths, "/etc/prompt2cmd/.env")
+// DefaultRecipesDir 返回默认的团队配方目录 $XDG_DATA_HOME/prompt2cmd/recipes
+// 旧版本的 ~/.prompt2cmd/recipes 存在时继续使用
+func DefaultRecipesDir() string {
+	return xdg.DataFile("recipes")
+}
 
-	// 查找第一个存在的配置文件
-	for _, path := range configPaths {
-		if _, err := os.Stat(path); err == nil {
-			fmt.Printf("找到配置文件: %s\n", path)
-			return path, nil
-		}
-	}
+// DefaultAuditLogFile 返回默认的审计日志路径 $XDG_STATE_HOME/prompt2cmd/audit.jsonl
+// 旧版本的 ~/.prompt2cmd/audit.jsonl 存在时继续使用
+func DefaultAuditLogFile() string {
+	return xdg.StateFile("audit.jsonl")
+}
 
-	// 如果未找到任何配置文件，返回空字符串和错误
-	return "", errors.New("未找到配置文件。请在当前目录、用户主目录或系统配置目录创建.env文件")
+// ConfigManager 接口定义配置管理器的行为
+type ConfigManager interface {
+	LoadConfig() (*Config, error)
 }
 
-// 确保配置目录存在
-func ensureConfigDir(dir string) error {
-	if _, err := os.Stat(dir); os.IsNotExist(err) {
-		return os.MkdirAll(dir, 0755)
+// ResolveAPIKey 需要时通过凭据命令或系统密钥环获取API密钥，已经获取过或不需要时直接返回
+// 只有创建LLM提供商等确实要使用密钥的地方才调用，查看配置和历史记录等操作不会运行凭据命令
+func (c *Config) ResolveAPIKey() error {
+	if c.LLMAPIKey != "" || c.APIKeySource == "" {
+		return nil
 	}
+	key, _, err := resolveAPIKey(c.LLMProvider, c.APIKeyCommand)
+	if err != nil {
+		return err
+	}
+	c.LLMAPIKey = key
 	return nil
 }
 
-// LoadConfig 从.env文件加载配置
-func (e *EnvConfigManager) LoadConfig() (*Config, error) {
-	// 创建配置对象
-	config := &Config{}
-
-	// 寻找配置文件
-	configFile, err := findConfigFile(e.envFile)
-	if err == nil {
-		// 加载找到的.env文件
-		err = godotenv.Load(configFile)
+// resolveAPIKey 通过凭据命令或系统密钥环获取API密钥
+// 设置了凭据命令时只使用凭据命令，否则从系统密钥环中读取以提供商名称保存的密钥
+func resolveAPIKey(provider, command string) (string, string, error) {
+	if command != "" {
+		key, err := secret.RunHelper(command)
 		if err != nil {
-			fmt.Printf("警告: 加载配置文件失败: %s\n", err.Error())
-		} else {
-			// 保存找到的配置文件路径
-			config.ConfigFile = configFile
+			return "", "", errors.New(i18n.T("通过凭据命令获取API密钥失败: %s", err.Error()))
 		}
-	} else {
-		fmt.Printf("警告: %s\n", err.Error())
-		fmt.Println("将使用环境变量或默认值...")
+		return key, APIKeyFromCommand, nil
+	}
+
+	keyring := secret.DefaultKeyring()
+	key, err := keyring.Get(provider)
+	if err == nil {
+		return key, APIKeyFromKeyring, nil
+	}
+	message := i18n.T("未找到 %s 的API密钥。请运行 prompt2cmd config set-key 将密钥保存到系统密钥环，或设置凭据命令 llm.api_key_command（例如 pass show %s），也可以设置 LLM_API_KEY 环境变量", provider, provider)
+	if !errors.Is(err, secret.ErrNotFound) {
+		message += i18n.T("（无法读取系统密钥环: %s）", err.Error())
 	}
+	return "", "", errors.New(message)
+}
+
+// buildConfig 根据配置项的值构建配置，getenv 按环境变量名返回配置项的值，未设置时返回空字符串
+func buildConfig(getenv func(key string) string) (*Config, error) {
+	// 创建配置对象
+	config := &Config{}
 
 	// 获取LLM提供商（必需）
-	config.LLMProvider = os.Getenv("LLM_PROVIDER")
+	config.LLMProvider = getenv("LLM_PROVIDER")
 	if config.LLMProvider == "" {
 		config.LLMProvider = "deepseek" // 默认使用deepseek
 	}
+	preset, ok := FindProviderPreset(config.LLMProvider)
+	if !ok {
+		return nil, errors.New(i18n.T("不支持的LLM提供商: %s", config.LLMProvider))
+	}
 
-	// 获取LLM API密钥（必需）
-	config.LLMAPIKey = os.Getenv("LLM_API_KEY")
-	if config.LLMAPIKey == "" {
-		// 尝试创建用户配置目录和示例配置
-		homeDir, _ := os.UserHomeDir()
-		if homeDir != "" {
-			promptConfigDir := filepath.Join(homeDir, ".prompt2cmd")
-			if err := ensureConfigDir(promptConfigDir); err == nil {
-				exampleConfigPath := filepath.Join(promptConfigDir, ".env.example")
-				if _, err := os.Stat(exampleConfigPath); os.IsNotExist(err) {
-					// 创建示例配置文件
-					exampleConfig := `# Prompt2Cmd 配置文件示例
-# 在 https://platform.deepseek.com/api_keys 或 https://platform.moonshot.cn/console/api-keys 获取API密钥
-
-# LLM 提供商 (deepseek, moonshot, 默认为 deepseek)
-LLM_PROVIDER=deepseek
-
-# LLM API 密钥（必需）
-LLM_API_KEY=your_api_key_here
-
-# LLM API 基础URL（可选，有默认值）
-# DeepSeek: https://api.deepseek.com
-# Moonshot: https://api.moonshot.cn/v1
-LLM_BASE_URL=
-
-# LLM 模型名称（可选，有默认值）
-# DeepSeek: deepseek-chat
-# Moonshot: kimi-k2-0711-preview
-LLM_MODEL=
-
-# 历史记录最大保存数量（可选，有默认值）
-MAX_HISTORY_SIZE=50
-
-# 是否使用本地模型（可选，有默认值）
-USE_LOCAL_MODEL=false
-
-# 本地模型路径（仅当USE_LOCAL_MODEL=true时必需）
-LOCAL_MODEL_PATH=
-
-# 危险命令列表（可选，有默认值）
-DANGEROUS_COMMANDS=rm -rf,rm,chmod,chown,mkfs,dd,mv,reboot,shutdown
-`
-					err := os.WriteFile(exampleConfigPath, []byte(exampleConfig), 0644)
-					if err == nil {
-						fmt.Printf("已在 %s 创建示例配置文件\n", exampleConfigPath)
-					}
-				}
-			}
+	// 获取LLM API密钥（远程提供商必需），未直接设置时使用凭据命令或系统密钥环
+	// 运行凭据命令和访问密钥环可能需要用户输入密码，因此只记录来源，使用时再获取
+	config.LLMAPIKey = getenv("LLM_API_KEY")
+	config.APIKeyCommand = getenv("LLM_API_KEY_COMMAND")
+	if config.LLMAPIKey == "" && !preset.Local {
+		config.APIKeySource = APIKeyFromKeyring
+		if config.APIKeyCommand != "" {
+			config.APIKeySource = APIKeyFromCommand
 		}
-
-		return nil, errors.New("未找到LLM_API_KEY环境变量，这是必需的。请设置环境变量或在配置文件中提供")
 	}
 
-	// 获取LLM基础URL
-	config.LLMBaseURL = os.Getenv("LLM_BASE_URL")
+	// 获取LLM基础URL，未设置时使用提供商的默认值
+	config.LLMBaseURL = getenv("LLM_BASE_URL")
 	if config.LLMBaseURL == "" {
-		// 根据提供商设置默认URL
-		switch config.LLMProvider {
-		case "deepseek":
-			config.LLMBaseURL = "https://api.deepseek.com"
-		case "moonshot":
-			config.LLMBaseURL = "https://api.moonshot.cn/v1"
-		default:
-			return nil, errors.New("不支持的LLM提供商: " + config.LLMProvider)
-		}
+		config.LLMBaseURL = preset.BaseURL
+	}
+	if err := validateBaseURL(config.LLMBaseURL); err != nil {
+		return nil, err
 	}
+	config.LLMBaseURL = strings.TrimSuffix(config.LLMBaseURL, "/")
 
-	// 获取LLM模型名称
-	config.LLMModel = os.Getenv("LLM_MODEL")
+	// 获取LLM模型名称，未设置时使用提供商的默认模型
+	config.LLMModel = getenv("LLM_MODEL")
 	if config.LLMModel == "" {
-		// 根据提供商设置默认模型
-		switch config.LLMProvider {
-		case "deepseek":
-			config.LLMModel = "deepseek-chat"
-		case "moonshot":
-			config.LLMModel = "kimi-k2-0711-preview"
-		default:
-			return nil, errors.New("不支持的LLM提供商: " + config.LLMProvider)
-		}
+		config.LLMModel = preset.Model
 	}
 
 	// 获取是否使用本地模型
-	useLocalModelStr := os.Getenv("USE_LOCAL_MODEL")
+	useLocalModelStr := getenv("USE_LOCAL_MODEL")
 	if useLocalModelStr != "" {
 		config.UseLocalModel = strings.ToLower(useLocalModelStr) == "true"
 	} else {
@@ -211,29 +216,53 @@ DANGEROUS_COMMANDS=rm -rf,rm,chmod,chown,mkfs,dd,mv,reboot,shutdown
 	}
 
 	// 获取本地模型路径
-	config.LocalModelPath = os.Getenv("LOCAL_MODEL_PATH")
+	config.LocalModelPath = getenv("LOCAL_MODEL_PATH")
 	// 如果设置了使用本地模型但没有提供路径，返回错误
 	if config.UseLocalModel && config.LocalModelPath == "" {
-		return nil, errors.New("启用了本地模型(USE_LOCAL_MODEL=true)，但未设置LOCAL_MODEL_PATH")
+		return nil, errors.New(i18n.T("启用了本地模型(USE_LOCAL_MODEL=true)，但未设置LOCAL_MODEL_PATH"))
 	}
 
 	// 获取历史记录大小限制
 	config.MaxHistorySize = 50 // 默认值
-	maxHistorySizeStr := os.Getenv("MAX_HISTORY_SIZE")
+	maxHistorySizeStr := getenv("MAX_HISTORY_SIZE")
 	if maxHistorySizeStr != "" {
 		maxHistorySize, err := strconv.Atoi(maxHistorySizeStr)
 		if err != nil {
-			return nil, errors.New("MAX_HISTORY_SIZE必须是一个有效的整数: " + err.Error())
+			return nil, errors.New(i18n.T("MAX_HISTORY_SIZE必须是一个有效的整数: %s", err.Error()))
 		}
 		if maxHistorySize < 1 {
-			return nil, errors.New("MAX_HISTORY_SIZE必须大于0")
+			return nil, errors.New(i18n.T("MAX_HISTORY_SIZE必须大于0"))
 		}
 		config.MaxHistorySize = maxHistorySize
 	}
 
+	// 获取历史记录存储方式
+	config.HistoryBackend = strings.ToLower(getenv("HISTORY_BACKEND"))
+	switch config.HistoryBackend {
+	case "":
+		config.HistoryBackend = "sqlite"
+	case "sqlite", "json":
+	default:
+		return nil, errors.New(i18n.T("HISTORY_BACKEND必须是 sqlite 或 json: %s", config.HistoryBackend))
+	}
+
+	// 获取历史记录上下文的 token 预算
+	config.HistoryContextTokens = 800 // 默认值
+	historyContextTokensStr := getenv("HISTORY_CONTEXT_TOKENS")
+	if historyContextTokensStr != "" {
+		historyContextTokens, err := strconv.Atoi(historyContextTokensStr)
+		if err != nil {
+			return nil, errors.New(i18n.T("HISTORY_CONTEXT_TOKENS必须是一个有效的整数: %s", err.Error()))
+		}
+		if historyContextTokens < 0 {
+			return nil, errors.New(i18n.T("HISTORY_CONTEXT_TOKENS不能为负数"))
+		}
+		config.HistoryContextTokens = historyContextTokens
+	}
+
 	// 获取危险命令列表
-	config.DangerousCommands = []string{"rm -rf", "rm", "chmod", "chown", "mkfs", "dd", "mv", "reboot", "shutdown"} // 默认列表
-	dangerousCommandsStr := os.Getenv("DANGEROUS_COMMANDS")
+	config.DangerousCommands = DefaultDangerousCommands // 默认列表
+	dangerousCommandsStr := getenv("DANGEROUS_COMMANDS")
 	if dangerousCommandsStr != "" {
 		// 分割字符串并清理空格
 		dangerousCommands := strings.Split(dangerousCommandsStr, ",")
@@ -252,5 +281,121 @@ DANGEROUS_COMMANDS=rm -rf,rm,chmod,chown,mkfs,dd,mv,reboot,shutdown
 		}
 	}
 
+	// 获取只读命令是否自动确认
+	autoConfirmStr := getenv("AUTO_CONFIRM_READONLY")
+	config.AutoConfirmReadOnly = strings.ToLower(autoConfirmStr) == "true"
+
+	// 获取受保护路径
+	config.ProtectedPaths = DefaultProtectedPaths
+	if protectedPathsStr := getenv("PROTECTED_PATHS"); protectedPathsStr != "" {
+		config.ProtectedPaths = splitList(protectedPathsStr)
+	}
+
+	switch action := strings.ToLower(getenv("PROTECTED_PATH_ACTION")); action {
+	case "", "deny":
+		config.BlockProtectedPaths = true
+	case "confirm":
+		config.BlockProtectedPaths = false
+	default:
+		return nil, errors.New(i18n.T("PROTECTED_PATH_ACTION必须是 deny 或 confirm: %s", action))
+	}
+
+	config.ProtectMountPoints = strings.ToLower(getenv("PROTECT_MOUNT_POINTS")) != "false"
+
+	// 获取是否脱敏敏感信息
+	config.RedactSecrets = strings.ToLower(getenv("REDACT_SECRETS")) != "false"
+
+	// 获取模型风险复核模式
+	config.RiskReviewMode = strings.ToLower(getenv("LLM_RISK_REVIEW"))
+	switch config.RiskReviewMode {
+	case "":
+		config.RiskReviewMode = "off"
+	case "off", "auto", "always":
+	default:
+		return nil, errors.New(i18n.T("LLM_RISK_REVIEW必须是 off、auto 或 always: %s", config.RiskReviewMode))
+	}
+
+	// 获取执行结果审计的模型和策略
+	config.AuditModel = getenv("LLM_AUDIT_MODEL")
+	config.AuditPolicy = strings.ToLower(getenv("LLM_AUDIT_POLICY"))
+	switch config.AuditPolicy {
+	case "":
+		config.AuditPolicy = "always"
+	case "always", "on-failure", "on-nonzero-exit", "on-empty-output", "never":
+	default:
+		return nil, errors.New(i18n.T("LLM_AUDIT_POLICY必须是 always、on-failure、on-nonzero-exit、on-empty-output 或 never: %s", config.AuditPolicy))
+	}
+
+	// 获取执行命令使用的shell
+	config.Shell = getenv("COMMAND_SHELL")
+	if config.Shell == "" {
+		config.Shell = DefaultShell
+	}
+
+	// 获取审计日志配置
+	config.AuditLogEnabled = strings.ToLower(getenv("AUDIT_LOG_ENABLED")) != "false"
+	config.AuditLogFile = getenv("AUDIT_LOG_FILE")
+	if config.AuditLogFile == "" {
+		config.AuditLogFile = DefaultAuditLogFile()
+	}
+
+	// 获取团队配方配置
+	config.RecipesDir = getenv("RECIPES_DIR")
+	if config.RecipesDir == "" {
+		config.RecipesDir = DefaultRecipesDir()
+	}
+	config.RecipesRepo = getenv("RECIPES_REPO")
+	config.RecipeMatching = strings.ToLower(getenv("RECIPE_MATCHING")) != "false"
+
+	// 获取是否自动重新加载配置
+	config.WatchConfig = strings.ToLower(getenv("CONFIG_WATCH")) == "true"
+
+	// 获取界面语言
+	language, err := i18n.Parse(getenv("UI_LANGUAGE"))
+	if err != nil {
+		return nil, err
+	}
+	config.Language = language
+
+	// 加载安全策略文件
+	policyFile, err := FindSecurityPolicyFile(getenv("SECURITY_POLICY_FILE"))
+	if err != nil {
+		return nil, err
+	}
+	if policyFile != "" {
+		policy, err := LoadSecurityPolicy(policyFile)
+		if err != nil {
+			return nil, err
+		}
+		config.SecurityPolicy = policy
+	}
+
 	return config, nil
 }
+
+// validateBaseURL 检查LLM基础URL是否为完整的 http 或 https 地址
+func validateBaseURL(baseURL string) error {
+	parsed, err := url.Parse(baseURL)
+	if err != nil {
+		return errors.New(i18n.T("LLM_BASE_URL无效: %s", err.Error()))
+	}
+	if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return errors.New(i18n.T("LLM_BASE_URL必须是以 http:// 或 https:// 开头的完整地址: %s", baseURL))
+	}
+	if parsed.RawQuery != "" || parsed.Fragment != "" {
+		return errors.New(i18n.T("LLM_BASE_URL不能包含查询参数: %s", baseURL))
+	}
+	return nil
+}
+
+// splitList 分割逗号分隔的列表，去除空白和空项
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
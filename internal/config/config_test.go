@@ -0,0 +1,38 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestAPIKeyCommandRunsOnlyWhenResolved(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("credential helper test uses sh")
+	}
+	project := isolateConfig(t)
+	marker := filepath.Join(t.TempDir(), "helper-ran")
+	t.Setenv("LLM_API_KEY_COMMAND", "touch "+marker+" && echo sk-from-helper")
+
+	cfg, err := NewConfigManager(project, "", nil).LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Fatal("LoadConfig ran the credential helper")
+	}
+	if cfg.LLMAPIKey != "" || cfg.APIKeySource != APIKeyFromCommand {
+		t.Fatalf("LLMAPIKey = %q, APIKeySource = %q before resolving", cfg.LLMAPIKey, cfg.APIKeySource)
+	}
+
+	if err := cfg.ResolveAPIKey(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.LLMAPIKey != "sk-from-helper" {
+		t.Errorf("LLMAPIKey = %q, want sk-from-helper", cfg.LLMAPIKey)
+	}
+	if _, err := os.Stat(marker); err != nil {
+		t.Error("ResolveAPIKey did not run the credential helper")
+	}
+}
@@ -0,0 +1,298 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+
+	"github.com/elecmonkey/prompt2cmd/internal/i18n"
+	"github.com/elecmonkey/prompt2cmd/internal/xdg"
+)
+
+// ProfileEnv 选择配置档案的环境变量
+const ProfileEnv = "PROMPT2CMD_PROFILE"
+
+// Setting 一个配置项在 YAML 配置文件中的路径及对应的环境变量
+type Setting struct {
+	Path string
+	Env  string
+}
+
+// Settings 所有配置项，YAML 中的列表会以逗号拼接后按环境变量的格式解析
+var Settings = []Setting{
+	{Path: "llm.provider", Env: "LLM_PROVIDER"},
+	{Path: "llm.api_key", Env: "LLM_API_KEY"},
+	{Path: "llm.api_key_command", Env: "LLM_API_KEY_COMMAND"},
+	{Path: "llm.base_url", Env: "LLM_BASE_URL"},
+	{Path: "llm.model", Env: "LLM_MODEL"},
+	{Path: "llm.use_local_model", Env: "USE_LOCAL_MODEL"},
+	{Path: "llm.local_model_path", Env: "LOCAL_MODEL_PATH"},
+	{Path: "llm.risk_review", Env: "LLM_RISK_REVIEW"},
+	{Path: "llm.audit_model", Env: "LLM_AUDIT_MODEL"},
+	{Path: "llm.audit_policy", Env: "LLM_AUDIT_POLICY"},
+	{Path: "shell", Env: "COMMAND_SHELL"},
+	{Path: "history.backend", Env: "HISTORY_BACKEND"},
+	{Path: "history.max_size", Env: "MAX_HISTORY_SIZE"},
+	{Path: "history.context_tokens", Env: "HISTORY_CONTEXT_TOKENS"},
+	{Path: "security.dangerous_commands", Env: "DANGEROUS_COMMANDS"},
+	{Path: "security.auto_confirm_readonly", Env: "AUTO_CONFIRM_READONLY"},
+	{Path: "security.policy_file", Env: "SECURITY_POLICY_FILE"},
+	{Path: "security.protected_paths", Env: "PROTECTED_PATHS"},
+	{Path: "security.protected_path_action", Env: "PROTECTED_PATH_ACTION"},
+	{Path: "security.protect_mount_points", Env: "PROTECT_MOUNT_POINTS"},
+	{Path: "security.redact_secrets", Env: "REDACT_SECRETS"},
+	{Path: "audit.enabled", Env: "AUDIT_LOG_ENABLED"},
+	{Path: "audit.file", Env: "AUDIT_LOG_FILE"},
+	{Path: "recipes.dir", Env: "RECIPES_DIR"},
+	{Path: "recipes.repo", Env: "RECIPES_REPO"},
+	{Path: "recipes.matching", Env: "RECIPE_MATCHING"},
+	{Path: "config.watch", Env: "CONFIG_WATCH"},
+	{Path: "ui.language", Env: "UI_LANGUAGE"},
+}
+
+// pathSettings 值为路径的配置项，配置文件中的相对路径相对于配置文件所在的目录
+var pathSettings = map[string]bool{
+	"LOCAL_MODEL_PATH":     true,
+	"SECURITY_POLICY_FILE": true,
+	"AUDIT_LOG_FILE":       true,
+	"RECIPES_DIR":          true,
+}
+
+// DefaultConfigFile 返回用户的 YAML 配置文件路径 $XDG_CONFIG_HOME/prompt2cmd/config.yaml
+func DefaultConfigFile() string {
+	return filepath.Join(xdg.ConfigDir(), "config.yaml")
+}
+
+// fileConfig 解析后的配置文件，设置以环境变量名为键
+type fileConfig struct {
+	Path     string
+	Profile  string
+	Settings map[string]string
+	Profiles map[string]map[string]string
+}
+
+// readEnvFile 读取 .env 格式的配置文件，只保留已知的配置项
+// 文件中的值不会写入进程的环境变量，因此不会传递给执行的命令
+func readEnvFile(path string) (*fileConfig, error) {
+	values, err := godotenv.Read(path)
+	if err != nil {
+		return nil, errors.New(i18n.T("解析配置文件 %s 失败: %s", path, err.Error()))
+	}
+	file := &fileConfig{
+		Path:     path,
+		Settings: make(map[string]string),
+	}
+	for _, setting := range Settings {
+		if value, ok := values[setting.Env]; ok && value != "" {
+			file.Settings[setting.Env] = value
+		}
+	}
+	return file, nil
+}
+
+// readConfigFile 读取并解析 YAML 配置文件
+func readConfigFile(path string) (*fileConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.New(i18n.T("读取配置文件失败: %s", err.Error()))
+	}
+
+	file := &fileConfig{
+		Path:     path,
+		Settings: make(map[string]string),
+		Profiles: make(map[string]map[string]string),
+	}
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, errors.New(i18n.T("解析配置文件 %s 失败: %s", path, err.Error()))
+	}
+	if len(document.Content) == 0 {
+		return file, nil
+	}
+	root := document.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, errors.New(i18n.T("配置文件 %s 的顶层应为映射", path))
+	}
+
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+		switch key.Value {
+		case "profile":
+			if value.Kind != yaml.ScalarNode {
+				return nil, errors.New(i18n.T("%s:%d: profile 应为配置档案名称", path, key.Line))
+			}
+			file.Profile = value.Value
+		case "profiles":
+			if value.Kind != yaml.MappingNode {
+				return nil, errors.New(i18n.T("%s:%d: profiles 应为映射", path, key.Line))
+			}
+			for j := 0; j+1 < len(value.Content); j += 2 {
+				name, body := value.Content[j], value.Content[j+1]
+				settings := make(map[string]string)
+				if err := flattenSettings(body, "", settings); err != nil {
+					return nil, errors.New(i18n.T("%s: 配置档案 %s: %s", path, name.Value, err.Error()))
+				}
+				resolvePaths(settings, filepath.Dir(path))
+				file.Profiles[name.Value] = settings
+			}
+		default:
+			if err := flattenSettings(value, key.Value, file.Settings); err != nil {
+				return nil, fmt.Errorf("%s: %s", path, err.Error())
+			}
+		}
+	}
+	resolvePaths(file.Settings, filepath.Dir(path))
+	return file, nil
+}
+
+// resolvePaths 将路径配置项中的相对路径转换为相对于 dir 的路径
+func resolvePaths(settings map[string]string, dir string) {
+	for env, value := range settings {
+		if pathSettings[env] && value != "" && !filepath.IsAbs(value) && !strings.HasPrefix(value, "~") {
+			settings[env] = filepath.Join(dir, value)
+		}
+	}
+}
+
+// flattenSettings 将嵌套的配置展开为以环境变量名为键的设置，未知的配置项返回错误
+func flattenSettings(node *yaml.Node, path string, settings map[string]string) error {
+	if node.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			child := node.Content[i].Value
+			if path != "" {
+				child = path + "." + child
+			}
+			if err := flattenSettings(node.Content[i+1], child, settings); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	env := settingEnv(path)
+	if env == "" {
+		return errors.New(i18n.T("第 %d 行: 未知的配置项 %s", node.Line, path))
+	}
+	switch node.Kind {
+	case yaml.ScalarNode:
+		settings[env] = node.Value
+	case yaml.SequenceNode:
+		items := make([]string, 0, len(node.Content))
+		for _, item := range node.Content {
+			if item.Kind != yaml.ScalarNode {
+				return errors.New(i18n.T("第 %d 行: %s 的列表项应为字符串", item.Line, path))
+			}
+			items = append(items, item.Value)
+		}
+		settings[env] = strings.Join(items, ",")
+	default:
+		return errors.New(i18n.T("第 %d 行: %s 的值无效", node.Line, path))
+	}
+	return nil
+}
+
+// settingEnv 返回配置项路径对应的环境变量名，未知的配置项返回空字符串
+func settingEnv(path string) string {
+	for _, setting := range Settings {
+		if setting.Path == path {
+			return setting.Env
+		}
+	}
+	return ""
+}
+
+// SettingValue 返回配置项的生效值，列表以逗号拼接
+func (c *Config) SettingValue(env string) string {
+	switch env {
+	case "LLM_PROVIDER":
+		return c.LLMProvider
+	case "LLM_API_KEY":
+		return c.LLMAPIKey
+	case "LLM_API_KEY_COMMAND":
+		return c.APIKeyCommand
+	case "LLM_BASE_URL":
+		return c.LLMBaseURL
+	case "LLM_MODEL":
+		return c.LLMModel
+	case "USE_LOCAL_MODEL":
+		return strconv.FormatBool(c.UseLocalModel)
+	case "LOCAL_MODEL_PATH":
+		return c.LocalModelPath
+	case "LLM_RISK_REVIEW":
+		return c.RiskReviewMode
+	case "LLM_AUDIT_MODEL":
+		return c.AuditModel
+	case "LLM_AUDIT_POLICY":
+		return c.AuditPolicy
+	case "COMMAND_SHELL":
+		return c.Shell
+	case "HISTORY_BACKEND":
+		return c.HistoryBackend
+	case "MAX_HISTORY_SIZE":
+		return strconv.Itoa(c.MaxHistorySize)
+	case "HISTORY_CONTEXT_TOKENS":
+		return strconv.Itoa(c.HistoryContextTokens)
+	case "DANGEROUS_COMMANDS":
+		return strings.Join(c.DangerousCommands, ",")
+	case "AUTO_CONFIRM_READONLY":
+		return strconv.FormatBool(c.AutoConfirmReadOnly)
+	case "SECURITY_POLICY_FILE":
+		if c.SecurityPolicy != nil {
+			return c.SecurityPolicy.File
+		}
+		return ""
+	case "PROTECTED_PATHS":
+		return strings.Join(c.ProtectedPaths, ",")
+	case "PROTECTED_PATH_ACTION":
+		if c.BlockProtectedPaths {
+			return "deny"
+		}
+		return "confirm"
+	case "PROTECT_MOUNT_POINTS":
+		return strconv.FormatBool(c.ProtectMountPoints)
+	case "REDACT_SECRETS":
+		return strconv.FormatBool(c.RedactSecrets)
+	case "AUDIT_LOG_ENABLED":
+		return strconv.FormatBool(c.AuditLogEnabled)
+	case "AUDIT_LOG_FILE":
+		return c.AuditLogFile
+	case "RECIPES_DIR":
+		return c.RecipesDir
+	case "RECIPES_REPO":
+		return c.RecipesRepo
+	case "RECIPE_MATCHING":
+		return strconv.FormatBool(c.RecipeMatching)
+	case "CONFIG_WATCH":
+		return strconv.FormatBool(c.WatchConfig)
+	case "UI_LANGUAGE":
+		return string(c.Language)
+	default:
+		return ""
+	}
+}
+
+// profileNames 返回配置文件中的配置档案名称
+func profileNames(files []*fileConfig) string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, file := range files {
+		for name := range file.Profiles {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	if len(names) == 0 {
+		return i18n.T("无")
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
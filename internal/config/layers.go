@@ -0,0 +1,337 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/elecmonkey/prompt2cmd/internal/i18n"
+	"github.com/elecmonkey/prompt2cmd/internal/xdg"
+)
+
+// ProjectConfigFile 项目配置文件名，放在项目目录或其上级目录中
+const ProjectConfigFile = ".prompt2cmd.yaml"
+
+// Layer 配置层，按系统、用户、项目、环境变量、命令行选项的顺序合并，后面的层覆盖前面的层
+type Layer int
+
+const (
+	// LayerDefault 未设置时使用的默认值
+	LayerDefault Layer = iota
+	// LayerSystem 系统配置：$XDG_CONFIG_DIRS/prompt2cmd/config.yaml 和 /etc/prompt2cmd
+	LayerSystem
+	// LayerUser 用户配置：$XDG_CONFIG_HOME/prompt2cmd/config.yaml 和旧版本的 ~/.prompt2cmd/.env
+	LayerUser
+	// LayerProject 项目配置：当前目录及其上级目录中的 .prompt2cmd.yaml 和当前目录的 .env
+	LayerProject
+	// LayerEnv 环境变量
+	LayerEnv
+	// LayerFlag 命令行选项
+	LayerFlag
+)
+
+// String 返回配置层的名称
+func (l Layer) String() string {
+	switch l {
+	case LayerSystem:
+		return i18n.T("系统配置")
+	case LayerUser:
+		return i18n.T("用户配置")
+	case LayerProject:
+		return i18n.T("项目配置")
+	case LayerEnv:
+		return i18n.T("环境变量")
+	case LayerFlag:
+		return i18n.T("命令行选项")
+	default:
+		return i18n.T("默认值")
+	}
+}
+
+// Origin 配置项的来源
+type Origin struct {
+	Layer   Layer
+	Source  string // 配置文件路径、环境变量名或命令行选项
+	Profile string // 来自配置档案时为档案名
+}
+
+// String 返回来源的说明
+func (o Origin) String() string {
+	if o.Source == "" {
+		return o.Layer.String()
+	}
+	text := o.Layer.String() + " " + o.Source
+	if o.Profile != "" {
+		text += i18n.T("（配置档案 %s）", o.Profile)
+	}
+	return text
+}
+
+// projectWeakening 会关闭或绕过安全防护的配置项，项目配置中一律不允许设置
+// 包括脱敏、审计日志、受保护路径及其处理方式、危险命令列表、安全策略文件、模型风险复核、
+// 只读命令自动确认以及团队配方的来源。安全策略中的 allow 规则会跳过内置规则，因此项目也不能指定策略文件
+var projectWeakening = []string{
+	"REDACT_SECRETS", "AUDIT_LOG_ENABLED", "AUDIT_LOG_FILE", "PROTECTED_PATHS", "PROTECTED_PATH_ACTION",
+	"PROTECT_MOUNT_POINTS", "DANGEROUS_COMMANDS", "SECURITY_POLICY_FILE", "LLM_RISK_REVIEW",
+	"AUTO_CONFIRM_READONLY", "RECIPES_DIR", "RECIPES_REPO",
+}
+
+// projectUntrusted 项目配置文件 .prompt2cmd.yaml 中不允许设置的配置项
+// 项目目录可能来自他人的仓库，这些配置项会把API密钥发送到其他地址、执行任意命令或削弱安全防护
+var projectUntrusted = append([]string{"LLM_API_KEY", "LLM_BASE_URL", "LLM_API_KEY_COMMAND", "COMMAND_SHELL"}, projectWeakening...)
+
+// legacyProjectUntrusted 当前目录的 .env 中不允许设置的配置项
+// 旧版本一直读取当前目录的 .env，为了兼容仍允许其中的API密钥和地址，但不允许执行命令或削弱安全防护；
+// 地址只有和API密钥写在同一个文件中时才会使用，见 dropForeignBaseURL
+var legacyProjectUntrusted = append([]string{"LLM_API_KEY_COMMAND", "COMMAND_SHELL"}, projectWeakening...)
+
+// Resolved 合并各个配置层之后的配置项
+type Resolved struct {
+	Values  map[string]string // 以环境变量名为键
+	Origins map[string]Origin
+	Profile string
+	Files   []Origin // 参与合并的配置文件，按合并顺序排列
+	// Warnings 合并时忽略项目配置中不允许设置的配置项等警告，由调用方决定如何显示
+	Warnings []string
+}
+
+// set 用 settings 覆盖已有的配置项
+func (r *Resolved) set(settings map[string]string, origin Origin) {
+	for env, value := range settings {
+		r.Values[env] = value
+		r.Origins[env] = origin
+	}
+}
+
+// Origin 返回配置项的来源，未设置的配置项来源为默认值
+func (r *Resolved) Origin(env string) Origin {
+	if origin, ok := r.Origins[env]; ok {
+		return origin
+	}
+	return Origin{Layer: LayerDefault}
+}
+
+// LayeredConfigManager 合并系统、用户、项目配置文件、环境变量和命令行选项得到配置
+// 配置文件可以是 YAML 格式（支持配置档案），也可以是旧版本的 .env 格式
+type LayeredConfigManager struct {
+	workingDir string
+	profile    string
+	flags      map[string]string
+}
+
+// NewConfigManager 创建配置管理器
+// profile 为空时依次使用 PROMPT2CMD_PROFILE 环境变量和配置文件中 profile 指定的配置档案；
+// flags 为命令行选项设置的配置项，以环境变量名为键
+func NewConfigManager(workingDir, profile string, flags map[string]string) *LayeredConfigManager {
+	return &LayeredConfigManager{
+		workingDir: workingDir,
+		profile:    profile,
+		flags:      flags,
+	}
+}
+
+// configCandidate 可能存在的配置文件
+type configCandidate struct {
+	layer Layer
+	path  string
+	env   bool // 是否为 .env 格式
+}
+
+// candidates 按合并顺序返回所有可能的配置文件
+func (m *LayeredConfigManager) candidates() []configCandidate {
+	var candidates []configCandidate
+
+	// 系统配置，$XDG_CONFIG_DIRS 中靠前的目录优先级更高
+	configDirs := xdg.ConfigDirs()
+	for i := len(configDirs) - 1; i >= 0; i-- {
+		candidates = append(candidates, configCandidate{layer: LayerSystem, path: filepath.Join(configDirs[i], "prompt2cmd", "config.yaml")})
+	}
+	candidates = append(candidates,
+		configCandidate{layer: LayerSystem, path: "/etc/prompt2cmd/.env", env: true},
+		configCandidate{layer: LayerSystem, path: "/etc/prompt2cmd/config.yaml"},
+	)
+
+	// 用户配置，旧版本的 .env 文件优先级低于 YAML 配置文件
+	if homeDir, err := os.UserHomeDir(); err == nil {
+		candidates = append(candidates,
+			configCandidate{layer: LayerUser, path: filepath.Join(homeDir, ".prompt2cmd_env"), env: true},
+			configCandidate{layer: LayerUser, path: filepath.Join(xdg.LegacyDir(), ".env"), env: true},
+		)
+	}
+	candidates = append(candidates, configCandidate{layer: LayerUser, path: DefaultConfigFile()})
+
+	// 项目配置，离当前目录越近的文件优先级越高
+	if m.workingDir != "" {
+		candidates = append(candidates, configCandidate{layer: LayerProject, path: filepath.Join(m.workingDir, ".env"), env: true})
+		var projectFiles []string
+		for dir := m.workingDir; ; dir = filepath.Dir(dir) {
+			projectFiles = append(projectFiles, filepath.Join(dir, ProjectConfigFile))
+			if filepath.Dir(dir) == dir {
+				break
+			}
+		}
+		for i := len(projectFiles) - 1; i >= 0; i-- {
+			candidates = append(candidates, configCandidate{layer: LayerProject, path: projectFiles[i]})
+		}
+	}
+	return candidates
+}
+
+// WatchFiles 返回所有可能影响配置的文件，包括尚不存在的文件，用于检测配置的变化
+func (m *LayeredConfigManager) WatchFiles() []string {
+	var paths []string
+	for _, candidate := range m.candidates() {
+		paths = append(paths, candidate.path)
+	}
+	return append(paths, defaultPolicyFile())
+}
+
+// Resolve 读取并合并所有配置层
+func (m *LayeredConfigManager) Resolve() (*Resolved, error) {
+	var files []*fileConfig
+	var layers []Layer
+	var warnings []string
+	seen := make(map[string]bool)
+	for _, candidate := range m.candidates() {
+		if seen[candidate.path] {
+			continue
+		}
+		seen[candidate.path] = true
+		if info, err := os.Stat(candidate.path); err != nil || info.IsDir() {
+			continue
+		}
+
+		var file *fileConfig
+		var err error
+		if candidate.env {
+			file, err = readEnvFile(candidate.path)
+		} else {
+			file, err = readConfigFile(candidate.path)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if candidate.layer == LayerProject {
+			if candidate.env {
+				warnings = append(warnings, dropUntrusted(file, legacyProjectUntrusted)...)
+				warnings = append(warnings, dropForeignBaseURL(file)...)
+			} else {
+				warnings = append(warnings, dropUntrusted(file, projectUntrusted)...)
+			}
+		}
+		files = append(files, file)
+		layers = append(layers, candidate.layer)
+	}
+
+	// 选择配置档案：命令行选项、环境变量、配置文件，后读取的配置文件优先
+	profile := m.profile
+	if profile == "" {
+		profile = os.Getenv(ProfileEnv)
+	}
+	if profile == "" {
+		for _, file := range files {
+			if file.Profile != "" {
+				profile = file.Profile
+			}
+		}
+	}
+
+	resolved := &Resolved{
+		Values:   make(map[string]string),
+		Origins:  make(map[string]Origin),
+		Profile:  profile,
+		Warnings: warnings,
+	}
+	profileFound := profile == ""
+	for i, file := range files {
+		origin := Origin{Layer: layers[i], Source: file.Path}
+		resolved.Files = append(resolved.Files, origin)
+		resolved.set(file.Settings, origin)
+		if settings, ok := file.Profiles[profile]; ok && profile != "" {
+			profileFound = true
+			resolved.set(settings, Origin{Layer: layers[i], Source: file.Path, Profile: profile})
+		}
+	}
+	if !profileFound {
+		return nil, errors.New(i18n.T("配置档案 %s 不存在（可用的配置档案: %s）", profile, profileNames(files)))
+	}
+
+	for _, setting := range Settings {
+		if value := os.Getenv(setting.Env); value != "" {
+			resolved.set(map[string]string{setting.Env: value}, Origin{Layer: LayerEnv, Source: setting.Env})
+		}
+	}
+	for env, value := range m.flags {
+		resolved.set(map[string]string{env: value}, Origin{Layer: LayerFlag})
+	}
+	return resolved, nil
+}
+
+// dropUntrusted 移除项目配置文件中不允许设置的配置项，返回说明被忽略的配置项的警告
+func dropUntrusted(file *fileConfig, untrusted []string) []string {
+	var warnings []string
+	sections := []map[string]string{file.Settings}
+	for _, settings := range file.Profiles {
+		sections = append(sections, settings)
+	}
+	for _, settings := range sections {
+		for _, env := range untrusted {
+			if _, ok := settings[env]; ok {
+				warnings = append(warnings, i18n.T("警告: 已忽略项目配置文件 %s 中的 %s，请在用户配置或环境变量中设置", file.Path, settingPath(env)))
+				delete(settings, env)
+			}
+		}
+	}
+	return warnings
+}
+
+// dropForeignBaseURL 当前目录的 .env 没有同时设置API密钥时移除其中的 LLM_BASE_URL
+// 否则在其他地方配置的API密钥会被发送到该文件指定的地址
+func dropForeignBaseURL(file *fileConfig) []string {
+	if _, ok := file.Settings["LLM_BASE_URL"]; !ok {
+		return nil
+	}
+	if file.Settings["LLM_API_KEY"] != "" {
+		return nil
+	}
+	delete(file.Settings, "LLM_BASE_URL")
+	return []string{i18n.T("警告: 已忽略 %s 中的 LLM_BASE_URL，只有同一文件中也设置了 LLM_API_KEY 时才会使用", file.Path)}
+}
+
+// settingPath 返回环境变量名对应的配置项路径
+func settingPath(env string) string {
+	for _, setting := range Settings {
+		if setting.Env == env {
+			return setting.Path
+		}
+	}
+	return env
+}
+
+// LoadConfig 合并所有配置层并加载配置
+func (m *LayeredConfigManager) LoadConfig() (*Config, error) {
+	resolved, err := m.Resolve()
+	if err != nil {
+		return nil, err
+	}
+	return resolved.Config()
+}
+
+// Config 根据合并后的配置项构建配置
+func (r *Resolved) Config() (*Config, error) {
+	config, err := buildConfig(func(key string) string {
+		return r.Values[key]
+	})
+	if err != nil {
+		if r.Profile != "" {
+			return nil, errors.New(i18n.T("配置档案 %s: %s", r.Profile, err.Error()))
+		}
+		return nil, err
+	}
+	if len(r.Files) > 0 {
+		config.ConfigFile = r.Files[len(r.Files)-1].Source
+	}
+	config.Profile = r.Profile
+	config.Warnings = r.Warnings
+	return config, nil
+}
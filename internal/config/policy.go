@@ -0,0 +1,142 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/elecmonkey/prompt2cmd/internal/i18n"
+	"github.com/elecmonkey/prompt2cmd/internal/xdg"
+)
+
+// PolicyAction 安全策略规则命中后的动作
+type PolicyAction string
+
+const (
+	// PolicyAllow 放行，跳过内置的危险命令检查
+	PolicyAllow PolicyAction = "allow"
+	// PolicyWarn 显示警告后按普通方式确认
+	PolicyWarn PolicyAction = "warn"
+	// PolicyConfirm 要求用户完整输入确认内容
+	PolicyConfirm PolicyAction = "confirm"
+	// PolicyDeny 禁止执行
+	PolicyDeny PolicyAction = "deny"
+)
+
+// PathScope 规则的路径范围
+// 路径支持 ~ 和 $REPO（当前git仓库根目录），相对路径相对于当前工作目录
+type PathScope struct {
+	Inside  []string `yaml:"inside"`  // 所有路径参数都在这些目录内时匹配
+	Outside []string `yaml:"outside"` // 任一路径参数在这些目录外时匹配
+}
+
+// PolicyRule 一条安全策略规则，所有已设置的条件都满足时才算命中
+type PolicyRule struct {
+	Name    string       `yaml:"name"`
+	Program string       `yaml:"program"` // 程序名，支持通配符，例如 mkfs*
+	Args    string       `yaml:"args"`    // 匹配参数（以空格拼接）的正则表达式
+	Command string       `yaml:"command"` // 匹配整条命令的正则表达式
+	Paths   *PathScope   `yaml:"paths"`
+	Action  PolicyAction `yaml:"action"`
+	Message string       `yaml:"message"`
+
+	ArgsRegexp    *regexp.Regexp `yaml:"-"`
+	CommandRegexp *regexp.Regexp `yaml:"-"`
+}
+
+// SecurityPolicy 声明式安全策略，规则按顺序匹配，第一条命中的规则生效
+type SecurityPolicy struct {
+	Version int          `yaml:"version"`
+	Rules   []PolicyRule `yaml:"rules"`
+	// 策略文件路径
+	File string `yaml:"-"`
+}
+
+// defaultPolicyFile 返回默认的策略文件路径 $XDG_CONFIG_HOME/prompt2cmd/policy.yaml
+// 旧版本的 ~/.prompt2cmd/policy.yaml 存在时继续使用
+func defaultPolicyFile() string {
+	return xdg.ConfigFile("policy.yaml")
+}
+
+// FindSecurityPolicyFile 查找安全策略文件
+// 优先使用明确指定的路径，其次是默认的策略文件，都不存在时返回空字符串
+func FindSecurityPolicyFile(policyFile string) (string, error) {
+	if policyFile != "" {
+		if _, err := os.Stat(policyFile); err != nil {
+			return "", errors.New(i18n.T("安全策略文件不存在: %s", policyFile))
+		}
+		return policyFile, nil
+	}
+
+	if path := defaultPolicyFile(); path != "" {
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return "", nil
+}
+
+// LoadSecurityPolicy 从YAML文件加载并校验安全策略
+func LoadSecurityPolicy(path string) (*SecurityPolicy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.New(i18n.T("读取安全策略文件失败: %s", err.Error()))
+	}
+
+	policy := &SecurityPolicy{}
+	if err := yaml.Unmarshal(data, policy); err != nil {
+		return nil, errors.New(i18n.T("解析安全策略文件失败: %s", err.Error()))
+	}
+	policy.File = path
+
+	if err := policy.compile(); err != nil {
+		return nil, errors.New(i18n.T("安全策略文件 %s 无效: %s", path, err.Error()))
+	}
+	return policy, nil
+}
+
+// compile 校验规则并编译正则表达式
+func (p *SecurityPolicy) compile() error {
+	for i := range p.Rules {
+		rule := &p.Rules[i]
+		if rule.Name == "" {
+			rule.Name = fmt.Sprintf("rule-%d", i+1)
+		}
+
+		rule.Action = PolicyAction(strings.ToLower(string(rule.Action)))
+		switch rule.Action {
+		case PolicyAllow, PolicyWarn, PolicyConfirm, PolicyDeny:
+		case "":
+			return errors.New(i18n.T("规则 %s 缺少 action", rule.Name))
+		default:
+			return errors.New(i18n.T("规则 %s 的 action 无效: %s（可选 allow, warn, confirm, deny）", rule.Name, rule.Action))
+		}
+
+		if rule.Program == "" && rule.Args == "" && rule.Command == "" && rule.Paths == nil {
+			return errors.New(i18n.T("规则 %s 没有任何匹配条件", rule.Name))
+		}
+		if rule.Program != "" {
+			if _, err := filepath.Match(rule.Program, ""); err != nil {
+				return errors.New(i18n.T("规则 %s 的 program 通配符无效: %s", rule.Name, err.Error()))
+			}
+		}
+
+		var err error
+		if rule.Args != "" {
+			if rule.ArgsRegexp, err = regexp.Compile(rule.Args); err != nil {
+				return errors.New(i18n.T("规则 %s 的 args 正则无效: %s", rule.Name, err.Error()))
+			}
+		}
+		if rule.Command != "" {
+			if rule.CommandRegexp, err = regexp.Compile(rule.Command); err != nil {
+				return errors.New(i18n.T("规则 %s 的 command 正则无效: %s", rule.Name, err.Error()))
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package filelock
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// Lock 基于文件的进程间互斥锁，用于多个会话同时写入同一个文件
+type Lock struct {
+	path string
+	file *os.File
+}
+
+// New 创建一个文件锁，锁文件不存在时会自动创建
+func New(path string) *Lock {
+	return &Lock{path: path}
+}
+
+// Lock 获取排他锁，阻塞直到成功
+func (l *Lock) Lock() error {
+	if err := os.MkdirAll(filepath.Dir(l.path), 0700); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(l.path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	if err := lockFile(file); err != nil {
+		file.Close()
+		return err
+	}
+	l.file = file
+	return nil
+}
+
+// Unlock 释放锁
+func (l *Lock) Unlock() error {
+	if l.file == nil {
+		return nil
+	}
+	err := unlockFile(l.file)
+	closeErr := l.file.Close()
+	l.file = nil
+	if err != nil {
+		return err
+	}
+	return closeErr
+}
@@ -0,0 +1,15 @@
+//go:build !unix
+
+package filelock
+
+import "os"
+
+// lockFile 在不支持 flock 的系统上不加锁
+func lockFile(file *os.File) error {
+	return nil
+}
+
+// unlockFile 在不支持 flock 的系统上不加锁
+func unlockFile(file *os.File) error {
+	return nil
+}
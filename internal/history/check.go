@@ -0,0 +1,104 @@
+package history
+
+import (
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/elecmonkey/prompt2cmd/internal/i18n"
+	"github.com/elecmonkey/prompt2cmd/internal/xdg"
+)
+
+// FileStatus 历史记录文件的检查结果
+type FileStatus struct {
+	Path    string
+	Exists  bool
+	Records int
+	Version int
+}
+
+// DefaultSQLitePath 返回SQLite历史记录数据库的路径
+func DefaultSQLitePath() string {
+	path, _ := defaultSQLitePath()
+	return path
+}
+
+// DefaultJSONPath 返回JSON历史记录文件的路径，与 NewFileCommandHistory 的查找顺序相同，但不会创建目录
+func DefaultJSONPath() string {
+	path := xdg.DataFile("history.json")
+	if _, err := os.Stat(path); err == nil {
+		return path
+	}
+	if homeDir, err := os.UserHomeDir(); err == nil {
+		legacy := filepath.Join(homeDir, ".prompt2cmd_history")
+		if _, err := os.Stat(legacy); err == nil {
+			return legacy
+		}
+	}
+	return path
+}
+
+// CheckSQLiteHistory 以只读方式检查SQLite历史记录数据库能否打开、结构版本是否受支持、数据是否完整
+// 检查不会创建、升级或修改数据库
+func CheckSQLiteHistory(dbPath string) (*FileStatus, error) {
+	status := &FileStatus{Path: dbPath}
+	if _, err := os.Stat(dbPath); err != nil {
+		if os.IsNotExist(err) {
+			return status, nil
+		}
+		return status, errors.New(i18n.T("无法访问历史记录数据库: %s", err.Error()))
+	}
+	status.Exists = true
+
+	db, err := sql.Open("sqlite", "file:"+dbPath+"?mode=ro&_pragma=busy_timeout(5000)")
+	if err != nil {
+		return status, errors.New(i18n.T("打开历史记录数据库失败: %s", err.Error()))
+	}
+	defer db.Close()
+
+	if err := db.QueryRow("PRAGMA user_version").Scan(&status.Version); err != nil {
+		return status, errors.New(i18n.T("读取历史记录数据库版本失败: %s", err.Error()))
+	}
+	if status.Version > sqliteSchemaVersion {
+		return status, errors.New(i18n.T("历史记录数据库版本 %d 高于当前程序支持的版本 %d，请升级 prompt2cmd", status.Version, sqliteSchemaVersion))
+	}
+	var result string
+	if err := db.QueryRow("PRAGMA quick_check").Scan(&result); err != nil {
+		return status, errors.New(i18n.T("检查历史记录数据库失败: %s", err.Error()))
+	}
+	if result != "ok" {
+		return status, errors.New(i18n.T("历史记录数据库已损坏: %s", result))
+	}
+	if status.Version > 0 {
+		if err := db.QueryRow("SELECT COUNT(*) FROM history").Scan(&status.Records); err != nil {
+			return status, errors.New(i18n.T("读取历史记录失败: %s", err.Error()))
+		}
+	}
+	return status, nil
+}
+
+// CheckJSONHistory 检查JSON历史记录文件能否解析，返回有效记录的数量
+// 与 NewFileCommandHistory 不同，解析失败时不会备份或重置文件
+func CheckJSONHistory(path string) (*FileStatus, error) {
+	status := &FileStatus{Path: path}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return status, nil
+		}
+		return status, errors.New(i18n.T("读取历史记录文件失败: %s", err.Error()))
+	}
+	status.Exists = true
+	if len(data) == 0 {
+		return status, nil
+	}
+
+	records, version, err := decodeHistoryFile(data)
+	if err != nil {
+		return status, errors.New(i18n.T("解析历史记录失败: %s", err.Error()))
+	}
+	status.Version = version
+	status.Records = len(filterValidRecords(records))
+	return status, nil
+}
@@ -0,0 +1,158 @@
+package history
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/elecmonkey/prompt2cmd/internal/rank"
+)
+
+// 上下文选择的默认参数
+const (
+	DefaultContextTokens     = 800 // 默认的上下文 token 预算
+	defaultContextCandidates = 200 // 参与排序的最近记录数
+	recordTokenOverhead      = 24  // 每条记录在消息中的固定开销（路径标签、代码块等）
+)
+
+// 目录相关的加权系数
+const (
+	sameDirBoost    = 1.5 // 与当前目录相同
+	relatedDirBoost = 1.2 // 当前目录的上级或下级目录
+)
+
+// ContextOptions 选择上下文记录的条件
+type ContextOptions struct {
+	Prompt      string // 当前的用户需求
+	Cwd         string // 当前工作目录
+	TokenBudget int    // 上下文的 token 预算，0 表示使用默认值，负数表示不使用历史记录
+	Candidates  int    // 参与排序的最近记录数，0 表示使用默认值
+}
+
+// SelectContext 按与当前需求的相关度选择历史记录作为模型的上下文
+// 相关度使用 BM25 计算，同一目录下执行的记录会被加权；最近的一条（非导入的）记录总是优先保留，
+// 以便支持“再执行一次”“换成另一个目录”这类承接上文的需求。
+// 选中的记录按时间由旧到新返回，总 token 估算不超过预算
+func SelectContext(store CommandHistory, options ContextOptions) ([]HistoryRecord, error) {
+	budget := options.TokenBudget
+	if budget == 0 {
+		budget = DefaultContextTokens
+	}
+	if budget < 0 {
+		return []HistoryRecord{}, nil
+	}
+	candidates := options.Candidates
+	if candidates <= 0 {
+		candidates = defaultContextCandidates
+	}
+
+	records, err := store.GetHistory(candidates)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return []HistoryRecord{}, nil
+	}
+
+	// 为每条记录的需求和命令建立索引
+	docs := make([][]string, len(records))
+	for i, record := range records {
+		docs[i] = rank.Tokenize(record.Prompt + " " + record.Command)
+	}
+	index := rank.NewBM25(docs)
+	query := rank.Tokenize(options.Prompt)
+
+	type scored struct {
+		position int
+		score    float64
+	}
+	var ranked []scored
+	// 最近一条由 prompt2cmd 生成的记录，从 shell 历史导入的记录不参与承接上文
+	last := -1
+	for i := len(records) - 1; i >= 0; i-- {
+		if records[i].Source == "" {
+			last = i
+			break
+		}
+	}
+	for i, record := range records {
+		score := index.Score(query, i)
+		if score == 0 && i != last {
+			continue
+		}
+		score *= directoryBoost(record.Cwd, options.Cwd)
+		// 相关度相同时越新的记录越优先
+		score += float64(i) / float64(len(records)) * 0.01
+		ranked = append(ranked, scored{position: i, score: score})
+	}
+	sort.SliceStable(ranked, func(a, b int) bool {
+		// 最近一条记录排在最前，比较它与自身时必须返回 false
+		if isLastA, isLastB := ranked[a].position == last, ranked[b].position == last; isLastA != isLastB {
+			return isLastA
+		}
+		return ranked[a].score > ranked[b].score
+	})
+
+	// 在预算内依次选取
+	var selected []int
+	used := 0
+	for _, item := range ranked {
+		cost := EstimateRecordTokens(records[item.position])
+		if used+cost > budget {
+			continue
+		}
+		used += cost
+		selected = append(selected, item.position)
+	}
+	sort.Ints(selected)
+
+	result := make([]HistoryRecord, 0, len(selected))
+	for _, position := range selected {
+		result = append(result, records[position])
+	}
+	return result, nil
+}
+
+// directoryBoost 根据记录的执行目录与当前目录的关系返回加权系数
+func directoryBoost(recordCwd, cwd string) float64 {
+	if recordCwd == "" || cwd == "" {
+		return 1
+	}
+	recordCwd = filepath.Clean(recordCwd)
+	cwd = filepath.Clean(cwd)
+	switch {
+	case recordCwd == cwd:
+		return sameDirBoost
+	case isSubPath(recordCwd, cwd) || isSubPath(cwd, recordCwd):
+		return relatedDirBoost
+	default:
+		return 1
+	}
+}
+
+// isSubPath 判断 path 是否位于 dir 之下
+func isSubPath(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// EstimateTokens 粗略估算文本的 token 数
+// ASCII 字符约 4 个一个 token，中文等其他字符约 1 个一个 token
+func EstimateTokens(text string) int {
+	ascii := 0
+	other := 0
+	for _, r := range text {
+		if r < utf8.RuneSelf {
+			ascii++
+		} else {
+			other++
+		}
+	}
+	return (ascii+3)/4 + other
+}
+
+// EstimateRecordTokens 估算一条记录作为上下文时占用的 token 数
+func EstimateRecordTokens(record HistoryRecord) int {
+	return EstimateTokens(record.Prompt) + EstimateTokens(record.Command) + EstimateTokens(record.Cwd) + recordTokenOverhead
+}
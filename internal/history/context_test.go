@@ -0,0 +1,114 @@
+package history
+
+import (
+	"fmt"
+	"testing"
+)
+
+// staticHistory 按时间由旧到新排列的固定记录
+type staticHistory []HistoryRecord
+
+func (h staticHistory) AddCommand(prompt, command string, executed bool) error { return nil }
+
+func (h staticHistory) AddRecord(record HistoryRecord) error { return nil }
+
+func (h staticHistory) GetHistory(limit int) ([]HistoryRecord, error) {
+	if limit > 0 && limit < len(h) {
+		return h[len(h)-limit:], nil
+	}
+	return h, nil
+}
+
+// contextIDs 返回选中记录的ID
+func contextIDs(t *testing.T, store CommandHistory, options ContextOptions) string {
+	t.Helper()
+	records, err := SelectContext(store, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, record := range records {
+		ids = append(ids, record.ID)
+	}
+	return fmt.Sprint(ids)
+}
+
+func TestSelectContextRanking(t *testing.T) {
+	store := staticHistory{
+		{ID: "logs", Prompt: "compress the logs", Command: "tar czf logs.tgz logs", Cwd: "/srv/app"},
+		{ID: "big", Prompt: "find large files", Command: "find . -size +100M", Cwd: "/home/me"},
+		{ID: "other", Prompt: "show disk usage", Command: "df -h", Cwd: "/home/me"},
+		{ID: "last", Prompt: "list files", Command: "ls -la", Cwd: "/tmp"},
+	}
+
+	tests := []struct {
+		name    string
+		options ContextOptions
+		want    string
+	}{
+		// 只保留相关的记录和最近一条记录，按时间由旧到新返回
+		{"relevant", ContextOptions{Prompt: "compress logs again"}, "[logs last]"},
+		{"nothing relevant", ContextOptions{Prompt: "restart nginx"}, "[last]"},
+		{"disabled", ContextOptions{Prompt: "compress logs", TokenBudget: -1}, "[]"},
+		{"candidates", ContextOptions{Prompt: "compress logs", Candidates: 2}, "[last]"},
+		// 预算只够两条记录时，最近一条优先，其次是最相关的记录
+		{"budget", ContextOptions{Prompt: "find large files logs", TokenBudget: 2 * (recordTokenOverhead + 12)}, "[big last]"},
+	}
+	for _, tt := range tests {
+		if got := contextIDs(t, store, tt.options); got != tt.want {
+			t.Errorf("%s: SelectContext = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSelectContextDirectoryBoost(t *testing.T) {
+	store := staticHistory{
+		{ID: "here", Prompt: "build the project", Command: "make build", Cwd: "/home/me/project"},
+		{ID: "elsewhere", Prompt: "build the project", Command: "make build", Cwd: "/srv/other"},
+		{ID: "last", Prompt: "list files", Command: "ls", Cwd: "/tmp"},
+	}
+	// 预算只够最近一条和另一条记录
+	budget := EstimateRecordTokens(store[2]) + EstimateRecordTokens(store[0])
+
+	got := contextIDs(t, store, ContextOptions{Prompt: "build project", Cwd: "/home/me/project", TokenBudget: budget})
+	if got != "[here last]" {
+		t.Errorf("SelectContext in the same directory = %s, want [here last]", got)
+	}
+	got = contextIDs(t, store, ContextOptions{Prompt: "build project", Cwd: "/home/me/project/sub", TokenBudget: budget})
+	if got != "[here last]" {
+		t.Errorf("SelectContext in a subdirectory = %s, want [here last]", got)
+	}
+	// 没有目录加权时相关度相同，较新的记录优先
+	got = contextIDs(t, store, ContextOptions{Prompt: "build project", TokenBudget: budget})
+	if got != "[elsewhere last]" {
+		t.Errorf("SelectContext without a directory = %s, want [elsewhere last]", got)
+	}
+}
+
+func TestSelectContextSkipsImportedLast(t *testing.T) {
+	store := staticHistory{
+		{ID: "generated", Prompt: "list files", Command: "ls"},
+		{ID: "imported", Prompt: "", Command: "vim notes.txt", Source: "shell"},
+	}
+	if got := contextIDs(t, store, ContextOptions{Prompt: "restart nginx"}); got != "[generated]" {
+		t.Errorf("SelectContext = %s, want the last generated record only", got)
+	}
+}
+
+func TestDirectoryBoost(t *testing.T) {
+	tests := []struct {
+		recordCwd, cwd string
+		want           float64
+	}{
+		{"/home/me/project", "/home/me/project/", sameDirBoost},
+		{"/home/me/project/sub", "/home/me/project", relatedDirBoost},
+		{"/home/me", "/home/me/project", relatedDirBoost},
+		{"/home/me/project2", "/home/me/project", 1},
+		{"", "/home/me", 1},
+	}
+	for _, tt := range tests {
+		if got := directoryBoost(tt.recordCwd, tt.cwd); got != tt.want {
+			t.Errorf("directoryBoost(%q, %q) = %v, want %v", tt.recordCwd, tt.cwd, got, tt.want)
+		}
+	}
+}
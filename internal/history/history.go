@@ -1,26 +1,47 @@
 package history
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"time"
+
+	"github.com/elecmonkey/prompt2cmd/internal/filelock"
+	"github.com/elecmonkey/prompt2cmd/internal/i18n"
+	"github.com/elecmonkey/prompt2cmd/internal/xdg"
 )
 
 // HistoryRecord 表示一条命令历史记录
 type HistoryRecord struct {
 	ID        string `json:"id"`
 	Prompt    string `json:"prompt"`
-	Command   string `json:"command"`
-	Executed  bool   `json:"executed"`
+	Command   string `json:"command"`  // 实际执行的命令
+	Executed  bool   `json:"executed"` // 命令是否被执行（是否成功见 ExitCode）
 	Timestamp string `json:"timestamp"`
+	Cwd       string `json:"cwd,omitempty"` // 添加记录时的工作目录
+
+	Shell            string        `json:"shell,omitempty"`
+	GeneratedCommand string        `json:"generated_command,omitempty"` // 模型生成的原始命令
+	Edited           bool          `json:"edited,omitempty"`            // 用户是否编辑过命令
+	ExitCode         *int          `json:"exit_code,omitempty"`         // 未执行或未知时为空
+	DurationMs       int64         `json:"duration_ms,omitempty"`
+	Stdout           string        `json:"stdout,omitempty"` // 截断后的标准输出
+	Stderr           string        `json:"stderr,omitempty"` // 截断后的标准错误
+	RiskLevel        string        `json:"risk_level,omitempty"`
+	RiskReasons      []string      `json:"risk_reasons,omitempty"`
+	Provider         string        `json:"provider,omitempty"`
+	Model            string        `json:"model,omitempty"`
+	Audit            *AuditVerdict `json:"audit,omitempty"`
+	// Source 记录来源，空表示由 prompt2cmd 生成，bash/zsh/fish 表示从对应 shell 的历史导入（没有需求）
+	Source string `json:"source,omitempty"`
 }
 
 // CommandHistory 接口定义了命令历史记录的行为
 type CommandHistory interface {
 	AddCommand(prompt, command string, executed bool) error
+	// AddRecord 添加一条完整的记录，ID、时间和工作目录为空时自动填写
+	AddRecord(record HistoryRecord) error
 	GetHistory(limit int) ([]HistoryRecord, error)
 }
 
@@ -41,12 +62,12 @@ func findHistoryFile(filePath string) (string, error) {
 	// 定义可能的历史文件位置
 	var historyPaths []string
 
-	// 1. 用户主目录
+	// 1. 数据目录 $XDG_DATA_HOME/prompt2cmd/history.json，旧版本的 ~/.prompt2cmd/history.json 存在时继续使用
+	historyPaths = append(historyPaths, xdg.DataFile("history.json"))
+
+	// 2. 用户主目录
 	homeDir, err := os.UserHomeDir()
 	if err == nil {
-		// ~/.prompt2cmd/history.json
-		promptDir := filepath.Join(homeDir, ".prompt2cmd")
-		historyPaths = append(historyPaths, filepath.Join(promptDir, "history.json"))
 		// 直接放在~目录下的.prompt2cmd_history（兼容性考虑）
 		historyPaths = append(historyPaths, filepath.Join(homeDir, ".prompt2cmd_history"))
 	}
@@ -63,7 +84,7 @@ func findHistoryFile(filePath string) (string, error) {
 		firstChoice := historyPaths[0]
 		// 确保目录存在
 		dir := filepath.Dir(firstChoice)
-		if err := os.MkdirAll(dir, 0755); err == nil {
+		if err := os.MkdirAll(dir, 0700); err == nil {
 			return firstChoice, nil
 		}
 	}
@@ -73,12 +94,28 @@ func findHistoryFile(filePath string) (string, error) {
 		return filepath.Join(homeDir, ".prompt2cmd_history"), nil
 	}
 	
-	return "", errors.New("无法确定历史记录文件路径")
+	return "", errors.New(i18n.T("无法确定历史记录文件路径"))
 }
 
 // 用于记录警告信息
 func logWarning(message string) {
-	fmt.Fprintf(os.Stderr, "警告: %s\n", message)
+	fmt.Fprintln(os.Stderr, i18n.T("警告: %s", message))
+}
+
+// isValidRecord 判断记录是否包含必要字段，从 shell 历史导入的记录可以没有需求
+func isValidRecord(record HistoryRecord) bool {
+	return record.ID != "" && record.Command != "" && (record.Prompt != "" || record.Source != "")
+}
+
+// filterValidRecords 过滤缺少必要字段的记录
+func filterValidRecords(records []HistoryRecord) []HistoryRecord {
+	validRecords := make([]HistoryRecord, 0, len(records))
+	for _, record := range records {
+		if isValidRecord(record) {
+			validRecords = append(validRecords, record)
+		}
+	}
+	return validRecords
 }
 
 // NewFileCommandHistory 创建一个新的文件命令历史记录
@@ -90,7 +127,7 @@ func NewFileCommandHistory(filePath string, maxRecords int) (*FileCommandHistory
 	// 查找或创建历史文件
 	resolvedPath, err := findHistoryFile(filePath)
 	if err != nil {
-		logWarning(err.Error() + "，将使用空历史记录")
+		logWarning(i18n.T("%s，将使用空历史记录", err.Error()))
 		// 尝试使用默认路径
 		homeDir, _ := os.UserHomeDir()
 		if homeDir != "" {
@@ -108,101 +145,219 @@ func NewFileCommandHistory(filePath string, maxRecords int) (*FileCommandHistory
 
 	// 尝试从文件加载记录
 	loadSuccess := false
+	migrated := false
 	if _, err := os.Stat(resolvedPath); err == nil {
 		// 文件存在，尝试加载
 		file, err := os.ReadFile(resolvedPath)
 		if err != nil {
-			logWarning("读取历史记录文件失败: " + err.Error() + "，将使用空历史记录")
+			logWarning(i18n.T("读取历史记录文件失败: %s，将使用空历史记录", err.Error()))
 		} else if len(file) > 0 {
-			// 解析JSON
-			err = json.Unmarshal(file, &history.records)
-			if err != nil {
-				logWarning("解析历史记录失败: " + err.Error() + "，将使用空历史记录并备份旧文件")
+			// 解析JSON，旧版本的文件会被迁移
+			records, version, err := decodeHistoryFile(file)
+			switch {
+			case errors.Is(err, errVersionTooNew):
+				return nil, err
+			case err != nil:
+				logWarning(i18n.T("解析历史记录失败: %s，将使用空历史记录并备份旧文件", err.Error()))
 				
 				// 备份损坏的历史文件
-				backupPath := resolvedPath + ".backup." + time.Now().Format("20060102150405")
-				backupErr := os.Rename(resolvedPath, backupPath)
-				if backupErr == nil {
-					logWarning("已将损坏的历史记录文件备份到: " + backupPath)
-				}
+				backupCorruptedFile(resolvedPath)
 				
 				// 重置为空记录
 				history.records = []HistoryRecord{}
-			} else {
+			default:
+				history.records = records
 				loadSuccess = true
+				migrated = version < CurrentFileVersion
 			}
 		}
 	}
 
 	// 如果成功加载，检查记录格式是否正确
 	if loadSuccess {
-		validRecords := make([]HistoryRecord, 0, len(history.records))
-		for _, record := range history.records {
-			if record.ID != "" && record.Prompt != "" && record.Command != "" {
-				validRecords = append(validRecords, record)
-			}
-		}
+		validRecords := filterValidRecords(history.records)
 		
-		// 如果有无效记录，更新并保存
+		// 如果有无效记录或文件是旧版本格式，更新并保存
 		if len(validRecords) != len(history.records) {
-			logWarning(fmt.Sprintf("发现 %d 条无效历史记录，已过滤", len(history.records) - len(validRecords)))
+			logWarning(i18n.T("发现 %d 条无效历史记录，已过滤", len(history.records) - len(validRecords)))
+		}
+		if len(validRecords) != len(history.records) || migrated {
 			history.records = validRecords
 			// 保存清理后的记录
-			history.saveHistory()
+			_ = history.update(func(records []HistoryRecord) []HistoryRecord {
+				return filterValidRecords(records)
+			})
 		}
 	}
 
 	return history, nil
 }
 
-// saveHistory 保存历史记录到文件
-func (h *FileCommandHistory) saveHistory() error {
-	// 确保不超过最大记录数
-	if len(h.records) > h.MaxRecords {
-		h.records = h.records[len(h.records)-h.MaxRecords:]
+// trimRecords 确保不超过最大记录数
+func (h *FileCommandHistory) trimRecords(records []HistoryRecord) []HistoryRecord {
+	if h.MaxRecords > 0 && len(records) > h.MaxRecords {
+		records = records[len(records)-h.MaxRecords:]
+	}
+	return records
+}
+
+// readRecords 从文件读取最新的历史记录，文件不存在或为空时返回空列表
+func (h *FileCommandHistory) readRecords() ([]HistoryRecord, error) {
+	data, err := os.ReadFile(h.filePath)
+	if os.IsNotExist(err) {
+		return []HistoryRecord{}, nil
+	}
+	if err != nil {
+		return nil, errors.New(i18n.T("读取历史记录文件失败: %s", err.Error()))
+	}
+	records, _, err := decodeHistoryFile(data)
+	if errors.Is(err, errVersionTooNew) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, errors.New(i18n.T("解析历史记录失败: %s", err.Error()))
 	}
+	return records, nil
+}
 
+// writeRecords 原子地写入历史记录：先写入同目录的临时文件并同步到磁盘，再重命名覆盖
+// 写入过程中崩溃只会留下临时文件，原文件保持完整
+func (h *FileCommandHistory) writeRecords(records []HistoryRecord) error {
 	// 序列化记录
-	data, err := json.MarshalIndent(h.records, "", "  ")
+	data, err := encodeHistoryFile(records)
 	if err != nil {
-		return errors.New("序列化历史记录失败: " + err.Error())
+		return errors.New(i18n.T("序列化历史记录失败: %s", err.Error()))
 	}
 
 	// 确保目录存在
 	dir := filepath.Dir(h.filePath)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return errors.New("创建历史记录目录失败: " + err.Error())
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return errors.New(i18n.T("创建历史记录目录失败: %s", err.Error()))
+	}
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(h.filePath)+".tmp.*")
+	if err != nil {
+		return errors.New(i18n.T("创建临时历史记录文件失败: %s", err.Error()))
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath) // 重命名成功后临时文件已不存在，删除会被忽略
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return errors.New(i18n.T("写入历史记录文件失败: %s", err.Error()))
+	}
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return errors.New(i18n.T("写入历史记录文件失败: %s", err.Error()))
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return errors.New(i18n.T("写入历史记录文件失败: %s", err.Error()))
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.New(i18n.T("写入历史记录文件失败: %s", err.Error()))
+	}
+	if err := os.Rename(tmpPath, h.filePath); err != nil {
+		return errors.New(i18n.T("写入历史记录文件失败: %s", err.Error()))
+	}
+	return nil
+}
+
+// update 在文件锁内读取文件中的最新记录，应用修改后写回
+// 每次保存都基于磁盘上的最新内容，多个会话同时写入时不会互相覆盖对方的记录
+func (h *FileCommandHistory) update(modify func(records []HistoryRecord) []HistoryRecord) error {
+	// 没有文件路径时只保存在内存中
+	if h.filePath == "" {
+		h.records = h.trimRecords(modify(h.records))
+		return nil
 	}
 
-	// 写入文件
-	err = os.WriteFile(h.filePath, data, 0644)
+	lock := filelock.New(h.filePath + ".lock")
+	if err := lock.Lock(); err != nil {
+		return errors.New(i18n.T("锁定历史记录文件失败: %s", err.Error()))
+	}
+	defer lock.Unlock()
+
+	records, err := h.readRecords()
+	if errors.Is(err, errVersionTooNew) {
+		return err
+	}
 	if err != nil {
-		return errors.New("写入历史记录文件失败: " + err.Error())
+		// 文件已损坏，备份后以内存中的记录为准
+		logWarning(i18n.T("%s，将备份旧文件并以当前会话的记录为准", err.Error()))
+		backupCorruptedFile(h.filePath)
+		records = append([]HistoryRecord{}, h.records...)
 	}
 
+	records = h.trimRecords(modify(records))
+	if err := h.writeRecords(records); err != nil {
+		return err
+	}
+	h.records = records
 	return nil
 }
 
+// reload 从文件重新加载记录，以便看到其他会话新增的记录；失败时保留内存中的记录
+func (h *FileCommandHistory) reload() {
+	if h.filePath == "" {
+		return
+	}
+	if records, err := h.readRecords(); err == nil {
+		h.records = records
+	}
+}
+
+// backupCorruptedFile 备份损坏的历史文件
+func backupCorruptedFile(path string) {
+	backupPath := path + ".backup." + time.Now().Format("20060102150405")
+	if err := os.Rename(path, backupPath); err == nil {
+		logWarning(i18n.T("已将损坏的历史记录文件备份到: %s", backupPath))
+	}
+}
+
 // AddCommand 添加一条命令到历史记录
 func (h *FileCommandHistory) AddCommand(prompt, command string, executed bool) error {
-	// 创建新的历史记录
-	record := HistoryRecord{
-		ID:        fmt.Sprintf("%d", time.Now().UnixNano()),
-		Prompt:    prompt,
-		Command:   command,
-		Executed:  executed,
-		Timestamp: time.Now().Format(time.RFC3339),
-	}
+	return h.AddRecord(HistoryRecord{
+		Prompt:   prompt,
+		Command:  command,
+		Executed: executed,
+	})
+}
+
+// AddRecord 添加一条完整的记录到历史记录
+func (h *FileCommandHistory) AddRecord(record HistoryRecord) error {
+	fillRecordDefaults(&record)
 
-	// 添加到记录数组
-	h.records = append(h.records, record)
+	// 合并文件中的最新记录后追加并保存
+	return h.update(func(records []HistoryRecord) []HistoryRecord {
+		return append(records, record)
+	})
+}
 
-	// 保存到文件
-	return h.saveHistory()
+// fillRecordDefaults 填写记录的ID、时间和工作目录
+func fillRecordDefaults(record *HistoryRecord) {
+	fillImportDefaults(record)
+	if record.Cwd == "" {
+		record.Cwd = currentDir()
+	}
+}
+
+// fillImportDefaults 填写导入记录的ID和时间
+// 导入的记录不是在当前目录执行的，因此不填写工作目录
+func fillImportDefaults(record *HistoryRecord) {
+	now := time.Now()
+	if record.ID == "" {
+		record.ID = fmt.Sprintf("%d", now.UnixNano())
+	}
+	if record.Timestamp == "" {
+		record.Timestamp = now.Format(time.RFC3339)
+	}
 }
 
 // GetHistory 获取最近的命令历史记录
 func (h *FileCommandHistory) GetHistory(limit int) ([]HistoryRecord, error) {
+	h.reload()
+
 	// 即使没有历史记录也返回空数组，而不是错误
 	if len(h.records) == 0 {
 		return []HistoryRecord{}, nil
@@ -0,0 +1,139 @@
+package history
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+// 压力测试的子进程通过这些环境变量接收参数
+const (
+	stressFileEnv   = "PROMPT2CMD_STRESS_HISTORY_FILE"
+	stressWorkerEnv = "PROMPT2CMD_STRESS_WORKER"
+)
+
+const (
+	stressWorkers = 8
+	stressRecords = 25
+)
+
+// TestFileCommandHistoryConcurrentProcesses 多个进程同时追加记录，
+// 检查加锁合并保存后每条记录都在，文件仍然可以解析
+func TestFileCommandHistoryConcurrentProcesses(t *testing.T) {
+	if path := os.Getenv(stressFileEnv); path != "" {
+		appendStressRecords(t, path, os.Getenv(stressWorkerEnv))
+		return
+	}
+	if testing.Short() {
+		t.Skip("skipping multi-process stress test in short mode")
+	}
+
+	path := filepath.Join(t.TempDir(), "history.json")
+	children := make([]*exec.Cmd, stressWorkers)
+	for i := range children {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestFileCommandHistoryConcurrentProcesses$")
+		cmd.Env = append(os.Environ(), stressFileEnv+"="+path, stressWorkerEnv+"="+strconv.Itoa(i))
+		children[i] = cmd
+	}
+	outputs := make([][]byte, len(children))
+	errs := make(chan error, len(children))
+	for i, cmd := range children {
+		go func(i int, cmd *exec.Cmd) {
+			var err error
+			outputs[i], err = cmd.CombinedOutput()
+			if err != nil {
+				err = fmt.Errorf("worker %d: %v\n%s", i, err, outputs[i])
+			}
+			errs <- err
+		}(i, cmd)
+	}
+	for range children {
+		if err := <-errs; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, _, err := decodeHistoryFile(data)
+	if err != nil {
+		t.Fatalf("history file no longer parses: %v", err)
+	}
+
+	seen := make(map[string]int)
+	for _, record := range records {
+		seen[record.Prompt]++
+	}
+	for worker := 0; worker < stressWorkers; worker++ {
+		for n := 0; n < stressRecords; n++ {
+			prompt := stressPrompt(strconv.Itoa(worker), n)
+			if seen[prompt] != 1 {
+				t.Errorf("record %q saved %d times, want 1", prompt, seen[prompt])
+			}
+		}
+	}
+	if len(records) != stressWorkers*stressRecords {
+		t.Errorf("got %d records, want %d", len(records), stressWorkers*stressRecords)
+	}
+}
+
+// appendStressRecords 在子进程中逐条追加记录
+func appendStressRecords(t *testing.T, path string, worker string) {
+	h, err := NewFileCommandHistory(path, stressWorkers*stressRecords)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for n := 0; n < stressRecords; n++ {
+		if err := h.AddCommand(stressPrompt(worker, n), "echo "+worker, true); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func stressPrompt(worker string, n int) string {
+	return fmt.Sprintf("worker %s record %d", worker, n)
+}
+
+// TestHistoryFilesArePrivate 历史记录只允许当前用户读写
+func TestHistoryFilesArePrivate(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	dir := filepath.Join(t.TempDir(), "data")
+
+	h, err := NewFileCommandHistory(filepath.Join(dir, "history.json"), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := h.AddCommand("list files", "ls", true); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := NewSQLiteCommandHistory(filepath.Join(dir, "history.db"), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err := db.AddCommand("list files", "ls", true); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]os.FileMode{
+		dir:                                  0700,
+		filepath.Join(dir, "history.json"):   0600,
+		filepath.Join(dir, "history.db"):     0600,
+		filepath.Join(dir, "history.db-wal"): 0600,
+	}
+	for path, mode := range want {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := info.Mode().Perm(); got != mode {
+			t.Errorf("%s mode = %o, want %o", path, got, mode)
+		}
+	}
+}
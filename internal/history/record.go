@@ -0,0 +1,117 @@
+package history
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/elecmonkey/prompt2cmd/internal/i18n"
+)
+
+// CurrentFileVersion 当前历史记录文件的格式版本
+// 版本 1 是不带版本号的记录数组；版本 2 为 {"version": 2, "records": [...]}，记录包含执行详情
+const CurrentFileVersion = 2
+
+// MaxOutputExcerpt 记录中保存的标准输出/标准错误的最大字节数
+const MaxOutputExcerpt = 4096
+
+// AuditVerdict 模型对执行结果的审计结论
+type AuditVerdict struct {
+	Success     bool   `json:"success"`
+	Description string `json:"description"`
+}
+
+// historyFile 历史记录文件的结构
+type historyFile struct {
+	Version int             `json:"version"`
+	Records []HistoryRecord `json:"records"`
+}
+
+// errVersionTooNew 文件由更新版本的程序写入，用于 errors.Is 判断，显示的消息见 versionTooNewError
+var errVersionTooNew = errors.New("history file version too new")
+
+// versionTooNewError 文件版本高于当前程序支持的版本，消息在显示时按界面语言翻译
+type versionTooNewError struct {
+	version int
+}
+
+func (e *versionTooNewError) Error() string {
+	return i18n.T("历史记录文件版本高于当前程序支持的版本（%d > %d），请升级 prompt2cmd", e.version, CurrentFileVersion)
+}
+
+func (e *versionTooNewError) Is(target error) bool {
+	return target == errVersionTooNew
+}
+
+// decodeHistoryFile 解析历史记录文件，旧版本的记录会被迁移到当前版本
+// 返回文件原本的版本号，调用方可据此决定是否写回新格式
+func decodeHistoryFile(data []byte) ([]HistoryRecord, int, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return []HistoryRecord{}, CurrentFileVersion, nil
+	}
+
+	// 版本 1：直接是记录数组
+	if trimmed[0] == '[' {
+		records := []HistoryRecord{}
+		if err := json.Unmarshal(trimmed, &records); err != nil {
+			return nil, 0, err
+		}
+		for i := range records {
+			migrateV1Record(&records[i])
+		}
+		return records, 1, nil
+	}
+
+	var file historyFile
+	if err := json.Unmarshal(trimmed, &file); err != nil {
+		return nil, 0, err
+	}
+	if file.Version > CurrentFileVersion {
+		return nil, file.Version, &versionTooNewError{version: file.Version}
+	}
+	if file.Records == nil {
+		file.Records = []HistoryRecord{}
+	}
+	return file.Records, file.Version, nil
+}
+
+// encodeHistoryFile 以当前版本的格式序列化记录
+func encodeHistoryFile(records []HistoryRecord) ([]byte, error) {
+	return json.MarshalIndent(historyFile{Version: CurrentFileVersion, Records: records}, "", "  ")
+}
+
+// migrateV1Record 迁移版本 1 的记录
+// 版本 1 中 executed 表示“执行时没有出错”，因此为 true 的记录可以确定退出码为 0；
+// 为 false 的记录无法区分是执行失败还是其他错误，退出码保持未知
+func migrateV1Record(record *HistoryRecord) {
+	if record.Executed && record.ExitCode == nil {
+		exitCode := 0
+		record.ExitCode = &exitCode
+	}
+}
+
+// Excerpt 截取输出片段用于保存，超过 MaxOutputExcerpt 时保留开头和结尾
+func Excerpt(output string) string {
+	if len(output) <= MaxOutputExcerpt {
+		return output
+	}
+	half := MaxOutputExcerpt / 2
+	head := output[:half]
+	for !utf8.ValidString(head) && len(head) > 0 {
+		head = head[:len(head)-1]
+	}
+	tail := output[len(output)-half:]
+	for !utf8.ValidString(tail) && len(tail) > 0 {
+		tail = tail[1:]
+	}
+	omitted := len(output) - len(head) - len(tail)
+	return head + i18n.T("\n... 省略 %d 字节 ...\n", omitted) + strings.TrimLeft(tail, "\n")
+}
+
+// Succeeded 返回命令是否执行成功（已执行且退出码为 0）
+func (r *HistoryRecord) Succeeded() bool {
+	return r.Executed && r.ExitCode != nil && *r.ExitCode == 0
+}
@@ -0,0 +1,202 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/elecmonkey/prompt2cmd/internal/i18n"
+)
+
+// SearchOptions 历史记录的查询条件，零值表示不限制
+type SearchOptions struct {
+	Query   string    // 在提示和命令中搜索的关键词，多个关键词以空格分隔，须全部匹配
+	Since   time.Time // 不早于该时间
+	Until   time.Time // 早于该时间
+	Cwd     string    // 只返回在该目录或其子目录中执行的记录
+	Success *bool     // 按是否执行成功（已执行且退出码为 0）过滤
+	Limit   int       // 每页数量，0 表示不限制
+	Offset  int       // 跳过的记录数
+}
+
+// SearchResult 查询结果
+type SearchResult struct {
+	Records []HistoryRecord `json:"records"` // 当前页的记录，按时间由新到旧排列
+	Total   int             `json:"total"`   // 符合条件的记录总数
+}
+
+// HistoryStore 支持查询和管理的命令历史记录
+type HistoryStore interface {
+	CommandHistory
+	Search(options SearchOptions) (*SearchResult, error)
+	// Get 按ID获取记录，不存在时返回 ErrRecordNotFound
+	Get(id string) (*HistoryRecord, error)
+	// Delete 按ID删除记录，不存在时返回 ErrRecordNotFound
+	Delete(id string) error
+	// Clear 删除全部记录
+	Clear() error
+	// Import 批量添加记录，ID已存在（或在SQLite中已被删除）的记录会被跳过，返回新增的记录数
+	Import(records []HistoryRecord) (int, error)
+}
+
+// ErrRecordNotFound 记录不存在
+var ErrRecordNotFound = i18n.Error("历史记录不存在")
+
+// searchTerms 分割搜索关键词
+func searchTerms(query string) []string {
+	return strings.Fields(query)
+}
+
+// currentDir 返回当前工作目录，获取失败时返回空字符串
+func currentDir() string {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	return cwd
+}
+
+// recordTime 解析记录的时间戳
+func recordTime(record HistoryRecord) time.Time {
+	t, err := time.Parse(time.RFC3339, record.Timestamp)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// matchesOptions 判断记录是否满足查询条件（不含分页）
+func matchesOptions(record HistoryRecord, options SearchOptions) bool {
+	prompt := strings.ToLower(record.Prompt)
+	command := strings.ToLower(record.Command)
+	for _, term := range searchTerms(strings.ToLower(options.Query)) {
+		if !strings.Contains(prompt, term) && !strings.Contains(command, term) {
+			return false
+		}
+	}
+
+	if !options.Since.IsZero() || !options.Until.IsZero() {
+		t := recordTime(record)
+		if !options.Since.IsZero() && t.Before(options.Since) {
+			return false
+		}
+		if !options.Until.IsZero() && !t.Before(options.Until) {
+			return false
+		}
+	}
+
+	if options.Cwd != "" {
+		dir := filepath.Clean(options.Cwd)
+		if record.Cwd != dir && !strings.HasPrefix(record.Cwd, dir+string(filepath.Separator)) {
+			return false
+		}
+	}
+
+	if options.Success != nil && record.Succeeded() != *options.Success {
+		return false
+	}
+
+	return true
+}
+
+// Search 在内存中的记录里查询
+func (h *FileCommandHistory) Search(options SearchOptions) (*SearchResult, error) {
+	h.reload()
+
+	var matched []HistoryRecord
+	for _, record := range h.records {
+		if matchesOptions(record, options) {
+			matched = append(matched, record)
+		}
+	}
+
+	// 记录按添加顺序保存，反转为由新到旧
+	for i, j := 0, len(matched)-1; i < j; i, j = i+1, j-1 {
+		matched[i], matched[j] = matched[j], matched[i]
+	}
+
+	result := &SearchResult{Total: len(matched), Records: []HistoryRecord{}}
+	if options.Offset >= len(matched) {
+		return result, nil
+	}
+	matched = matched[options.Offset:]
+	if options.Limit > 0 && options.Limit < len(matched) {
+		matched = matched[:options.Limit]
+	}
+	result.Records = matched
+	return result, nil
+}
+
+// Get 按ID获取记录
+func (h *FileCommandHistory) Get(id string) (*HistoryRecord, error) {
+	h.reload()
+	for i := range h.records {
+		if h.records[i].ID == id {
+			record := h.records[i]
+			return &record, nil
+		}
+	}
+	return nil, ErrRecordNotFound
+}
+
+// Delete 按ID删除记录
+func (h *FileCommandHistory) Delete(id string) error {
+	found := false
+	err := h.update(func(records []HistoryRecord) []HistoryRecord {
+		kept := make([]HistoryRecord, 0, len(records))
+		for _, record := range records {
+			if record.ID == id {
+				found = true
+				continue
+			}
+			kept = append(kept, record)
+		}
+		return kept
+	})
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrRecordNotFound
+	}
+	return nil
+}
+
+// Clear 删除全部记录
+func (h *FileCommandHistory) Clear() error {
+	return h.update(func([]HistoryRecord) []HistoryRecord {
+		return []HistoryRecord{}
+	})
+}
+
+// Import 批量添加记录，已有的ID跳过
+// 导入的记录可能早于已有记录，合并后按时间重新排序，超出 MaxRecords 时最早的记录会被删除
+func (h *FileCommandHistory) Import(records []HistoryRecord) (int, error) {
+	added := 0
+	err := h.update(func(existing []HistoryRecord) []HistoryRecord {
+		added = 0
+		ids := make(map[string]bool, len(existing))
+		for _, record := range existing {
+			ids[record.ID] = true
+		}
+		for _, record := range records {
+			fillImportDefaults(&record)
+			if !isValidRecord(record) || ids[record.ID] {
+				continue
+			}
+			ids[record.ID] = true
+			existing = append(existing, record)
+			added++
+		}
+		sort.SliceStable(existing, func(i, j int) bool {
+			return recordTime(existing[i]).Before(recordTime(existing[j]))
+		})
+		return existing
+	})
+	if err != nil {
+		return 0, err
+	}
+	return added, nil
+}
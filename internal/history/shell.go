@@ -0,0 +1,336 @@
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/elecmonkey/prompt2cmd/internal/i18n"
+)
+
+// 支持的 shell 历史记录格式
+const (
+	ShellBash = "bash" // 带 #时间戳 行的 bash 历史（HISTTIMEFORMAT）
+	ShellZsh  = "zsh"  // zsh 的 EXTENDED_HISTORY 格式
+	ShellFish = "fish" // fish 的 YAML 风格历史
+)
+
+// ShellFormats 支持的全部格式
+var ShellFormats = []string{ShellBash, ShellZsh, ShellFish}
+
+// ShellHistoryEntry shell 历史记录中的一条命令
+type ShellHistoryEntry struct {
+	Command string
+	Time    time.Time // 历史文件中没有时间时为零值
+}
+
+// zshMeta zsh 历史文件中用于转义特殊字节的前缀
+const zshMeta = 0x83
+
+// ValidShellFormat 判断格式是否受支持
+func ValidShellFormat(format string) bool {
+	for _, f := range ShellFormats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// DetectShellFormat 根据 shell 的路径（如 $SHELL）判断历史记录格式，无法判断时返回空字符串
+func DetectShellFormat(shell string) string {
+	name := filepath.Base(shell)
+	switch {
+	case strings.HasPrefix(name, "zsh"):
+		return ShellZsh
+	case strings.HasPrefix(name, "fish"):
+		return ShellFish
+	case strings.HasPrefix(name, "bash"):
+		return ShellBash
+	default:
+		return ""
+	}
+}
+
+// DefaultShellHistoryFile 返回 shell 默认的历史文件路径
+// bash 和 zsh 优先使用 $HISTFILE，fish 使用 $XDG_DATA_HOME/fish/fish_history
+func DefaultShellHistoryFile(format string) (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", errors.New(i18n.T("无法确定用户主目录: %s", err.Error()))
+	}
+	histfile := os.Getenv("HISTFILE")
+	switch format {
+	case ShellBash:
+		if histfile != "" && !strings.Contains(histfile, "zsh") {
+			return histfile, nil
+		}
+		return filepath.Join(homeDir, ".bash_history"), nil
+	case ShellZsh:
+		if histfile != "" && strings.Contains(histfile, "zsh") {
+			return histfile, nil
+		}
+		return filepath.Join(homeDir, ".zsh_history"), nil
+	case ShellFish:
+		dataHome := os.Getenv("XDG_DATA_HOME")
+		if dataHome == "" {
+			dataHome = filepath.Join(homeDir, ".local", "share")
+		}
+		return filepath.Join(dataHome, "fish", "fish_history"), nil
+	default:
+		return "", errors.New(i18n.T("不支持的 shell 历史格式: %s", format))
+	}
+}
+
+// WriteShellHistory 以 shell 历史文件的格式写出记录
+func WriteShellHistory(w io.Writer, records []HistoryRecord, format string) error {
+	buffered := bufio.NewWriter(w)
+	for _, record := range records {
+		when := recordTime(record)
+		if when.IsZero() {
+			when = time.Now()
+		}
+		var entry string
+		switch format {
+		case ShellBash:
+			entry = fmt.Sprintf("#%d\n%s\n", when.Unix(), record.Command)
+		case ShellZsh:
+			// 多行命令在 zsh 历史中以反斜杠续行
+			command := strings.ReplaceAll(record.Command, "\n", "\\\n")
+			entry = fmt.Sprintf(": %d:%d;%s\n", when.Unix(), record.DurationMs/1000, zshMetafy(command))
+		case ShellFish:
+			entry = fmt.Sprintf("- cmd: %s\n  when: %d\n", fishEscape(record.Command), when.Unix())
+		default:
+			return errors.New(i18n.T("不支持的 shell 历史格式: %s", format))
+		}
+		if _, err := buffered.WriteString(entry); err != nil {
+			return err
+		}
+	}
+	return buffered.Flush()
+}
+
+// ReadShellHistory 解析 shell 历史文件，返回的命令按文件中的顺序排列
+func ReadShellHistory(r io.Reader, format string) ([]ShellHistoryEntry, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	switch format {
+	case ShellBash:
+		return parseBashHistory(string(data)), nil
+	case ShellZsh:
+		return parseZshHistory(zshUnmetafy(data)), nil
+	case ShellFish:
+		return parseFishHistory(string(data)), nil
+	default:
+		return nil, errors.New(i18n.T("不支持的 shell 历史格式: %s", format))
+	}
+}
+
+// parseBashHistory 解析 bash 历史
+// 文件中有 #时间戳 行时，两个时间戳之间的各行属于同一条（多行）命令；否则每行是一条命令
+func parseBashHistory(data string) []ShellHistoryEntry {
+	lines := strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n")
+	timestamped := false
+	for _, line := range lines {
+		if _, ok := bashTimestamp(line); ok {
+			timestamped = true
+			break
+		}
+	}
+
+	var entries []ShellHistoryEntry
+	if !timestamped {
+		for _, line := range lines {
+			if strings.TrimSpace(line) != "" {
+				entries = append(entries, ShellHistoryEntry{Command: line})
+			}
+		}
+		return entries
+	}
+
+	var current *ShellHistoryEntry
+	var body []string
+	flush := func() {
+		command := strings.TrimRight(strings.Join(body, "\n"), "\n")
+		if current != nil && strings.TrimSpace(command) != "" {
+			current.Command = command
+			entries = append(entries, *current)
+		}
+		body = nil
+	}
+	for _, line := range lines {
+		if when, ok := bashTimestamp(line); ok {
+			flush()
+			current = &ShellHistoryEntry{Time: when}
+			continue
+		}
+		if current == nil {
+			// 第一个时间戳之前的命令没有时间
+			if strings.TrimSpace(line) != "" {
+				entries = append(entries, ShellHistoryEntry{Command: line})
+			}
+			continue
+		}
+		body = append(body, line)
+	}
+	flush()
+	return entries
+}
+
+// bashTimestamp 解析 bash 历史中形如 #1700000000 的时间戳行
+func bashTimestamp(line string) (time.Time, bool) {
+	if len(line) < 2 || line[0] != '#' {
+		return time.Time{}, false
+	}
+	seconds, err := strconv.ParseInt(line[1:], 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(seconds, 0), true
+}
+
+// parseZshHistory 解析 zsh 历史，同时支持扩展格式（: 时间:耗时;命令）和普通格式
+func parseZshHistory(data string) []ShellHistoryEntry {
+	var entries []ShellHistoryEntry
+	lines := strings.Split(data, "\n")
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		// 以反斜杠结尾的行与下一行是同一条命令
+		for strings.HasSuffix(line, "\\") && i+1 < len(lines) {
+			i++
+			line = strings.TrimSuffix(line, "\\") + "\n" + lines[i]
+		}
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		entry := ShellHistoryEntry{Command: line}
+		if strings.HasPrefix(line, ": ") {
+			if meta, command, ok := strings.Cut(line[2:], ";"); ok {
+				seconds, _, _ := strings.Cut(meta, ":")
+				if n, err := strconv.ParseInt(seconds, 10, 64); err == nil {
+					entry = ShellHistoryEntry{Command: command, Time: time.Unix(n, 0)}
+				}
+			}
+		}
+		if strings.TrimSpace(entry.Command) != "" {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// parseFishHistory 解析 fish 历史
+func parseFishHistory(data string) []ShellHistoryEntry {
+	var entries []ShellHistoryEntry
+	var current *ShellHistoryEntry
+	flush := func() {
+		if current != nil && strings.TrimSpace(current.Command) != "" {
+			entries = append(entries, *current)
+		}
+		current = nil
+	}
+	for _, line := range strings.Split(data, "\n") {
+		switch {
+		case strings.HasPrefix(line, "- cmd: "):
+			flush()
+			current = &ShellHistoryEntry{Command: fishUnescape(strings.TrimPrefix(line, "- cmd: "))}
+		case strings.HasPrefix(line, "  when: ") && current != nil:
+			if n, err := strconv.ParseInt(strings.TrimSpace(strings.TrimPrefix(line, "  when: ")), 10, 64); err == nil {
+				current.Time = time.Unix(n, 0)
+			}
+		}
+	}
+	flush()
+	return entries
+}
+
+// fishEscape 按 fish 历史文件的规则转义反斜杠和换行
+func fishEscape(command string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(command)
+}
+
+// fishUnescape 还原 fish 历史中转义的反斜杠和换行
+func fishUnescape(command string) string {
+	var builder strings.Builder
+	for i := 0; i < len(command); i++ {
+		if command[i] == '\\' && i+1 < len(command) {
+			switch command[i+1] {
+			case '\\':
+				builder.WriteByte('\\')
+				i++
+				continue
+			case 'n':
+				builder.WriteByte('\n')
+				i++
+				continue
+			}
+		}
+		builder.WriteByte(command[i])
+	}
+	return builder.String()
+}
+
+// zshMetafy 按 zsh 的规则转义历史文件中的特殊字节（0x00 和 0x83-0xa2）
+// 这些字节在 UTF-8 的中文等字符中很常见，不转义时 zsh 读出的命令会乱码
+func zshMetafy(command string) string {
+	var buffer bytes.Buffer
+	for i := 0; i < len(command); i++ {
+		c := command[i]
+		if c == 0 || (c >= zshMeta && c <= 0xa2) {
+			buffer.WriteByte(zshMeta)
+			buffer.WriteByte(c ^ 32)
+			continue
+		}
+		buffer.WriteByte(c)
+	}
+	return buffer.String()
+}
+
+// zshUnmetafy 还原 zsh 历史文件中转义的字节
+func zshUnmetafy(data []byte) string {
+	result := make([]byte, 0, len(data))
+	for i := 0; i < len(data); i++ {
+		if data[i] == zshMeta && i+1 < len(data) {
+			i++
+			result = append(result, data[i]^32)
+			continue
+		}
+		result = append(result, data[i])
+	}
+	return string(result)
+}
+
+// ShellRecordID 返回从 shell 历史导入的命令的记录ID
+// ID 由格式和命令内容决定，重复导入同一条命令不会产生重复记录
+func ShellRecordID(format, command string) string {
+	sum := sha256.Sum256([]byte(format + "\x00" + command))
+	return "shell-" + hex.EncodeToString(sum[:8])
+}
+
+// NewShellRecord 根据 shell 历史中的命令创建历史记录
+// 导入的记录没有对应的需求和工作目录，退出码未知；命令没有时间时记录时间为空，由 Import 填写
+func NewShellRecord(format string, entry ShellHistoryEntry) HistoryRecord {
+	record := HistoryRecord{
+		ID:       ShellRecordID(format, entry.Command),
+		Command:  entry.Command,
+		Executed: true,
+		Shell:    format,
+		Source:   format,
+	}
+	if !entry.Time.IsZero() {
+		record.Timestamp = entry.Time.Format(time.RFC3339)
+	}
+	return record
+}
@@ -0,0 +1,581 @@
+package history
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	_ "modernc.org/sqlite" // 纯Go实现的SQLite驱动，无需CGO
+
+	"github.com/elecmonkey/prompt2cmd/internal/i18n"
+	"github.com/elecmonkey/prompt2cmd/internal/xdg"
+)
+
+// sqliteMigrations 各版本的数据库结构变更，下标为版本号，当前版本保存在 PRAGMA user_version 中
+// 版本 1 的 history_fts 使用 trigram 分词，可以对中文等不以空格分词的文本做子串搜索；
+// 版本 2 增加执行详情，旧记录中 executed 为 1 表示执行时没有出错，迁移为退出码 0；
+// 版本 3 记录已删除的ID，避免再次导入JSON文件时删除的记录重新出现；
+// 版本 4 增加记录来源，区分从 shell 历史导入的记录
+var sqliteMigrations = []string{
+	1: sqliteSchemaV1,
+	2: `
+ALTER TABLE history ADD COLUMN shell TEXT NOT NULL DEFAULT '';
+ALTER TABLE history ADD COLUMN generated_command TEXT NOT NULL DEFAULT '';
+ALTER TABLE history ADD COLUMN edited INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE history ADD COLUMN exit_code INTEGER;
+ALTER TABLE history ADD COLUMN duration_ms INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE history ADD COLUMN stdout TEXT NOT NULL DEFAULT '';
+ALTER TABLE history ADD COLUMN stderr TEXT NOT NULL DEFAULT '';
+ALTER TABLE history ADD COLUMN risk_level TEXT NOT NULL DEFAULT '';
+ALTER TABLE history ADD COLUMN risk_reasons TEXT NOT NULL DEFAULT '';
+ALTER TABLE history ADD COLUMN provider TEXT NOT NULL DEFAULT '';
+ALTER TABLE history ADD COLUMN model TEXT NOT NULL DEFAULT '';
+ALTER TABLE history ADD COLUMN audit_success INTEGER;
+ALTER TABLE history ADD COLUMN audit_description TEXT NOT NULL DEFAULT '';
+UPDATE history SET exit_code = 0 WHERE executed = 1;
+`,
+	3: `
+CREATE TABLE IF NOT EXISTS deleted (
+	record_id TEXT PRIMARY KEY
+);
+`,
+	4: `
+ALTER TABLE history ADD COLUMN source TEXT NOT NULL DEFAULT '';
+`,
+}
+
+// sqliteSchemaVersion 当前数据库结构版本
+var sqliteSchemaVersion = len(sqliteMigrations) - 1
+
+// sqliteSchemaV1 版本 1 的数据库结构
+const sqliteSchemaV1 = `
+CREATE TABLE IF NOT EXISTS history (
+	id         INTEGER PRIMARY KEY AUTOINCREMENT,
+	record_id  TEXT    NOT NULL UNIQUE,
+	prompt     TEXT    NOT NULL,
+	command    TEXT    NOT NULL,
+	executed   INTEGER NOT NULL,
+	timestamp  TEXT    NOT NULL,
+	created_at INTEGER NOT NULL,
+	cwd        TEXT    NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS history_created_at ON history(created_at);
+CREATE INDEX IF NOT EXISTS history_cwd ON history(cwd);
+
+CREATE VIRTUAL TABLE IF NOT EXISTS history_fts USING fts5(
+	prompt, command, content='history', content_rowid='id', tokenize='trigram'
+);
+CREATE TRIGGER IF NOT EXISTS history_ai AFTER INSERT ON history BEGIN
+	INSERT INTO history_fts(rowid, prompt, command) VALUES (new.id, new.prompt, new.command);
+END;
+CREATE TRIGGER IF NOT EXISTS history_ad AFTER DELETE ON history BEGIN
+	INSERT INTO history_fts(history_fts, rowid, prompt, command) VALUES ('delete', old.id, old.prompt, old.command);
+END;
+CREATE TRIGGER IF NOT EXISTS history_au AFTER UPDATE ON history BEGIN
+	INSERT INTO history_fts(history_fts, rowid, prompt, command) VALUES ('delete', old.id, old.prompt, old.command);
+	INSERT INTO history_fts(rowid, prompt, command) VALUES (new.id, new.prompt, new.command);
+END;
+
+CREATE TABLE IF NOT EXISTS meta (
+	key   TEXT PRIMARY KEY,
+	value TEXT NOT NULL
+);
+`
+
+// SQLiteCommandHistory 使用SQLite单文件数据库存储命令历史记录
+// 与 FileCommandHistory 不同，写入时不需要重写整个文件，并支持全文搜索和分页查询
+type SQLiteCommandHistory struct {
+	db         *sql.DB
+	dbPath     string
+	maxRecords int // 最多保存的记录数，0 表示不限制
+}
+
+// defaultSQLitePath 返回默认的数据库路径 $XDG_DATA_HOME/prompt2cmd/history.db
+// 旧版本的 ~/.prompt2cmd/history.db 存在时继续使用
+func defaultSQLitePath() (string, error) {
+	return xdg.DataFile("history.db"), nil
+}
+
+// NewSQLiteCommandHistory 打开或创建SQLite历史记录数据库
+// dbPath 为空时使用数据目录中的 history.db；打开时会导入已有的 history.json
+// maxRecords 大于 0 时，写入后只保留最新的 maxRecords 条记录
+func NewSQLiteCommandHistory(dbPath string, maxRecords int) (*SQLiteCommandHistory, error) {
+	if dbPath == "" {
+		var err error
+		dbPath, err = defaultSQLitePath()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(dbPath), 0700); err != nil {
+		return nil, errors.New(i18n.T("创建历史记录目录失败: %s", err.Error()))
+	}
+
+	db, err := sql.Open("sqlite", sqliteDSN(dbPath))
+	if err != nil {
+		return nil, errors.New(i18n.T("打开历史记录数据库失败: %s", err.Error()))
+	}
+
+	history := &SQLiteCommandHistory{db: db, dbPath: dbPath, maxRecords: maxRecords}
+	if err := history.migrate(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	restrictPermissions(dbPath)
+
+	// 导入旧的JSON历史记录
+	jsonPath, err := findHistoryFile("")
+	if err == nil {
+		if err := history.importJSON(jsonPath); err != nil {
+			logWarning(i18n.T("导入JSON历史记录失败: %s", err.Error()))
+		}
+	}
+
+	return history, nil
+}
+
+// sqliteDSN 返回数据库的连接串，路径中的 ?、# 等字符会被转义
+func sqliteDSN(dbPath string) string {
+	dsn := url.URL{
+		Scheme:   "file",
+		OmitHost: true,
+		Path:     filepath.ToSlash(dbPath),
+		RawQuery: "_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_txlock=immediate",
+	}
+	return dsn.String()
+}
+
+// restrictPermissions 将数据库及其 WAL 和共享内存文件设为只有当前用户可读写
+// 历史记录中可能包含命令输出和路径，不应被其他用户读取
+func restrictPermissions(dbPath string) {
+	for _, path := range []string{dbPath, dbPath + "-wal", dbPath + "-shm"} {
+		if err := os.Chmod(path, 0600); err != nil && !os.IsNotExist(err) {
+			logWarning(i18n.T("设置历史记录数据库权限失败: %s", err.Error()))
+		}
+	}
+}
+
+// Close 关闭数据库
+func (h *SQLiteCommandHistory) Close() error {
+	return h.db.Close()
+}
+
+// migrate 创建或升级数据库结构
+func (h *SQLiteCommandHistory) migrate() error {
+	var version int
+	if err := h.db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return errors.New(i18n.T("读取历史记录数据库版本失败: %s", err.Error()))
+	}
+	if version > sqliteSchemaVersion {
+		return errors.New(i18n.T("历史记录数据库版本 %d 高于当前程序支持的版本 %d，请升级 prompt2cmd", version, sqliteSchemaVersion))
+	}
+	for next := version + 1; next <= sqliteSchemaVersion; next++ {
+		if err := h.applyMigration(next); err != nil {
+			return errors.New(i18n.T("升级历史记录数据库到版本 %d 失败: %s", next, err.Error()))
+		}
+	}
+	return nil
+}
+
+// applyMigration 在事务中执行一个版本的结构变更
+// 事务以 IMMEDIATE 方式开始，多个会话同时升级时只有一个会执行，其余的发现版本已更新后跳过
+func (h *SQLiteCommandHistory) applyMigration(version int) error {
+	tx, err := h.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var current int
+	if err := tx.QueryRow("PRAGMA user_version").Scan(&current); err != nil {
+		return err
+	}
+	if current >= version {
+		return nil
+	}
+
+	if _, err := tx.Exec(sqliteMigrations[version]); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", version)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// importJSON 导入 FileCommandHistory 的JSON文件
+// 文件变化后会再次导入，因此回退到JSON存储期间新增的记录也会合并进来；已有的记录按ID跳过
+func (h *SQLiteCommandHistory) importJSON(jsonPath string) error {
+	info, err := os.Stat(jsonPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	metaKey := "imported:" + jsonPath
+	fingerprint := fmt.Sprintf("%d:%d", info.ModTime().UnixNano(), info.Size())
+	var imported string
+	err = h.db.QueryRow("SELECT value FROM meta WHERE key = ?", metaKey).Scan(&imported)
+	if err == nil && imported == fingerprint {
+		return nil
+	}
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	data, err := os.ReadFile(jsonPath)
+	if err != nil {
+		return err
+	}
+	records, _, err := decodeHistoryFile(data)
+	if err != nil {
+		return errors.New(i18n.T("解析历史记录失败: %s", err.Error()))
+	}
+
+	tx, err := h.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	added, err := importRecords(tx, records)
+	if err != nil {
+		return err
+	}
+	if err := trimRecords(tx, h.maxRecords); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("INSERT OR REPLACE INTO meta(key, value) VALUES (?, ?)", metaKey, fingerprint); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	if added > 0 {
+		logWarning(i18n.T("已将 %s 中的 %d 条历史记录导入 %s，原文件保留不变", jsonPath, added, h.dbPath))
+	}
+	return nil
+}
+
+// importRecords 在事务中插入记录，跳过无效、已存在和已删除的记录，返回新增的记录数
+func importRecords(tx *sql.Tx, records []HistoryRecord) (int, error) {
+	added := 0
+	for _, record := range records {
+		if !isValidRecord(record) {
+			continue
+		}
+		var deleted int
+		if err := tx.QueryRow("SELECT COUNT(*) FROM deleted WHERE record_id = ?", record.ID).Scan(&deleted); err != nil {
+			return 0, err
+		}
+		if deleted > 0 {
+			continue
+		}
+		result, err := insertRecord(tx, record, "INSERT OR IGNORE")
+		if err != nil {
+			return 0, err
+		}
+		if n, err := result.RowsAffected(); err == nil {
+			added += int(n)
+		}
+	}
+	return added, nil
+}
+
+// trimRecords 删除超出 maxRecords 的最早记录，并记录其ID，避免再次导入JSON文件时重新出现
+func trimRecords(tx *sql.Tx, maxRecords int) error {
+	if maxRecords <= 0 {
+		return nil
+	}
+	const overflow = "SELECT record_id FROM history ORDER BY created_at DESC, id DESC LIMIT -1 OFFSET ?"
+	if _, err := tx.Exec("INSERT OR IGNORE INTO deleted(record_id) "+overflow, maxRecords); err != nil {
+		return err
+	}
+	_, err := tx.Exec("DELETE FROM history WHERE record_id IN ("+overflow+")", maxRecords)
+	return err
+}
+
+// execer 可以执行SQL语句的对象（*sql.DB 或 *sql.Tx）
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+// recordColumns 查询和插入记录时使用的列，顺序与 recordValues、scanRecord 一致
+const recordColumns = `record_id, prompt, command, executed, timestamp, cwd, shell, generated_command, edited,
+	exit_code, duration_ms, stdout, stderr, risk_level, risk_reasons, provider, model, audit_success, audit_description, source`
+
+// recordValues 返回记录各列的值
+func recordValues(record HistoryRecord) []any {
+	riskReasons := ""
+	if len(record.RiskReasons) > 0 {
+		data, _ := json.Marshal(record.RiskReasons)
+		riskReasons = string(data)
+	}
+	var auditSuccess *bool
+	auditDescription := ""
+	if record.Audit != nil {
+		auditSuccess = &record.Audit.Success
+		auditDescription = record.Audit.Description
+	}
+	return []any{
+		record.ID, record.Prompt, record.Command, record.Executed, record.Timestamp, record.Cwd,
+		record.Shell, record.GeneratedCommand, record.Edited, record.ExitCode, record.DurationMs,
+		record.Stdout, record.Stderr, record.RiskLevel, riskReasons, record.Provider, record.Model,
+		auditSuccess, auditDescription, record.Source,
+	}
+}
+
+// rowScanner 可以读取一行结果的对象（*sql.Row 或 *sql.Rows）
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanRecord 读取一行记录
+func scanRecord(row rowScanner) (HistoryRecord, error) {
+	var record HistoryRecord
+	var exitCode sql.NullInt64
+	var auditSuccess sql.NullBool
+	var riskReasons, auditDescription string
+	err := row.Scan(&record.ID, &record.Prompt, &record.Command, &record.Executed, &record.Timestamp, &record.Cwd,
+		&record.Shell, &record.GeneratedCommand, &record.Edited, &exitCode, &record.DurationMs,
+		&record.Stdout, &record.Stderr, &record.RiskLevel, &riskReasons, &record.Provider, &record.Model,
+		&auditSuccess, &auditDescription, &record.Source)
+	if err != nil {
+		return record, err
+	}
+	if exitCode.Valid {
+		code := int(exitCode.Int64)
+		record.ExitCode = &code
+	}
+	if riskReasons != "" {
+		_ = json.Unmarshal([]byte(riskReasons), &record.RiskReasons)
+	}
+	if auditSuccess.Valid {
+		record.Audit = &AuditVerdict{Success: auditSuccess.Bool, Description: auditDescription}
+	}
+	return record, nil
+}
+
+// insertRecord 插入一条记录，verb 为 INSERT 或 INSERT OR IGNORE
+func insertRecord(db execer, record HistoryRecord, verb string) (sql.Result, error) {
+	var createdAt int64
+	if t := recordTime(record); !t.IsZero() {
+		createdAt = t.Unix()
+	}
+	values := append(recordValues(record), createdAt)
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(values)), ", ")
+	return db.Exec(verb+" INTO history("+recordColumns+", created_at) VALUES ("+placeholders+")", values...)
+}
+
+// AddCommand 添加一条命令到历史记录
+func (h *SQLiteCommandHistory) AddCommand(prompt, command string, executed bool) error {
+	return h.AddRecord(HistoryRecord{
+		Prompt:   prompt,
+		Command:  command,
+		Executed: executed,
+	})
+}
+
+// AddRecord 添加一条完整的记录到历史记录，超出数量上限时删除最早的记录
+func (h *SQLiteCommandHistory) AddRecord(record HistoryRecord) error {
+	fillRecordDefaults(&record)
+
+	tx, err := h.db.Begin()
+	if err != nil {
+		return errors.New(i18n.T("写入历史记录失败: %s", err.Error()))
+	}
+	defer tx.Rollback()
+
+	if _, err := insertRecord(tx, record, "INSERT"); err != nil {
+		return errors.New(i18n.T("写入历史记录失败: %s", err.Error()))
+	}
+	if err := trimRecords(tx, h.maxRecords); err != nil {
+		return errors.New(i18n.T("写入历史记录失败: %s", err.Error()))
+	}
+	if err := tx.Commit(); err != nil {
+		return errors.New(i18n.T("写入历史记录失败: %s", err.Error()))
+	}
+	return nil
+}
+
+// Import 批量添加记录，已存在或已删除的ID跳过
+func (h *SQLiteCommandHistory) Import(records []HistoryRecord) (int, error) {
+	tx, err := h.db.Begin()
+	if err != nil {
+		return 0, errors.New(i18n.T("导入历史记录失败: %s", err.Error()))
+	}
+	defer tx.Rollback()
+
+	filled := make([]HistoryRecord, len(records))
+	for i, record := range records {
+		fillImportDefaults(&record)
+		filled[i] = record
+	}
+	added, err := importRecords(tx, filled)
+	if err != nil {
+		return 0, errors.New(i18n.T("导入历史记录失败: %s", err.Error()))
+	}
+	if err := trimRecords(tx, h.maxRecords); err != nil {
+		return 0, errors.New(i18n.T("导入历史记录失败: %s", err.Error()))
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, errors.New(i18n.T("导入历史记录失败: %s", err.Error()))
+	}
+	return added, nil
+}
+
+// GetHistory 获取最近的命令历史记录，按时间由旧到新排列
+func (h *SQLiteCommandHistory) GetHistory(limit int) ([]HistoryRecord, error) {
+	result, err := h.Search(SearchOptions{Limit: limit})
+	if err != nil {
+		return nil, err
+	}
+	records := result.Records
+	for i, j := 0, len(records)-1; i < j; i, j = i+1, j-1 {
+		records[i], records[j] = records[j], records[i]
+	}
+	return records, nil
+}
+
+// Search 按条件查询历史记录
+// 三个字符及以上的关键词使用全文索引，更短的关键词（如两个汉字）退化为 LIKE 匹配
+func (h *SQLiteCommandHistory) Search(options SearchOptions) (*SearchResult, error) {
+	var conditions []string
+	var args []any
+
+	for _, term := range searchTerms(options.Query) {
+		if utf8.RuneCountInString(term) >= 3 {
+			conditions = append(conditions, "id IN (SELECT rowid FROM history_fts WHERE history_fts MATCH ?)")
+			args = append(args, `"`+strings.ReplaceAll(term, `"`, `""`)+`"`)
+		} else {
+			pattern := "%" + escapeLike(term) + "%"
+			conditions = append(conditions, `(prompt LIKE ? ESCAPE '\' OR command LIKE ? ESCAPE '\')`)
+			args = append(args, pattern, pattern)
+		}
+	}
+	if !options.Since.IsZero() {
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, options.Since.Unix())
+	}
+	if !options.Until.IsZero() {
+		conditions = append(conditions, "created_at < ?")
+		args = append(args, options.Until.Unix())
+	}
+	if options.Cwd != "" {
+		dir := filepath.Clean(options.Cwd)
+		conditions = append(conditions, `(cwd = ? OR cwd LIKE ? ESCAPE '\')`)
+		args = append(args, dir, escapeLike(dir+string(filepath.Separator))+"%")
+	}
+	if options.Success != nil {
+		if *options.Success {
+			conditions = append(conditions, "(executed = 1 AND exit_code = 0)")
+		} else {
+			conditions = append(conditions, "NOT (executed = 1 AND exit_code IS NOT NULL AND exit_code = 0)")
+		}
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	result := &SearchResult{Records: []HistoryRecord{}}
+	if err := h.db.QueryRow("SELECT COUNT(*) FROM history"+where, args...).Scan(&result.Total); err != nil {
+		return nil, errors.New(i18n.T("查询历史记录失败: %s", err.Error()))
+	}
+
+	limit := options.Limit
+	if limit <= 0 {
+		limit = -1 // SQLite 中 -1 表示不限制
+	}
+	query := "SELECT " + recordColumns + " FROM history" + where +
+		" ORDER BY created_at DESC, id DESC LIMIT ? OFFSET ?"
+	rows, err := h.db.Query(query, append(args, limit, options.Offset)...)
+	if err != nil {
+		return nil, errors.New(i18n.T("查询历史记录失败: %s", err.Error()))
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		record, err := scanRecord(rows)
+		if err != nil {
+			return nil, errors.New(i18n.T("读取历史记录失败: %s", err.Error()))
+		}
+		result.Records = append(result.Records, record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.New(i18n.T("读取历史记录失败: %s", err.Error()))
+	}
+	return result, nil
+}
+
+// Get 按ID获取记录
+func (h *SQLiteCommandHistory) Get(id string) (*HistoryRecord, error) {
+	record, err := scanRecord(h.db.QueryRow("SELECT "+recordColumns+" FROM history WHERE record_id = ?", id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrRecordNotFound
+	}
+	if err != nil {
+		return nil, errors.New(i18n.T("读取历史记录失败: %s", err.Error()))
+	}
+	return &record, nil
+}
+
+// Delete 按ID删除记录
+func (h *SQLiteCommandHistory) Delete(id string) error {
+	tx, err := h.db.Begin()
+	if err != nil {
+		return errors.New(i18n.T("删除历史记录失败: %s", err.Error()))
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec("DELETE FROM history WHERE record_id = ?", id)
+	if err != nil {
+		return errors.New(i18n.T("删除历史记录失败: %s", err.Error()))
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return ErrRecordNotFound
+	}
+	if _, err := tx.Exec("INSERT OR IGNORE INTO deleted(record_id) VALUES (?)", id); err != nil {
+		return errors.New(i18n.T("删除历史记录失败: %s", err.Error()))
+	}
+	if err := tx.Commit(); err != nil {
+		return errors.New(i18n.T("删除历史记录失败: %s", err.Error()))
+	}
+	return nil
+}
+
+// Clear 删除全部记录
+func (h *SQLiteCommandHistory) Clear() error {
+	tx, err := h.db.Begin()
+	if err != nil {
+		return errors.New(i18n.T("清空历史记录失败: %s", err.Error()))
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("INSERT OR IGNORE INTO deleted(record_id) SELECT record_id FROM history"); err != nil {
+		return errors.New(i18n.T("清空历史记录失败: %s", err.Error()))
+	}
+	if _, err := tx.Exec("DELETE FROM history"); err != nil {
+		return errors.New(i18n.T("清空历史记录失败: %s", err.Error()))
+	}
+	if err := tx.Commit(); err != nil {
+		return errors.New(i18n.T("清空历史记录失败: %s", err.Error()))
+	}
+	return nil
+}
+
+// escapeLike 转义 LIKE 模式中的特殊字符
+func escapeLike(s string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+	return replacer.Replace(s)
+}
@@ -0,0 +1,288 @@
+package history
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/elecmonkey/prompt2cmd/internal/xdg"
+)
+
+// openTestSQLite 在临时目录中打开数据库，并隔离默认的JSON历史记录
+func openTestSQLite(t *testing.T, maxRecords int) (*SQLiteCommandHistory, string) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	path := filepath.Join(t.TempDir(), "history.db")
+	h, err := NewSQLiteCommandHistory(path, maxRecords)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { h.Close() })
+	return h, path
+}
+
+// testRecord 创建一条时间递增的记录
+func testRecord(n int, prompt, command, cwd string) HistoryRecord {
+	exitCode := 0
+	return HistoryRecord{
+		ID:        fmt.Sprintf("r%d", n),
+		Prompt:    prompt,
+		Command:   command,
+		Executed:  true,
+		ExitCode:  &exitCode,
+		Timestamp: time.Date(2024, 1, 1, 0, n, 0, 0, time.UTC).Format(time.RFC3339),
+		Cwd:       cwd,
+	}
+}
+
+func TestSQLitePathWithSpecialCharacters(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	path := filepath.Join(t.TempDir(), "a?b#c%d", "history.db")
+
+	h, err := NewSQLiteCommandHistory(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := h.AddRecord(testRecord(1, "list files", "ls", "/tmp")); err != nil {
+		t.Fatal(err)
+	}
+	h.Close()
+
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("database not created at %s: %v", path, err)
+	}
+	h, err = NewSQLiteCommandHistory(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Close()
+	if _, err := h.Get("r1"); err != nil {
+		t.Errorf("Get after reopen: %v", err)
+	}
+}
+
+func TestSQLiteMigratesFromVersion1(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	path := filepath.Join(t.TempDir(), "history.db")
+
+	db, err := sql.Open("sqlite", sqliteDSN(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, stmt := range []string{
+		sqliteSchemaV1,
+		"PRAGMA user_version = 1",
+		`INSERT INTO history(record_id, prompt, command, executed, timestamp, created_at, cwd)
+			VALUES ('old', 'list files', 'ls', 1, '2024-01-01T00:00:00Z', 1704067200, '/tmp')`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	db.Close()
+
+	h, err := NewSQLiteCommandHistory(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var version int
+	if err := h.db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		t.Fatal(err)
+	}
+	if version != sqliteSchemaVersion {
+		t.Errorf("user_version = %d, want %d", version, sqliteSchemaVersion)
+	}
+	record, err := h.Get("old")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record.ExitCode == nil || *record.ExitCode != 0 {
+		t.Errorf("migrated ExitCode = %v, want 0", record.ExitCode)
+	}
+	result, err := h.Search(SearchOptions{Query: "files"})
+	if err != nil || result.Total != 1 {
+		t.Errorf("Search after migration = %v, %v, want 1 record", result, err)
+	}
+
+	// 比当前程序更新的数据库拒绝打开
+	if _, err := h.db.Exec(fmt.Sprintf("PRAGMA user_version = %d", sqliteSchemaVersion+1)); err != nil {
+		t.Fatal(err)
+	}
+	h.Close()
+	if h, err := NewSQLiteCommandHistory(path, 0); err == nil {
+		h.Close()
+		t.Errorf("NewSQLiteCommandHistory opened a newer schema, want an error")
+	}
+}
+
+func TestSQLiteImportsJSON(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	jsonPath := xdg.DataFile("history.json")
+	dbPath := filepath.Join(t.TempDir(), "history.db")
+
+	fileHistory, err := NewFileCommandHistory(jsonPath, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, command := range []string{"ls", "pwd"} {
+		if err := fileHistory.AddRecord(testRecord(i+1, "prompt "+command, command, "/tmp")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	h, err := NewSQLiteCommandHistory(dbPath, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, err := h.GetHistory(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 || records[0].Command != "ls" || records[1].Command != "pwd" {
+		t.Fatalf("imported records = %v, want ls and pwd", records)
+	}
+	if err := h.Delete("r1"); err != nil {
+		t.Fatal(err)
+	}
+	h.Close()
+
+	// JSON文件变化后再次导入，新增的记录合并进来，删除的记录不会重新出现
+	if err := fileHistory.AddRecord(testRecord(3, "prompt date", "date", "/tmp")); err != nil {
+		t.Fatal(err)
+	}
+	h, err = NewSQLiteCommandHistory(dbPath, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Close()
+	records, err = h.GetHistory(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 || records[0].Command != "pwd" || records[1].Command != "date" {
+		t.Errorf("records after reimport = %v, want pwd and date", records)
+	}
+}
+
+func TestSQLiteSearch(t *testing.T) {
+	h, _ := openTestSQLite(t, 0)
+	for i, r := range []HistoryRecord{
+		testRecord(1, "列出所有文件", "ls -la", "/home/me/project"),
+		testRecord(2, "查找大文件", "find . -size +100M", "/home/me/project/sub"),
+		testRecord(3, "压缩日志", "tar czf logs.tgz logs", "/var/log"),
+	} {
+		if i == 2 {
+			failed := 2
+			r.ExitCode = &failed
+		}
+		if err := h.AddRecord(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	success := true
+	tests := []struct {
+		name    string
+		options SearchOptions
+		want    []string
+	}{
+		{"fts", SearchOptions{Query: "size"}, []string{"r2"}},
+		{"fts chinese", SearchOptions{Query: "大文件"}, []string{"r2"}},
+		{"short term", SearchOptions{Query: "文件"}, []string{"r2", "r1"}},
+		{"all terms", SearchOptions{Query: "tar logs"}, []string{"r3"}},
+		{"no match", SearchOptions{Query: "docker"}, nil},
+		{"quote in query", SearchOptions{Query: `"size`}, nil},
+		{"cwd", SearchOptions{Cwd: "/home/me/project"}, []string{"r2", "r1"}},
+		{"cwd prefix only", SearchOptions{Cwd: "/home/me/proj"}, nil},
+		{"success", SearchOptions{Success: &success}, []string{"r2", "r1"}},
+		{"since", SearchOptions{Since: time.Date(2024, 1, 1, 0, 2, 0, 0, time.UTC)}, []string{"r3", "r2"}},
+		{"page", SearchOptions{Limit: 1, Offset: 1}, []string{"r2"}},
+	}
+	for _, tt := range tests {
+		result, err := h.Search(tt.options)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		var ids []string
+		for _, record := range result.Records {
+			ids = append(ids, record.ID)
+		}
+		if fmt.Sprint(ids) != fmt.Sprint(tt.want) {
+			t.Errorf("%s: Search = %v, want %v", tt.name, ids, tt.want)
+		}
+	}
+
+	result, err := h.Search(SearchOptions{Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Total != 3 {
+		t.Errorf("Total = %d, want 3 regardless of the page size", result.Total)
+	}
+}
+
+func TestSQLiteDelete(t *testing.T) {
+	h, _ := openTestSQLite(t, 0)
+	if err := h.AddRecord(testRecord(1, "list files", "ls", "/tmp")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := h.Delete("missing"); !errors.Is(err, ErrRecordNotFound) {
+		t.Errorf("Delete(missing) = %v, want ErrRecordNotFound", err)
+	}
+	if err := h.Delete("r1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.Get("r1"); !errors.Is(err, ErrRecordNotFound) {
+		t.Errorf("Get after Delete = %v, want ErrRecordNotFound", err)
+	}
+	if result, err := h.Search(SearchOptions{Query: "files"}); err != nil || result.Total != 0 {
+		t.Errorf("Search after Delete = %v, %v, want no records", result, err)
+	}
+
+	// 删除的记录不能再被导入
+	if added, err := h.Import([]HistoryRecord{testRecord(1, "list files", "ls", "/tmp")}); err != nil || added != 0 {
+		t.Errorf("Import of a deleted record = %d, %v, want 0", added, err)
+	}
+}
+
+func TestSQLiteMaxRecords(t *testing.T) {
+	h, _ := openTestSQLite(t, 3)
+	for i := 1; i <= 5; i++ {
+		if err := h.AddRecord(testRecord(i, "prompt", fmt.Sprintf("echo %d", i), "/tmp")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	records, err := h.GetHistory(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, record := range records {
+		ids = append(ids, record.ID)
+	}
+	if fmt.Sprint(ids) != "[r3 r4 r5]" {
+		t.Errorf("records = %v, want [r3 r4 r5]", ids)
+	}
+
+	added, err := h.Import([]HistoryRecord{testRecord(6, "prompt", "echo 6", "/tmp"), testRecord(1, "prompt", "echo 1", "/tmp")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if added != 1 {
+		t.Errorf("Import added %d, want only the record that was not trimmed", added)
+	}
+	if result, err := h.Search(SearchOptions{}); err != nil || result.Total != 3 {
+		t.Errorf("Total after Import = %v, %v, want 3", result, err)
+	}
+}
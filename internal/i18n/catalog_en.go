@@ -0,0 +1,703 @@
+package i18n
+
+// english 英文消息目录，以中文原文为键，格式化动词的顺序和数量必须与原文一致
+var english = map[string]string{
+	// 终端界面
+	"未知路径":   "unknown path",
+	"你想要：":   "What do you want: ",
+	"生成的命令:": "Generated command:",
+	"命令解释:":  "Explanation:",
+	"是否执行此命令? (y/n/e[编辑]): ": "Run this command? (y/n/e[edit]): ",
+	"无效输入，请输入 y/n/e":         "Invalid input, please enter y/n/e",
+	"命令被安全策略禁止执行":            "The command is blocked by the security policy",
+	"风险等级: ":                 "Risk level: ",
+	"这是一个严重风险操作。请输入 %s 确认执行 (n取消/e编辑): ": "This is a critical-risk operation. Type %s to confirm (n to cancel/e to edit): ",
+	"确认内容不匹配，请完整输入 %s":                   "Confirmation did not match, please type %s exactly",
+	"执行结果:":      "Output:",
+	"错误: ":       "Error: ",
+	"读取输入失败: %s": "Failed to read input: %s",
+	"输入不能为空":     "Input cannot be empty",
+
+	// 安全检查
+	"检测到 fork 炸弹，会耗尽系统资源": "fork bomb detected, it will exhaust system resources",
+	"命令包含配置的危险命令: %s":     "command contains a configured dangerous command: %s",
+	"等 %d 个路径":            "%d paths in total",
+	"切换目录后无法确定操作的位置: %s":  "cannot tell where the command operates after changing directory: %s",
+	"将写入或删除受保护路径: %s":     "will write to or delete protected paths: %s",
+	"命令可能修改文件或系统状态":       "the command may modify files or system state",
+	"禁止：此命令被安全策略禁止执行：%s。": "Blocked: this command is forbidden by the security policy: %s.",
+	"；": "; ",
+	"警告：此命令的风险等级为「%s」，检测到以下危险模式：":                          "Warning: this command has risk level \"%s\", the following dangerous patterns were detected:",
+	"请确认您了解此命令的影响后再继续。":                                    "Make sure you understand the impact of this command before continuing.",
+	"警告：此命令的风险等级为「%s」：%s。可能会导致数据丢失或系统问题，请确认您了解此命令的影响后再继续。": "Warning: this command has risk level \"%s\": %s. It may cause data loss or system problems, make sure you understand its impact before continuing.",
+	"远程代码执行":  "remote code execution",
+	"混淆代码执行":  "obfuscated code execution",
+	"反弹shell": "reverse shell",
+	"数据外传":    "data exfiltration",
+	"权限提升":    "privilege escalation",
+	"通过命令替换执行 %s 下载的内容":                        "executes content downloaded by %s through command substitution",
+	"eval 执行了解码后的内容，真实命令无法在执行前审查":              "eval runs decoded content, the real command cannot be reviewed before it runs",
+	"%s 下载的内容通过管道直接交给 %s 执行":                   "content downloaded by %s is piped directly into %s",
+	"%s 解码后的内容通过管道直接交给 %s 执行":                  "content decoded by %s is piped directly into %s",
+	"通过 /dev/tcp 或 /dev/udp 建立网络连接，常用于反弹shell": "opens a network connection through /dev/tcp or /dev/udp, commonly used for reverse shells",
+	"%s -e/-c 会把shell绑定到网络连接":                  "%s -e/-c binds a shell to a network connection",
+	"%s 将本地数据发送到 %s":                           "%s sends local data to %s",
+	"socat 会把本地程序绑定到网络连接":                      "socat binds a local program to a network connection",
+	"%s 脚本同时使用了网络连接和进程执行，疑似反弹shell":            "the %s script uses both network connections and process execution, likely a reverse shell",
+	"使用 sudo/doas 以管理员权限执行 %s":                 "runs %s with administrator privileges through sudo/doas",
+	"%s 会切换到其他用户（通常是 root）":                    "%s switches to another user (usually root)",
+	"setcap 会为程序授予特权能力":                        "setcap grants privileged capabilities to a program",
+	"visudo 会修改 sudo 权限配置":                     "visudo modifies the sudo configuration",
+	"chmod 设置了 setuid/setgid 位，程序将以文件所有者的权限运行": "chmod sets the setuid/setgid bit, the program will run with the file owner's privileges",
+	"修改 %s 会改变系统的用户或权限配置":                      "modifying %s changes the system's user or permission configuration",
+	"curl 将上传本地文件 %s":                          "curl will upload the local file %s",
+	"curl 将以表单上传本地文件 %s":                       "curl will upload the local file %s as a form",
+	"curl 将发送本地文件 %s 的内容":                      "curl will send the contents of the local file %s",
+	"wget 将发送本地文件的内容":                          "wget will send the contents of a local file",
+	"%s 将本地文件复制到远程主机 %s":                       "%s copies local files to the remote host %s",
+	"将影响 %d 个路径，共超过 %d 个文件":                    "affects %d paths, more than %d files in total",
+	"将影响 %d 个路径，共 %d 个文件":                      "affects %d paths, %d files in total",
+	"... 以及另外 %d 个路径":                          "... and %d more paths",
+	"命中安全策略规则":                                 "matched a security policy rule",
+	"策略规则 %s: %s":                              "policy rule %s: %s",
+	"安全":                                       "Safe",
+	"注意":                                       "Caution",
+	"危险":                                       "Dangerous",
+	"严重":                                       "Critical",
+	"未知":                                       "Unknown",
+	"无效的风险等级: %s":                              "invalid risk level: %s",
+	"模型复核: ":                                   "model review: ",
+	"%s 会格式化或擦除文件系统":                           "%s formats or wipes a file system",
+	"dd 将直接写入设备 %s":                            "dd will write directly to the device %s",
+	"dd 会直接按块复制或覆盖数据":                          "dd copies or overwrites data block by block",
+	"shred 会不可恢复地覆盖文件内容":                       "shred irreversibly overwrites file contents",
+	"shred 将擦除设备 %s":                           "shred will wipe the device %s",
+	"%s 会修改磁盘分区表":                              "%s modifies the disk partition table",
+	"find -delete 会删除所有匹配的文件":                  "find -delete deletes every matching file",
+	"%s -R 会递归修改权限或所有权":                        "%s -R recursively changes permissions or ownership",
+	"%s -R 的目标是 %s":                            "the target of %s -R is %s",
+	"%s 会关闭或重启系统":                              "%s shuts down or restarts the system",
+	"kill -1 会向所有进程发送信号":                       "kill -1 sends a signal to every process",
+	"重定向将直接覆盖设备 %s":                            "the redirection will overwrite the device %s",
+	"重定向将修改系统配置文件 %s":                          "the redirection will modify the system configuration file %s",
+	"rm 使用了 --no-preserve-root":                "rm uses --no-preserve-root",
+	"xargs 交给 rm 的删除目标要在执行时才能确定":               "the files xargs passes to rm are only known at run time",
+	"rm -r 会递归删除目录及其全部内容":                      "rm -r recursively deletes directories and everything in them",
+	"rm -r 的目标是 %s":                            "the target of rm -r is %s",
+	"rm -f 会不经确认强制删除文件":                        "rm -f forcibly deletes files without confirmation",
+
+	// 配置
+	"通过凭据命令获取API密钥失败: %s": "failed to get the API key from the credential command: %s",
+	"未找到 %s 的API密钥。请运行 prompt2cmd config set-key 将密钥保存到系统密钥环，或设置凭据命令 llm.api_key_command（例如 pass show %s），也可以设置 LLM_API_KEY 环境变量": "No API key found for %s. Run prompt2cmd config set-key to save the key in the system keyring, set a credential command in llm.api_key_command (for example pass show %s), or set the LLM_API_KEY environment variable",
+	"（无法读取系统密钥环: %s）":                                                                   " (cannot read the system keyring: %s)",
+	"不支持的LLM提供商: %s":                                                                    "unsupported LLM provider: %s",
+	"启用了本地模型(USE_LOCAL_MODEL=true)，但未设置LOCAL_MODEL_PATH":                                "the local model is enabled (USE_LOCAL_MODEL=true) but LOCAL_MODEL_PATH is not set",
+	"MAX_HISTORY_SIZE必须是一个有效的整数: %s":                                                    "MAX_HISTORY_SIZE must be a valid integer: %s",
+	"MAX_HISTORY_SIZE必须大于0":                                                             "MAX_HISTORY_SIZE must be greater than 0",
+	"HISTORY_BACKEND必须是 sqlite 或 json: %s":                                              "HISTORY_BACKEND must be sqlite or json: %s",
+	"HISTORY_CONTEXT_TOKENS必须是一个有效的整数: %s":                                              "HISTORY_CONTEXT_TOKENS must be a valid integer: %s",
+	"HISTORY_CONTEXT_TOKENS不能为负数":                                                       "HISTORY_CONTEXT_TOKENS cannot be negative",
+	"PROTECTED_PATH_ACTION必须是 deny 或 confirm: %s":                                       "PROTECTED_PATH_ACTION must be deny or confirm: %s",
+	"LLM_RISK_REVIEW必须是 off、auto 或 always: %s":                                          "LLM_RISK_REVIEW must be off, auto or always: %s",
+	"LLM_AUDIT_POLICY必须是 always、on-failure、on-nonzero-exit、on-empty-output 或 never: %s": "LLM_AUDIT_POLICY must be always, on-failure, on-nonzero-exit, on-empty-output or never: %s",
+	"UI_LANGUAGE必须是 auto、zh 或 en: %s":                                                   "UI_LANGUAGE must be auto, zh or en: %s",
+	"LLM_BASE_URL无效: %s":                                                                "invalid LLM_BASE_URL: %s",
+	"LLM_BASE_URL必须是以 http:// 或 https:// 开头的完整地址: %s":                                   "LLM_BASE_URL must be a full URL starting with http:// or https://: %s",
+	"LLM_BASE_URL不能包含查询参数: %s":                                                          "LLM_BASE_URL cannot contain query parameters: %s",
+	"解析配置文件 %s 失败: %s":                                                                  "failed to parse config file %s: %s",
+	"读取配置文件失败: %s":                                                                      "failed to read config file: %s",
+	"配置文件 %s 的顶层应为映射":                                                                   "the top level of config file %s must be a mapping",
+	"%s:%d: profile 应为配置档案名称":                                                           "%s:%d: profile must be a profile name",
+	"%s:%d: profiles 应为映射":                                                              "%s:%d: profiles must be a mapping",
+	"%s: 配置档案 %s: %s":                                                                   "%s: profile %s: %s",
+	"第 %d 行: 未知的配置项 %s":                                                                 "line %d: unknown setting %s",
+	"第 %d 行: %s 的列表项应为字符串":                                                              "line %d: list items of %s must be strings",
+	"第 %d 行: %s 的值无效":                                                                   "line %d: invalid value for %s",
+	"无":                                                                                 "none",
+	"系统配置":                                                                              "system config",
+	"用户配置":                                                                              "user config",
+	"项目配置":                                                                              "project config",
+	"环境变量":                                                                              "environment variable",
+	"命令行选项":                                                                             "command-line option",
+	"默认值":                                                                               "default",
+	"（配置档案 %s）":                                                                         " (profile %s)",
+	"配置档案 %s 不存在（可用的配置档案: %s）":                                                          "profile %s does not exist (available profiles: %s)",
+	"警告: 已忽略项目配置文件 %s 中的 %s，请在用户配置或环境变量中设置":                    "Warning: project config file %s cannot set %s, it was ignored; set it in the user config or an environment variable",
+	"警告: 已忽略 %s 中的 LLM_BASE_URL，只有同一文件中也设置了 LLM_API_KEY 时才会使用": "Warning: ignored LLM_BASE_URL in %s; it is only used when the same file also sets LLM_API_KEY",
+	"配置档案 %s: %s":                                          "profile %s: %s",
+	"安全策略文件不存在: %s":                                        "security policy file does not exist: %s",
+	"读取安全策略文件失败: %s":                                       "failed to read security policy file: %s",
+	"解析安全策略文件失败: %s":                                       "failed to parse security policy file: %s",
+	"安全策略文件 %s 无效: %s":                                     "invalid security policy file %s: %s",
+	"规则 %s 缺少 action":                                      "rule %s is missing action",
+	"规则 %s 的 action 无效: %s（可选 allow, warn, confirm, deny）": "rule %s has an invalid action: %s (allowed: allow, warn, confirm, deny)",
+	"规则 %s 没有任何匹配条件":                                       "rule %s has no match conditions",
+	"规则 %s 的 program 通配符无效: %s":                            "rule %s has an invalid program pattern: %s",
+	"规则 %s 的 args 正则无效: %s":                                "rule %s has an invalid args regular expression: %s",
+	"规则 %s 的 command 正则无效: %s":                             "rule %s has an invalid command regular expression: %s",
+
+	// 历史记录
+	"无法访问历史记录数据库: %s":                             "cannot access history database: %s",
+	"打开历史记录数据库失败: %s":                             "failed to open history database: %s",
+	"读取历史记录数据库版本失败: %s":                           "failed to read history database version: %s",
+	"历史记录数据库版本 %d 高于当前程序支持的版本 %d，请升级 prompt2cmd":  "history database version %d is newer than the supported version %d, please upgrade prompt2cmd",
+	"检查历史记录数据库失败: %s":                             "failed to check history database: %s",
+	"历史记录数据库已损坏: %s":                              "history database is corrupted: %s",
+	"读取历史记录失败: %s":                                "failed to read history: %s",
+	"读取历史记录文件失败: %s":                              "failed to read history file: %s",
+	"解析历史记录失败: %s":                                "failed to parse history: %s",
+	"无法确定历史记录文件路径":                                "cannot determine history file path",
+	"警告: %s":                                      "Warning: %s",
+	"%s，将使用空历史记录":                                 "%s, starting with empty history",
+	"读取历史记录文件失败: %s，将使用空历史记录":                     "failed to read history file: %s, starting with empty history",
+	"解析历史记录失败: %s，将使用空历史记录并备份旧文件":                 "failed to parse history: %s, starting with empty history and backing up the old file",
+	"发现 %d 条无效历史记录，已过滤":                           "found %d invalid history records, filtered out",
+	"序列化历史记录失败: %s":                               "failed to serialize history: %s",
+	"创建历史记录目录失败: %s":                              "failed to create history directory: %s",
+	"设置历史记录数据库权限失败: %s":                           "failed to restrict history database permissions: %s",
+	"创建临时历史记录文件失败: %s":                            "failed to create temporary history file: %s",
+	"写入历史记录文件失败: %s":                              "failed to write history file: %s",
+	"锁定历史记录文件失败: %s":                              "failed to lock history file: %s",
+	"%s，将备份旧文件并以当前会话的记录为准":                        "%s, backing up the old file and keeping this session's records",
+	"已将损坏的历史记录文件备份到: %s":                          "backed up corrupted history file to: %s",
+	"历史记录文件版本高于当前程序支持的版本（%d > %d），请升级 prompt2cmd": "history file version is newer than supported (%d > %d), please upgrade prompt2cmd",
+	"\n... 省略 %d 字节 ...\n":                        "\n... %d bytes omitted ...\n",
+	"历史记录不存在":                                     "history record not found",
+	"无法确定用户主目录: %s":                               "cannot determine home directory: %s",
+	"不支持的 shell 历史格式: %s":                         "unsupported shell history format: %s",
+	"导入JSON历史记录失败: %s":                            "failed to import JSON history: %s",
+	"升级历史记录数据库到版本 %d 失败: %s":                      "failed to upgrade history database to version %d: %s",
+	"已将 %s 中的 %d 条历史记录导入 %s，原文件保留不变":              "imported history from %s (%d records) into %s, the original file is kept",
+	"写入历史记录失败: %s":                                "failed to write history: %s",
+	"导入历史记录失败: %s":                                "failed to import history: %s",
+	"查询历史记录失败: %s":                                "failed to query history: %s",
+	"删除历史记录失败: %s":                                "failed to delete history: %s",
+	"清空历史记录失败: %s":                                "failed to clear history: %s",
+
+	// 审计日志
+	"创建审计日志目录失败: %s": "failed to create audit log directory: %s",
+	"锁定审计日志失败: %s":   "failed to lock audit log: %s",
+	"打开审计日志失败: %s":   "failed to open audit log: %s",
+	"审计日志已损坏，拒绝继续追加（可运行 prompt2cmd audit verify 检查）: %s": "audit log is corrupted, refusing to append (run prompt2cmd audit verify to check): %s",
+	"计算审计日志哈希失败: %s":                     "failed to compute audit log hash: %s",
+	"序列化审计日志失败: %s":                      "failed to serialize audit log entry: %s",
+	"写入审计日志失败: %s":                       "failed to write audit log: %s",
+	"同步审计日志失败: %s":                       "failed to sync audit log: %s",
+	"最后一条记录不完整":                          "the last entry is incomplete",
+	"解析最后一条记录失败: %s":                     "failed to parse the last entry: %s",
+	"写入审计日志链头失败: %s":                     "failed to write audit log chain head: %s",
+	"读取审计日志失败: %s":                       "failed to read audit log: %s",
+	"第 %d 行: 记录不完整，文件可能被截断":              "line %d: incomplete entry, the file may have been truncated",
+	"第 %d 行: 无法解析: %s":                   "line %d: cannot parse: %s",
+	"第 %d 行: 序号应为 %d，实际为 %d，记录可能被删除或插入":  "line %d: expected sequence %d but got %d, entries may have been deleted or inserted",
+	"第 %d 行: prev_hash 与上一条记录不一致，哈希链已断开": "line %d: prev_hash does not match the previous entry, the hash chain is broken",
+	"第 %d 行: 哈希不匹配，记录内容被修改":              "line %d: hash mismatch, the entry has been modified",
+	"缺少链头文件，无法确认尾部记录是否被删除":               "chain head file is missing, cannot tell whether trailing entries were deleted",
+	"读取审计日志链头失败: %s":                     "failed to read audit log chain head: %s",
+	"链头文件无法解析: %s":                       "cannot parse chain head file: %s",
+	"日志最后一条记录为 #%d，但链头记录为 #%d，尾部记录可能被删除": "the last log entry is #%d but the chain head records #%d, trailing entries may have been deleted",
+
+	// 模型
+	"当前路径：%s\n用户需求：%s":               "Current path: %s\nRequest: %s",
+	"（用户曾在 %s 中直接执行下面的命令，仅作为使用习惯参考）": "(the user ran the following command directly in %s, for reference on their habits only)",
+	"```\n%s\n```\n\n已生成上述命令，%s":     "```\n%s\n```\n\nGenerated the command above, %s",
+	"未执行":            "not executed",
+	"已执行，结果未知":       "executed, result unknown",
+	"执行成功":           "executed successfully",
+	"执行失败（退出码 %d）":   "failed (exit code %d)",
+	"解析JSON内容失败: %s": "failed to parse JSON content: %s",
+	"未找到生成的命令":       "no command was generated",
+	"未提供命令解释":        "no explanation provided",
+	"[无任何输出]":        "[no output]",
+	"用户需求: %s\n执行的命令: %s\n执行结果:\n%s":   "Request: %s\nExecuted command: %s\nResult:\n%s",
+	"解析JSON审计结果失败: %s":                 "failed to parse JSON audit result: %s",
+	"当前工作目录: %s\n用户需求: %s\n待执行的命令: %s": "Working directory: %s\nRequest: %s\nCommand to run: %s",
+	"解析JSON风险复核结果失败: %s":               "failed to parse JSON risk review: %s",
+	"序列化请求失败: %s":                      "failed to serialize request: %s",
+	"创建HTTP请求失败: %s":                   "failed to create HTTP request: %s",
+	"发送请求失败: %s":                       "failed to send request: %s",
+	"读取响应失败: %s":                       "failed to read response: %s",
+	"API调用失败，状态码: %d, 响应: %s":          "API call failed, status code: %d, response: %s",
+	"解析响应失败: %s":                       "failed to parse response: %s",
+	"未找到生成结果":                          "no completion found",
+	"解析生成结果失败":                         "failed to parse completion",
+	"解析消息失败":                           "failed to parse message",
+	"生成内容为空":                           "completion is empty",
+	"解析模型列表失败: %s":                     "failed to parse model list: %s",
+	"读取提示词模板 %s 失败: %s":                "failed to read prompt template %s: %s",
+	"未知的提示词模板: %s":                     "unknown prompt template: %s",
+	"内置模板 %s":                          "built-in template %s",
+	"解析提示词模板 %s 失败: %s":                "failed to parse prompt template %s: %s",
+	"渲染提示词模板 %s 失败: %s":                "failed to render prompt template %s: %s",
+	"命令不能为空":                           "command must not be empty",
+	"当前LLM提供商不支持风险复核":                  "the current LLM provider does not support risk review",
+
+	// 脱敏
+	"私钥":       "private key",
+	"AWS访问密钥":  "AWS access key",
+	"GitHub令牌": "GitHub token",
+	"Slack令牌":  "Slack token",
+	"API密钥":    "API key",
+	"Bearer令牌": "bearer token",
+	"URL中的密码":  "password in URL",
+	"配置中的密钥":   "secret in config",
+	"高熵字符串":    "high-entropy string",
+
+	// 密钥环
+	"macOS 钥匙串":                "macOS Keychain",
+	"保存密钥失败: %s %s":            "failed to save secret: %s %s",
+	"访问钥匙串失败: %s":              "failed to access keychain: %s",
+	"密钥环中没有对应的密钥":              "no matching secret in the keyring",
+	"当前系统不支持系统密钥环":             "system keyring is not supported on this system",
+	"凭据命令超过 %s 没有完成":           "credential command did not finish within %s",
+	"凭据命令没有输出密钥":               "credential command produced no secret",
+	"无法连接D-Bus会话总线: %s":        "cannot connect to the D-Bus session bus: %s",
+	"无法打开Secret Service会话: %s": "cannot open a Secret Service session: %s",
+	"查找密钥失败: %s":               "failed to look up secret: %s",
+	"解锁密钥环失败: %s":              "failed to unlock keyring: %s",
+	"等待密钥环确认失败: %s":            "failed to wait for keyring confirmation: %s",
+	"显示密钥环确认对话框失败: %s":         "failed to show keyring confirmation dialog: %s",
+	"已取消解锁密钥环":                 "keyring unlock was cancelled",
+	"等待密钥环确认超时":                "timed out waiting for keyring confirmation",
+	"读取密钥失败: %s":               "failed to read secret: %s",
+	"%s 的 %s API密钥":            "%s API key for %s",
+	"保存密钥失败: %s":               "failed to save secret: %s",
+	"删除密钥失败: %s":               "failed to delete secret: %s",
+
+	// 片段和配方
+	"配方 %s 缺少 description":    "recipe %s is missing description",
+	"配方 %s 缺少 command":        "recipe %s is missing command",
+	"配方 %s: %s":               "recipe %s: %s",
+	"%s: 配方名称 %s 与 %s 重复，已跳过": "%s: recipe name %s duplicates %s, skipped",
+	"配方文件应为一个配方或配方列表":         "a recipe file must contain a recipe or a list of recipes",
+	"无效的片段名称 %q：只能包含字母、数字、下划线、连字符和点": "invalid snippet name %q: only letters, digits, underscores, hyphens and dots are allowed",
+	"缺少参数: %s":              "missing parameters: %s",
+	"片段中没有这些参数: %s":         "the snippet has no such parameters: %s",
+	"引号没有闭合: %s":            "unclosed quote: %s",
+	"无效的参数 %q，请使用 名称=值 的形式": "invalid argument %q, use the form name=value",
+	"片段不存在":                 "snippet not found",
+	"片段的命令不能为空":             "snippet command must not be empty",
+	"读取片段文件失败: %s":          "failed to read snippet file: %s",
+	"解析片段文件失败: %s":          "failed to parse snippet file: %s",
+	"片段文件版本 %d 高于当前程序支持的版本 %d，请升级 prompt2cmd": "snippet file version %d is newer than the supported version %d, please upgrade prompt2cmd",
+	"锁定片段文件失败: %s":   "failed to lock snippet file: %s",
+	"序列化片段失败: %s":    "failed to serialize snippets: %s",
+	"创建片段目录失败: %s":   "failed to create snippet directory: %s",
+	"创建临时片段文件失败: %s": "failed to create temporary snippet file: %s",
+	"写入片段文件失败: %s":   "failed to write snippet file: %s",
+
+	// 子命令
+	"审计日志文件（默认使用配置中的 AUDIT_LOG_FILE）": "audit log file (defaults to AUDIT_LOG_FILE from the config)",
+	"审计日志: %s": "Audit log: %s",
+	"审计日志已被篡改（前 %d 条记录校验通过）":                      "audit log has been tampered with (the first %d entries verified)",
+	"审计日志完整，共 %d 条记录":                             "audit log is intact, %d entries",
+	"用法: prompt2cmd audit verify [--file 审计日志文件]": "Usage: prompt2cmd audit verify [--file audit-log-file]",
+	"未知的 config 子命令: %s":                          "unknown config subcommand: %s",
+	"显示每个配置项的来源":                                  "show where each setting comes from",
+	"配置档案: %s":                                    "Profile: %s",
+	"配置文件: 无":                                     "Config files: none",
+	"配置文件（后面的覆盖前面的）:":                             "Config files (later ones override earlier ones):",
+	"配置项\t值\t来源":                                  "Setting\tValue\tOrigin",
+	"配置项\t值":                                      "Setting\tValue",
+	"（未设置）":                                       "(not set)",
+	"启动本地模拟服务失败: %s":                              "failed to start the local mock server: %s",
+	"（使用时获取）":                                     "(fetched when used)",
+	"配置无效: %s":                                    "invalid configuration: %s",
+	"凭据命令 %s":                                     "credential command %s",
+	"系统密钥环 %s":                                    "system keyring %s",
+	"LLM提供商，默认为当前配置的提供商":                          "LLM provider, defaults to the configured provider",
+	"%s 是本地模型，不需要API密钥":                           "%s is a local model and needs no API key",
+	"保存到%s失败: %s":                                 "failed to save to %s: %s",
+	"也可以在配置文件中设置凭据命令 llm.api_key_command，例如 pass show %s": "you can also set a credential command llm.api_key_command in the config file, e.g. pass show %s",
+	"已将 %s 的API密钥保存到%s":                                 "saved the API key for %s to %s",
+	"%s 中设置的 llm.api_key 优先于密钥环，建议删除其中的明文密钥":            "llm.api_key set in %s takes precedence over the keyring, consider removing the plaintext key",
+	"%s 中设置了凭据命令 llm.api_key_command，设置凭据命令时不会读取密钥环":    "%s sets the credential command llm.api_key_command, the keyring is not read when a credential command is set",
+	"请输入 %s 的API密钥（输入时不显示）: ":                           "Enter the API key for %s (input is hidden): ",
+	"读取API密钥失败: %s":                                     "failed to read API key: %s",
+	"API密钥不能为空":                                         "API key must not be empty",
+	"%s中没有 %s 的API密钥":                                   "%s has no API key for %s",
+	"删除失败: %s":                                          "delete failed: %s",
+	"已从%s删除 %s 的API密钥":                                  "deleted the API key from %s for %s",
+	"%s 需要指定一个值":                                        "%s requires a value",
+	"未知的子命令: %s":                                        "unknown subcommand: %s",
+	"获取当前工作目录失败: %s":                                    "failed to get the current working directory: %s",
+	"要测试的安全策略文件（默认使用配置中的策略）":                            "security policy file to test (defaults to the configured policy)",
+	"请提供要测试的命令":                                         "please provide a command to test",
+	"命令: %s":                                            "Command: %s",
+	"策略文件: %s":                                          "Policy file: %s",
+	"策略文件: 未配置（仅使用内置规则）":                                "Policy file: not configured (built-in rules only)",
+	"命中规则: 无":                                           "Matched rules: none",
+	"命中规则:":                                             "Matched rules:",
+	"风险等级: %s (%s)":                                     "Risk level: %s (%s)",
+	"判定理由:":                                             "Reasons:",
+	"结果: ⛔ 禁止执行":                                        "Result: ⛔ blocked",
+	"结果: ❗ 需要完整输入 %s 确认":                                "Result: ❗ requires typing %s to confirm",
+	"结果: ✅ 只读命令":                                        "Result: ✅ read-only command",
+	"结果: ❓ 需要确认后执行":                                     "Result: ❓ requires confirmation",
+	"加载配置失败，使用默认危险命令列表: %s":                             "failed to load config, using the default dangerous command list: %s",
+	"用法: prompt2cmd policy test [--file 策略文件] \"<命令>\"": "Usage: prompt2cmd policy test [--file policy-file] \"<command>\"",
+
+	// 诊断
+	"配置目录":    "Config directory",
+	"配置文件":    "Config file",
+	"配置项":     "Settings",
+	"模型接口":    "Model API",
+	"模型":      "Model",
+	"审计模型":    "Audit model",
+	"历史记录目录":  "History directory",
+	"历史记录":    "History",
+	"本地模型":    "Local model",
+	"安全策略":    "Security policy",
+	"审计日志":    "Audit log",
+	"团队配方":    "Team recipes",
+	"提示词模板":   "Prompt templates",
+	"不连接模型接口": "do not connect to the model API",
+	"检查目录权限，或通过 XDG_CONFIG_HOME 指定其他配置目录":      "check directory permissions, or use XDG_CONFIG_HOME to choose another config directory",
+	"修改配置文件中报错的行，或运行 prompt2cmd init 重新生成配置文件": "fix the reported line in the config file, or run prompt2cmd init to regenerate it",
+	"没有找到配置文件，只使用环境变量和默认值":                     "no config file found, using environment variables and defaults only",
+	"运行 prompt2cmd init 生成配置文件":                "run prompt2cmd init to create a config file",
+	"提供商 %s，模型 %s，地址 %s":                       "provider %s, model %s, URL %s",
+	"，配置档案 %s":                                 ", profile %s",
+	"来自凭据命令 %s":                                "from credential command %s",
+	"来自系统密钥环":                                  "from the system keyring",
+	"来自%s":                                     "from %s",
+	"明文保存在%s":                                  "stored in plaintext in %s",
+	"运行 prompt2cmd config set-key 将密钥保存到系统密钥环，并从配置文件中删除明文密钥": "run prompt2cmd config set-key to save the key to the system keyring, and remove the plaintext key from the config file",
+	"本地模型不需要API密钥": "local models need no API key",
+	"%s（%s）来自%s":   "%s (%s) comes from %s",
+	"运行 prompt2cmd config show --origin 查看每个配置项的来源":    "run prompt2cmd config show --origin to see where each setting comes from",
+	"离线模式，未连接 %s":                                      "offline mode, did not connect to %s",
+	"检查网络连接和 llm.base_url":                             "check your network connection and llm.base_url",
+	"API密钥无效或没有权限，运行 prompt2cmd config set-key 重新保存密钥": "the API key is invalid or lacks permission, run prompt2cmd config set-key to save it again",
+	"地址不是 OpenAI 兼容接口，检查 llm.base_url 是否缺少 /v1 等路径":    "the URL is not an OpenAI-compatible API, check whether llm.base_url is missing a path such as /v1",
+	"确认 Ollama 正在运行（ollama serve），并检查 llm.base_url":    "make sure Ollama is running (ollama serve) and check llm.base_url",
+	"%s 可用，共 %d 个模型":                                   "%s is available, %d models",
+	"检查 %s，可用的模型: %s":                                  "check %s, available models: %s",
+	"运行 ollama pull %s 下载模型，或修改 %s":                    "run ollama pull %s to download the model, or change %s",
+	"模型列表中没有 %s":                                       "%s is not in the model list",
+	"找不到 %s":                                           "cannot find %s",
+	"安装该shell或修改配置项 shell":                             "install the shell or change the shell setting",
+	"检查目录权限，或通过 XDG_DATA_HOME 指定其他数据目录":                "check directory permissions, or use XDG_DATA_HOME to choose another data directory",
+	"备份后删除该文件，程序会重新创建":                                 "back up and delete the file, it will be recreated",
+	"修复或删除该文件；程序下次启动时会备份损坏的文件并使用空历史记录":                 "repair or delete the file; on the next start the corrupted file is backed up and history starts empty",
+	"%s 尚未创建":                          "%s has not been created yet",
+	"%s，%d 条记录":                        "%s, %d records",
+	"无法访问 %s":                          "cannot access %s",
+	"检查 llm.local_model_path":          "check llm.local_model_path",
+	"%s，%d 条规则":                        "%s, %d rules",
+	"检查目录权限，或修改 audit.file":            "check directory permissions, or change audit.file",
+	"%d 个配方无效: %s":                     "%d invalid recipes: %s",
+	"运行 prompt2cmd recipe list 查看全部问题": "run prompt2cmd recipe list to see all problems",
+	"%s，%d 个配方":                        "%s, %d recipes",
+	"尚未同步 %s":                          "%s has not been synced yet",
+	"运行 prompt2cmd recipe sync":        "run prompt2cmd recipe sync",
+	"检查 %s 的权限":                        "check the permissions of %s",
+	"修改模板，或删除该文件以使用内置模板":               "fix the template, or delete the file to use the built-in template",
+	"%s 不可写: %s":                       "%s is not writable: %s",
+	" 尚未创建，将在首次使用时创建":                  " does not exist yet and will be created on first use",
+	"有 %d 项检查未通过，%d 项警告":               "%d checks failed, %d warnings",
+	"检查通过，%d 项警告":                      "checks passed, %d warnings",
+	"检查全部通过":                           "all checks passed",
+
+	// 历史记录命令
+	"SQLite历史记录不可用，改用JSON文件: %s":              "SQLite history is unavailable, falling back to a JSON file: %s",
+	"历史记录功能不可用: %s":                           "history is unavailable: %s",
+	"程序将继续运行，但不会记录命令历史":                       "the program keeps running but will not record command history",
+	"使用 /rerun <编号> 重新执行（仍需确认）":               "use /rerun <number> to run again (still requires confirmation)",
+	"重新执行历史记录 %s（原需求：%s）":                     "rerunning history record %s (original request: %s)",
+	"该命令原先在 %s 中执行，当前目录为 %s":                  "this command originally ran in %s, the current directory is %s",
+	"来自历史记录":                                  "from history",
+	"未知的 history 子命令: %s":                     "unknown history subcommand: %s",
+	"无法解析日期 %s，请使用 2006-01-02 或 RFC3339 格式":   "cannot parse date %s, use 2006-01-02 or RFC3339",
+	"每页数量，0 表示全部":                             "records per page, 0 for all",
+	"跳过的记录数":                                  "number of records to skip",
+	"以JSON格式输出":                               "output as JSON",
+	"不早于该日期":                                  "not before this date",
+	"不晚于该日期":                                  "not after this date",
+	"只显示在该目录或其子目录中执行的记录":                      "only show records run in this directory or its subdirectories",
+	"只显示执行成功的记录":                              "only show successful records",
+	"只显示未执行或执行失败的记录":                          "only show records that were not run or failed",
+	"请提供搜索关键词或过滤条件":                           "please provide a search keyword or filter",
+	"--success 和 --failed 不能同时使用":             "--success and --failed cannot be used together",
+	"共 %d 条，显示第 %d-%d 条（使用 --offset %d 查看更多）": "%d records, showing %d-%d (use --offset %d to see more)",
+	"没有历史记录":                                  "no history",
+	"#\tID\t时间\t状态\t命令\t需求":                   "#\tID\tTime\tStatus\tCommand\tRequest",
+	"已导入":                                     "imported",
+	"成功":                                      "success",
+	"失败(%d)":                                  "failed(%d)",
+	"序列化失败: %s":                               "serialization failed: %s",
+	"%s: 编号 %d 超出范围，共 %d 条记录":                 "%s: number %d is out of range, %d records",
+	"请提供一个记录编号或ID":                            "please provide a record number or ID",
+	"时间:     %s":                              "Time:     %s",
+	"目录:     %s":                              "Dir:      %s",
+	"来源:     从 %s 历史导入":                       "Source:   imported from %s history",
+	"需求:     %s":                              "Request:  %s",
+	"命令:     %s":                              "Command:  %s",
+	"原始命令: %s（已编辑）":                           "Original: %s (edited)",
+	"状态:     %s":                              "Status:   %s",
+	"耗时:     %s":                              "Duration: %s",
+	"风险等级: %s":                                "Risk:     %s",
+	"模型:     %s/%s":                           "Model:    %s/%s",
+	"审计:     %v，%s":                           "Audit:    %v, %s",
+	"标准输出:\n%s":                               "Stdout:\n%s",
+	"标准错误:\n%s":                               "Stderr:\n%s",
+	"加载配置失败: %s":                              "failed to load config: %s",
+	"已删除记录 %s: %s":                            "deleted record %s: %s",
+	"不询问直接清空":                                 "clear without asking",
+	"确定要清空全部历史记录吗? (y/n): ":                   "Clear all history? (y/n): ",
+	"已取消":                                     "cancelled",
+	"已清空历史记录":                                 "history cleared",
+	"不支持的格式 %s，可选: %s":                        "unsupported format %s, choose from: %s",
+	"历史格式：bash、zsh 或 fish，默认根据 $SHELL 判断":     "history format: bash, zsh or fish, detected from $SHELL by default",
+	"追加到该文件，默认输出到标准输出":                        "append to this file, defaults to standard output",
+	"追加到 shell 默认的历史文件":                       "append to the shell's default history file",
+	"只导出不早于该日期的记录":                            "only export records not before this date",
+	"--output 和 --histfile 不能同时使用":            "--output and --histfile cannot be used together",
+	"导出失败: %s":                                "export failed: %s",
+	"创建目录失败: %s":                              "failed to create directory: %s",
+	"打开历史文件失败: %s":                            "failed to open history file: %s",
+	"已将 %d 条命令追加到 %s":                         "appended %d commands to %s",
+	"在已打开的 %s 中执行 %s 即可加载":                    "in an already open %s, run %s to load them",
+	"历史文件，默认为 shell 的默认历史文件":                  "history file, defaults to the shell's default history file",
+	"最多导入最近的多少条不重复的命令":                        "maximum number of recent unique commands to import",
+	"只显示将要导入的命令":                              "only show the commands that would be imported",
+	"读取 %s 失败: %s":                            "failed to read %s: %s",
+	"将导入 %d 条命令；跳过疑似包含敏感信息的 %d 条、不适合作为示例的 %d 条、重复的 %d 条": "would import %d commands; skipping %d that may contain secrets, %d unsuitable as examples and %d duplicates",
+	"当前使用JSON存储，只保留最近 MAX_HISTORY_SIZE 条记录，较早的命令可能不会保留":  "history is stored as JSON and keeps only the latest MAX_HISTORY_SIZE records, older commands may be dropped",
+	"已从 %s 导入 %d 条命令（%d 条已存在）":                           "imported from %s: %d commands (%d already present)",
+	"跳过疑似包含敏感信息的 %d 条、不适合作为示例的 %d 条、重复的 %d 条":            "skipped %d that may contain secrets, %d unsuitable as examples and %d duplicates",
+
+	// 初始设置
+	"标准": "Standard",
+	"所有命令执行前都需要确认，禁止修改受保护的系统路径": "every command needs confirmation, changes to protected system paths are denied",
+	"严格": "Strict",
+	"在标准设置的基础上，静态规则无法确定风险时让模型复核": "like Standard, plus a model review when static rules cannot determine the risk",
+	"宽松": "Relaxed",
+	"只读命令自动执行，修改受保护路径时输入路径确认即可": "read-only commands run automatically, changes to protected paths are confirmed by typing the path",
+	"不连接模型，使用本地模拟服务测试配置":        "do not connect to the model, test the config against a local mock service",
+	"配置文件已存在时直接覆盖":              "overwrite an existing config file",
+	"配置文件路径":                    "config file path",
+	"Prompt2Cmd 初始设置，配置将写入 %s":  "Prompt2Cmd setup, the config will be written to %s",
+	"%s 已存在，是否覆盖?":              "%s already exists, overwrite?",
+	"已取消，配置文件没有修改":              "cancelled, the config file was not changed",
+	"1. 选择LLM提供商:":              "1. Choose an LLM provider:",
+	"（本地模型，不需要API密钥）":           "(local model, no API key needed)",
+	"请输入编号或名称":                  "Enter a number or name",
+	"API基础URL":                  "API base URL",
+	"模型名称":                      "Model name",
+	"测试失败: %s":                  "test failed: %s",
+	"仍然保存配置?":                   "Save the config anyway?",
+	"5. 选择安全设置:":                "5. Choose security settings:",
+	"%s：%s":                     "%s: %s",
+	"请输入编号":                     "Enter a number",
+	"无效的编号: %s":                 "invalid number: %s",
+	"改为明文写入配置文件?":               "Write it to the config file in plaintext instead?",
+	"可以稍后运行 prompt2cmd config set-key 保存，或在配置文件中设置 llm.api_key_command": "you can run prompt2cmd config set-key later, or set llm.api_key_command in the config file",
+	"配置已写入 %s": "config written to %s",
+	"运行 prompt2cmd 开始使用，prompt2cmd config show --origin 可以查看生效的配置": "run prompt2cmd to get started, prompt2cmd config show --origin shows the effective config",
+	"2. 选择API密钥的保存方式:":                       "2. Choose how to store the API key:",
+	"1. 系统密钥环（%s，推荐）":                        "1. System keyring (%s, recommended)",
+	"2. 凭据命令，例如 pass show %s":                "2. Credential command, e.g. pass show %s",
+	"3. 明文写入配置文件":                            "3. Plaintext in the config file",
+	"凭据命令":                                   "Credential command",
+	"3. 测试模型调用":                              "3. Test a model call",
+	"离线模式，使用本地模拟服务 %s":                       "offline mode, using the local mock service %s",
+	"正在连接 %s（模型 %s）...":                      "connecting to %s (model %s)...",
+	"显示当前所在的目录":                              "show the current directory",
+	"测试成功，模型生成的命令: %s（%s）":                   "test succeeded, the model generated: %s (%s)",
+	"离线模式的模拟响应":                              "mock response in offline mode",
+	"4. 选择执行命令使用的shell:":                     "4. Choose the shell used to run commands:",
+	"请输入编号或shell路径":                          "Enter a number or shell path",
+	"找不到shell: %s":                           "cannot find shell: %s",
+	"# prompt2cmd 配置文件，由 prompt2cmd init 生成": "# prompt2cmd config file, generated by prompt2cmd init",
+	"# prompt2cmd config show --origin 可以查看生效的配置及每一项的来源": "# prompt2cmd config show --origin shows the effective config and where each setting comes from",
+	"创建配置目录失败: %s": "failed to create config directory: %s",
+	"写入配置文件失败: %s": "failed to write config file: %s",
+
+	// 交互模式
+	"Prompt2Cmd v%s - 自然语言转终端命令工具": "Prompt2Cmd v%s - natural language to shell commands",
+	"输入 'exit' 或 'quit' 退出程序，'/history' 查看历史记录，'/rerun <编号>' 重新执行历史命令，'/save <名称>'、'/run <名称>' 保存和运行命令片段，'/reload' 重新加载配置": "Type 'exit' or 'quit' to leave, '/history' to view history, '/rerun <number>' to rerun a command, '/save <name>' and '/run <name>' to save and run snippets, '/reload' to reload the config",
+	"还没有配置文件，运行 prompt2cmd init 完成初始设置": "no config file yet, run prompt2cmd init to set up",
+	"使用配置档案 %s（%s，模型 %s）":               "using profile %s (%s, model %s)",
+	"再见!":         "Bye!",
+	"切换目录失败: %s":  "failed to change directory: %s",
+	"已切换到目录: %s":  "changed directory to: %s",
+	"本次使用模型 %s":   "using model %s for this request",
+	"当前模型: %s/%s": "current model: %s/%s",
+	"使用 /model <名称> 切换模型，/provider <名称> 切换提供商，或在需求前加上 @模型 只对这一条需求使用其他模型": "use /model <name> to switch models, /provider <name> to switch providers, or prefix a request with @model to use another model for that request only",
+	"用法: /model <名称>":                "usage: /model <name>",
+	"已切换到模型 %s/%s":                   "switched to model %s/%s",
+	"当前提供商: %s（模型 %s）":               "current provider: %s (model %s)",
+	"可用的提供商: %s":                     "available providers: %s",
+	"用法: /provider <名称> [模型]":        "usage: /provider <name> [model]",
+	"已切换到 %s/%s":                     "switched to %s/%s",
+	"切换失败，继续使用 %s/%s: %s":            "switch failed, still using %s/%s: %s",
+	"用法: /prompt show [%s]":          "usage: /prompt show [%s]",
+	"未知的提示词模板: %s（%s）":               "unknown prompt template: %s (%s)",
+	"内置模板":                           "built-in template",
+	"提示词模板 %s（%s）:":                  "prompt template %s (%s):",
+	"在 %s 中创建 %s.tmpl 可以覆盖内置模板":      "override the built-in template by creating a file in %s named %s.tmpl",
+	"忽略无效的团队配方: %s":                  "ignoring invalid team recipe: %s",
+	"找到经过团队审核的配方:":                   "found team-reviewed recipes:",
+	"使用该配方? (y/n，n 表示让模型生成): ":       "Use this recipe? (y/n, n lets the model generate): ",
+	"输入编号使用配方（1-%d），直接回车或 n 让模型生成: ": "Enter a number to use a recipe (1-%d), or press Enter or n to let the model generate: ",
+	"配方 %s 需要的程序不存在: %s":             "recipe %s requires missing programs: %s",
+	"团队配方 %s 声明的风险等级为%s":             "team recipe %s declares risk level %s",
+	"团队配方 %s：%s":                     "team recipe %s: %s",
+	"未知的 recipe 子命令: %s":             "unknown recipe subcommand: %s",
+	"%s 中没有团队配方":                     "no team recipes in %s",
+	"名称\t风险\t说明\t依赖":                 "Name\tRisk\tDescription\tRequires",
+	"（缺少 %s）":                        " (missing %s)",
+	"配方不存在: %s":                      "recipe not found: %s",
+	"名称:     %s":                     "Name:     %s",
+	"说明:     %s":                     "Desc:     %s",
+	"参数:     %s":                     "Params:   %s",
+	"依赖:     %s":                     "Requires: %s",
+	"关键词:   %s":                      "Keywords: %s",
+	"文件:     %s":                     "File:     %s",
+	"未设置 RECIPES_REPO，无法同步团队配方":      "RECIPES_REPO is not set, cannot sync team recipes",
+	"同步团队配方需要 git":                   "syncing team recipes requires git",
+	"正在更新 %s ...":                    "updating %s ...",
+	"%s 已存在且不是git仓库，请清空该目录或修改 RECIPES_DIR": "%s exists and is not a git repository, empty it or change RECIPES_DIR",
+	"创建配方目录失败: %s":                         "failed to create recipe directory: %s",
+	"正在克隆 %s 到 %s ...":                     "cloning %s into %s ...",
+	"同步团队配方失败: %s":                         "failed to sync team recipes: %s",
+	"检测到配置文件变化，正在重新加载...":                  "config file changed, reloading...",
+	"新的配置无效，继续使用原来的配置: %s":                 "the new config is invalid, keeping the previous config: %s",
+	"已重新加载配置，配置项没有变化":                      "config reloaded, no settings changed",
+	"已重新加载配置:":                             "config reloaded:",
+	"安全策略 %s: %d 条规则":                      "security policy %s: %d rules",
+	"历史记录存储方式的修改在重新启动后生效":                  "changes to the history backend take effect after a restart",
+	"%s 退出码 %d，按审计策略 %s 跳过模型审计":            "%s exit code %d, model audit skipped by audit policy %s",
+	"正在审计执行结果...":                          "auditing the result...",
+	"审计失败: %s":                             "audit failed: %s",
+	"%s 执行状态: %v":                          "%s success: %v",
+	"审计结果: %s":                             "audit result: %s",
+	"正在请模型复核命令风险...":                       "asking the model to review the command's risk...",
+	"风险复核失败，仅使用静态检查结果: %s":                 "risk review failed, using static checks only: %s",
+	"风险复核结果无效，仅使用静态检查结果: %s":               "risk review result is invalid, using static checks only: %s",
+	"可恢复":  "reversible",
+	"不可恢复": "irreversible",
+	"评估为「%s」（%s），影响范围: %s。%s": "assessed as \"%s\" (%s), blast radius: %s. %s",
+	"已在发送前脱敏 %d 处敏感信息: %s":    "redacted %d secrets before sending: %s",
+	"无效的数量: %s":               "invalid count: %s",
+	"用法: /rerun <编号|ID>":      "usage: /rerun <number|ID>",
+	"未知的指令: %s（可用指令: /history [数量]、/rerun <编号>、/save <名称>、/run <名称>、/snippets、/reload、/model [名称]、/provider [名称]、/prompt show [模板]）": "unknown directive: %s (available: /history [count], /rerun <number>, /save <name>, /run <name>, /snippets, /reload, /model [name], /provider [name], /prompt show [template])",
+	"无法获取历史记录: %s":          "cannot load history: %s",
+	"将继续生成命令，但不使用历史上下文":     "continuing without history context",
+	"正在生成命令...":             "generating command...",
+	"命令已被安全策略阻止":            "the command was blocked by the security policy",
+	"只读命令，已自动确认执行":          "read-only command, confirmed automatically",
+	"请编辑命令: ":               "Edit the command: ",
+	"命令已取消":                 "command cancelled",
+	"正在执行命令...":             "running command...",
+	"执行失败: %s":              "execution failed: %s",
+	"保存历史记录失败: %s":          "failed to save history: %s",
+	"用法: /save <名称> [命令模板]": "usage: /save <name> [command template]",
+	"还没有可以保存的命令":            "no command to save yet",
+	"最近的命令: %s":             "last command: %s",
+	"用 {{名称}} 或 {{名称:默认值}} 标记可变部分（直接回车保存原命令）: ": "Mark variable parts with {{name}} or {{name:default}} (press Enter to save the command as is): ",
+	"已保存":         "saved",
+	"已更新":         "updated",
+	"%s片段 %s: %s": "%s snippet %s: %s",
+	"参数: %s（使用 /run %s 名称=值 运行）": "parameters: %s (run with /run %s name=value)",
+	"用法: /run <名称> [参数名=值 ...]":  "usage: /run <name> [param=value ...]",
+	"运行片段 %s":                     "run snippet %s",
+	"来自片段 %s":                     "from snippet %s",
+	"请输入参数 %s: ":                  "Enter parameter %s: ",
+	"用法: /snippets [delete <名称>]": "usage: /snippets [delete <name>]",
+	"已删除片段 %s":                    "deleted snippet %s",
+	"还没有保存的片段，使用 /save <名称> 保存最近的命令": "no saved snippets yet, use /save <name> to save the last command",
+	"名称\t参数\t命令": "Name\tParams\tCommand",
+
+	// 用法
+	`Prompt2Cmd v%s - 自然语言转终端命令工具
+
+用法:
+  prompt2cmd [全局选项] [子命令]
+  prompt2cmd                       启动交互模式
+  prompt2cmd init [--offline]      运行初始设置向导，生成配置文件
+  prompt2cmd policy test "<命令>"   显示命令命中的安全策略规则和风险等级
+  prompt2cmd history <list|search|show|rerun|delete|clear|export|import>  浏览和管理历史记录
+  prompt2cmd recipe <list|show|sync>  查看和同步团队配方
+  prompt2cmd audit verify           校验命令审计日志是否被篡改
+  prompt2cmd config show [--origin]  显示生效的配置及每一项的来源
+  prompt2cmd config set-key         将API密钥保存到系统密钥环
+  prompt2cmd doctor [--offline]     检查配置、文件权限、模型接口、shell和历史记录
+  prompt2cmd version               显示版本
+  prompt2cmd help                  显示帮助
+
+全局选项:
+  --profile <名称>                 使用配置文件中的配置档案，也可以通过 PROMPT2CMD_PROFILE 环境变量指定
+  --provider <名称>                本次运行使用的LLM提供商
+  --model <名称>                   本次运行使用的模型
+`: `Prompt2Cmd v%s - natural language to shell commands
+
+Usage:
+  prompt2cmd [global options] [subcommand]
+  prompt2cmd                       start interactive mode
+  prompt2cmd init [--offline]      run the setup wizard and create a config file
+  prompt2cmd policy test "<command>"  show the security policy rules and risk level a command matches
+  prompt2cmd history <list|search|show|rerun|delete|clear|export|import>  browse and manage history
+  prompt2cmd recipe <list|show|sync>  view and sync team recipes
+  prompt2cmd audit verify           verify that the command audit log has not been tampered with
+  prompt2cmd config show [--origin]  show the effective config and where each setting comes from
+  prompt2cmd config set-key         save the API key to the system keyring
+  prompt2cmd doctor [--offline]     check config, file permissions, model API, shell and history
+  prompt2cmd version               show the version
+  prompt2cmd help                  show this help
+
+Global options:
+  --profile <name>                 use a profile from the config file, or set PROMPT2CMD_PROFILE
+  --provider <name>                LLM provider for this run
+  --model <name>                   model for this run
+`,
+	`用法:
+  prompt2cmd config show [--origin]              显示生效的配置，--origin 同时显示每一项来自哪个配置层
+  prompt2cmd config set-key [--provider <名称>]   将API密钥保存到系统密钥环，可以从管道读取密钥
+  prompt2cmd config delete-key [--provider <名称>] 从系统密钥环删除API密钥`: `Usage:
+  prompt2cmd config show [--origin]              show the effective config, --origin also shows which layer each setting comes from
+  prompt2cmd config set-key [--provider <name>]   save the API key to the system keyring, the key can be piped in
+  prompt2cmd config delete-key [--provider <name>] delete the API key from the system keyring`,
+	`用法:
+  prompt2cmd history list   [--limit N] [--offset N] [--json]
+  prompt2cmd history search <关键词> [--since 日期] [--until 日期] [--cwd 目录] [--success|--failed] [--limit N] [--offset N] [--json]
+  prompt2cmd history show   <编号|ID> [--json]
+  prompt2cmd history rerun  <编号|ID>
+  prompt2cmd history delete <编号|ID>
+  prompt2cmd history clear  [--yes]
+  prompt2cmd history export [--format bash|zsh|fish] [--output 文件|--histfile] [--since 日期]
+  prompt2cmd history import [--format bash|zsh|fish] [--file 文件] [--limit N] [--dry-run]
+
+编号是 list 输出中的 # 列，1 表示最近一条`: `Usage:
+  prompt2cmd history list   [--limit N] [--offset N] [--json]
+  prompt2cmd history search <keyword> [--since date] [--until date] [--cwd dir] [--success|--failed] [--limit N] [--offset N] [--json]
+  prompt2cmd history show   <number|ID> [--json]
+  prompt2cmd history rerun  <number|ID>
+  prompt2cmd history delete <number|ID>
+  prompt2cmd history clear  [--yes]
+  prompt2cmd history export [--format bash|zsh|fish] [--output file|--histfile] [--since date]
+  prompt2cmd history import [--format bash|zsh|fish] [--file file] [--limit N] [--dry-run]
+
+The number is the # column of list, 1 is the most recent record`,
+	`用法:
+  prompt2cmd init [--offline] [--force] [--file <路径>]
+
+选项:
+  --offline   不连接模型，使用本地模拟服务测试配置
+  --force     配置文件已存在时直接覆盖
+  --file      配置文件路径，默认为 ~/.config/prompt2cmd/config.yaml`: `Usage:
+  prompt2cmd init [--offline] [--force] [--file <path>]
+
+Options:
+  --offline   do not connect to the model, test the config against a local mock service
+  --force     overwrite an existing config file
+  --file      config file path, defaults to ~/.config/prompt2cmd/config.yaml`,
+	`用法:
+  prompt2cmd recipe list            列出团队配方，并检查配方文件是否有效
+  prompt2cmd recipe show <名称>     显示配方详情
+  prompt2cmd recipe sync            从 RECIPES_REPO 克隆或更新配方目录`: `Usage:
+  prompt2cmd recipe list            list team recipes and check that the recipe files are valid
+  prompt2cmd recipe show <name>     show recipe details
+  prompt2cmd recipe sync            clone or update the recipe directory from RECIPES_REPO`,
+}
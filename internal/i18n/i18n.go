@@ -0,0 +1,138 @@
+package i18n
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Language 界面语言
+type Language string
+
+const (
+	// Chinese 简体中文，也是消息原文使用的语言
+	Chinese Language = "zh"
+	// English 英文
+	English Language = "en"
+)
+
+// current 当前使用的语言，启动时按环境变量检测，加载配置后可能被配置项覆盖
+var current = initial()
+
+// catalogs 各语言的消息目录，以中文原文为键；中文不需要目录
+var catalogs = map[Language]map[string]string{
+	English: english,
+}
+
+// initial 返回启动时使用的语言，UI_LANGUAGE 环境变量优先，使加载配置之前的输出也使用指定的语言
+func initial() Language {
+	if lang, ok := parse(os.Getenv("UI_LANGUAGE")); ok {
+		return lang
+	}
+	return Detect()
+}
+
+// Detect 按 LC_ALL、LC_MESSAGES、LANG 的顺序检测语言，为 C、POSIX 的变量会被跳过
+// 都未设置或都是 C、POSIX 时使用中文，中文以外的语言都使用英文
+func Detect() Language {
+	for _, env := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		value := os.Getenv(env)
+		if value == "" {
+			continue
+		}
+		if lang, ok := fromLocale(value); ok {
+			return lang
+		}
+	}
+	return Chinese
+}
+
+// fromLocale 从 zh_CN.UTF-8、en_US 这样的 locale 中取出语言，C 和 POSIX 不表示任何语言
+func fromLocale(locale string) (Language, bool) {
+	code := strings.ToLower(locale)
+	if i := strings.IndexAny(code, "_.@-"); i >= 0 {
+		code = code[:i]
+	}
+	switch code {
+	case "", "c", "posix":
+		return "", false
+	case "zh":
+		return Chinese, true
+	default:
+		return English, true
+	}
+}
+
+// Parse 解析语言配置项：auto（按环境变量检测）、zh、en，也接受 zh_CN.UTF-8、en_US 等 locale
+func Parse(value string) (Language, error) {
+	lang, ok := parse(value)
+	if !ok {
+		return "", errors.New(T("UI_LANGUAGE必须是 auto、zh 或 en: %s", value))
+	}
+	return lang, nil
+}
+
+// parse 解析语言配置项，只接受中文和英文，不使用 T 以便在包初始化时调用
+func parse(value string) (Language, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" || strings.EqualFold(value, "auto") {
+		return Detect(), true
+	}
+	lang, ok := fromLocale(value)
+	if !ok || !strings.HasPrefix(strings.ToLower(value), string(lang)) {
+		return "", false
+	}
+	return lang, true
+}
+
+// SetLanguage 设置界面语言
+func SetLanguage(lang Language) {
+	if lang != "" {
+		current = lang
+	}
+}
+
+// Current 返回当前使用的语言
+func Current() Language {
+	return current
+}
+
+// Name 返回语言的名称，用于提示模型使用该语言回复
+func (l Language) Name() string {
+	if l == English {
+		return "English"
+	}
+	return "中文"
+}
+
+// T 翻译消息，msg 为中文原文，带有 args 时按 fmt.Sprintf 格式化
+func T(msg string, args ...any) string {
+	msg = Translate(msg)
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
+
+// Translate 翻译不含格式化动词的消息，用于常量等非字面量的消息，目录中没有对应的译文时返回原文
+func Translate(msg string) string {
+	if translated, ok := catalogs[current][msg]; ok {
+		return translated
+	}
+	return msg
+}
+
+// Error 创建一个在显示时才翻译消息的错误，用于包级别的错误变量
+// 包初始化时还没有加载配置，直接调用 T 会固定使用环境变量检测到的语言
+func Error(msg string) error {
+	return &translatedError{msg: msg}
+}
+
+type translatedError struct {
+	msg string
+}
+
+func (e *translatedError) Error() string {
+	return Translate(e.msg)
+}
@@ -0,0 +1,29 @@
+package i18n
+
+import "testing"
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		lcAll, lcMessages, lang string
+		want                    Language
+	}{
+		{"", "", "", Chinese},
+		{"", "", "en_US.UTF-8", English},
+		{"", "", "zh_CN.UTF-8", Chinese},
+		{"C", "", "en_US.UTF-8", English},
+		{"C.UTF-8", "", "en_GB.UTF-8", English},
+		{"POSIX", "C", "zh_TW.UTF-8", Chinese},
+		{"C", "C", "C", Chinese},
+		{"", "en_US.UTF-8", "zh_CN.UTF-8", English},
+		{"zh_CN.UTF-8", "en_US.UTF-8", "en_US.UTF-8", Chinese},
+	}
+
+	for _, tt := range tests {
+		t.Setenv("LC_ALL", tt.lcAll)
+		t.Setenv("LC_MESSAGES", tt.lcMessages)
+		t.Setenv("LANG", tt.lang)
+		if got := Detect(); got != tt.want {
+			t.Errorf("Detect() with LC_ALL=%q LC_MESSAGES=%q LANG=%q = %s, want %s", tt.lcAll, tt.lcMessages, tt.lang, got, tt.want)
+		}
+	}
+}
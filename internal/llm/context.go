@@ -0,0 +1,56 @@
+package llm
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/elecmonkey/prompt2cmd/internal/history"
+	"github.com/elecmonkey/prompt2cmd/internal/i18n"
+)
+
+// ShortenPath 将用户主目录下的路径显示为 ~ 形式，空路径显示为“未知路径”
+func ShortenPath(path string) string {
+	if path == "" {
+		return i18n.T("未知路径")
+	}
+	homeDir, err := os.UserHomeDir()
+	if err == nil && homeDir != "" && strings.HasPrefix(path, homeDir) {
+		return filepath.Join("~", strings.TrimPrefix(path, homeDir))
+	}
+	return path
+}
+
+// FormatUserTurn 构建用户消息，path 为执行命令时所在的目录
+func FormatUserTurn(path, prompt string) string {
+	return i18n.T("当前路径：%s\n用户需求：%s", ShortenPath(path), prompt)
+}
+
+// FormatHistoryTurn 将一条历史记录转换为多轮对话中的用户消息和助手回复
+// 使用记录当时的工作目录，并在回复中附上命令的执行结果
+// 从 shell 历史导入的记录没有需求，以“用户直接执行的命令”作为示例
+func FormatHistoryTurn(record history.HistoryRecord) (user string, assistant string) {
+	if record.Source != "" && record.Prompt == "" {
+		user = FormatUserTurn(record.Cwd, i18n.T("（用户曾在 %s 中直接执行下面的命令，仅作为使用习惯参考）", record.Source))
+		assistant = fmt.Sprintf("```\n%s\n```", record.Command)
+		return user, assistant
+	}
+	user = FormatUserTurn(record.Cwd, record.Prompt)
+	assistant = i18n.T("```\n%s\n```\n\n已生成上述命令，%s", record.Command, describeOutcome(record))
+	return user, assistant
+}
+
+// describeOutcome 描述历史记录中命令的执行结果
+func describeOutcome(record history.HistoryRecord) string {
+	switch {
+	case !record.Executed:
+		return i18n.T("未执行")
+	case record.ExitCode == nil:
+		return i18n.T("已执行，结果未知")
+	case *record.ExitCode == 0:
+		return i18n.T("执行成功")
+	default:
+		return i18n.T("执行失败（退出码 %d）", *record.ExitCode)
+	}
+}
@@ -0,0 +1,64 @@
+package llm
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/elecmonkey/prompt2cmd/internal/i18n"
+)
+
+// StatusError 模型接口返回了非 200 的状态码
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+// Error 返回错误说明
+func (e *StatusError) Error() string {
+	return i18n.T("API调用失败，状态码: %d, 响应: %s", e.StatusCode, strings.TrimSpace(e.Body))
+}
+
+// ListModels 调用 OpenAI 兼容的 /models 接口，返回提供商可用的模型名称
+// DeepSeek、Moonshot 和 Ollama 都提供该接口，可以在不消耗 token 的情况下检查地址和API密钥
+func ListModels(baseURL, apiKey string, timeout time.Duration) ([]string, error) {
+	req, err := http.NewRequest("GET", strings.TrimSuffix(baseURL, "/")+"/models", nil)
+	if err != nil {
+		return nil, errors.New(i18n.T("创建HTTP请求失败: %s", err.Error()))
+	}
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+
+	client := &http.Client{Timeout: timeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, errors.New(i18n.T("发送请求失败: %s", err.Error()))
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, errors.New(i18n.T("读取响应失败: %s", err.Error()))
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, &StatusError{StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	var response struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, errors.New(i18n.T("解析模型列表失败: %s", err.Error()))
+	}
+	models := make([]string, 0, len(response.Data))
+	for _, model := range response.Data {
+		models = append(models, model.ID)
+	}
+	return models, nil
+}
@@ -0,0 +1,48 @@
+package ollama
+
+import (
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/elecmonkey/prompt2cmd/internal/config"
+	"github.com/elecmonkey/prompt2cmd/internal/llm/deepseek"
+)
+
+// Provider 通过 Ollama 的 OpenAI 兼容接口使用本地模型
+// 请求格式与 DeepSeek 相同，不需要API密钥；服务运行在本机时发送前不脱敏
+type Provider struct {
+	*deepseek.Provider
+}
+
+// NewProvider 创建一个新的 Ollama 提供商
+func NewProvider(cfg *config.Config) *Provider {
+	return &Provider{
+		Provider: deepseek.NewProvider(cfg),
+	}
+}
+
+// IsLocal 返回是否为本地模型
+// 只有 base_url 指向回环地址或 unix 套接字时才算本地，远程的 Ollama 服务与其他远程模型一样处理
+func (p *Provider) IsLocal() bool {
+	return isLocalURL(p.BaseURL)
+}
+
+// isLocalURL 判断地址是否指向本机
+func isLocalURL(rawURL string) bool {
+	scheme, _, _ := strings.Cut(strings.ToLower(rawURL), "://")
+	if scheme == "unix" || strings.HasSuffix(scheme, "+unix") {
+		return true
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+
+	host := strings.ToLower(u.Hostname())
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,28 @@
+package ollama
+
+import "testing"
+
+func TestIsLocalURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"http://localhost:11434/v1", true},
+		{"http://LOCALHOST:11434/v1", true},
+		{"http://127.0.0.1:11434/v1", true},
+		{"http://127.1.2.3/v1", true},
+		{"http://[::1]:11434/v1", true},
+		{"unix:///run/ollama.sock", true},
+		{"http+unix://%2Frun%2Follama.sock/v1", true},
+		{"http://192.168.1.20:11434/v1", false},
+		{"https://ollama.example.com/v1", false},
+		{"http://localhost.example.com/v1", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := isLocalURL(tt.url); got != tt.want {
+			t.Errorf("isLocalURL(%q) = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package prompts
+
+import (
+	"embed"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/elecmonkey/prompt2cmd/internal/i18n"
+	"github.com/elecmonkey/prompt2cmd/internal/llm"
+	"github.com/elecmonkey/prompt2cmd/internal/xdg"
+)
+
+// 提示词模板的名称
+const (
+	// System 生成命令的系统提示词
+	System = "system"
+	// Audit 审计执行结果的系统提示词
+	Audit = "audit"
+	// Review 复核命令风险的系统提示词
+	Review = "review"
+)
+
+// Names 所有提示词模板的名称
+var Names = []string{System, Audit, Review}
+
+// defaults 内置模板，templates 下为中文模板，templates/en 下为英文模板
+//
+//go:embed templates/*.tmpl templates/en/*.tmpl
+var defaults embed.FS
+
+// knownTools 检测是否已安装的常用工具，告诉模型可以使用哪些工具
+var knownTools = []string{
+	"git", "rg", "fd", "fzf", "jq", "yq", "curl", "wget", "rsync", "tar", "zip",
+	"docker", "podman", "kubectl", "helm", "make", "go", "python3", "node", "npm",
+	"systemctl", "journalctl", "brew", "apt", "dnf", "pacman",
+}
+
+var (
+	detectOnce    sync.Once
+	detectedTools []string
+)
+
+// Data 模板中可以使用的变量
+type Data struct {
+	OS       string   // 操作系统，与 runtime.GOOS 相同
+	Shell    string   // 执行命令使用的shell
+	Cwd      string   // 当前工作路径，主目录显示为 ~
+	Tools    []string // 已安装的常用工具
+	Language string   // 模型回复使用的语言，与界面语言相同
+}
+
+// NewData 根据shell和当前工作路径创建模板变量
+func NewData(shell, cwd string) Data {
+	return Data{
+		OS:       runtime.GOOS,
+		Shell:    shell,
+		Cwd:      llm.ShortenPath(cwd),
+		Tools:    InstalledTools(),
+		Language: i18n.Current().Name(),
+	}
+}
+
+// InstalledTools 返回 PATH 中可以找到的常用工具，结果在进程内缓存
+func InstalledTools() []string {
+	detectOnce.Do(func() {
+		for _, tool := range knownTools {
+			if _, err := exec.LookPath(tool); err == nil {
+				detectedTools = append(detectedTools, tool)
+			}
+		}
+	})
+	return detectedTools
+}
+
+// Dir 返回用户提示词模板所在的目录 $XDG_CONFIG_HOME/prompt2cmd/prompts
+func Dir() string {
+	return filepath.Join(xdg.ConfigDir(), "prompts")
+}
+
+// Template 一个提示词模板
+type Template struct {
+	Name   string
+	Source string // 用户模板的文件路径，内置模板为空
+	Text   string
+}
+
+// Load 读取提示词模板，用户目录中存在 <名称>.tmpl 时优先使用，否则使用界面语言对应的内置模板
+// 每次调用都重新读取文件，修改模板后不需要重新启动
+func Load(name string) (*Template, error) {
+	path := filepath.Join(Dir(), name+".tmpl")
+	data, err := os.ReadFile(path)
+	if err == nil {
+		return &Template{Name: name, Source: path, Text: string(data)}, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, errors.New(i18n.T("读取提示词模板 %s 失败: %s", path, err.Error()))
+	}
+
+	data, err = defaults.ReadFile(builtinPath(name))
+	if err != nil {
+		return nil, errors.New(i18n.T("未知的提示词模板: %s", name))
+	}
+	return &Template{Name: name, Text: string(data)}, nil
+}
+
+// builtinPath 返回内置模板在 embed.FS 中的路径
+func builtinPath(name string) string {
+	if i18n.Current() == i18n.English {
+		return "templates/en/" + name + ".tmpl"
+	}
+	return "templates/" + name + ".tmpl"
+}
+
+// Render 使用模板变量渲染提示词
+func (t *Template) Render(data Data) (string, error) {
+	source := t.Source
+	if source == "" {
+		source = i18n.T("内置模板 %s", t.Name)
+	}
+	tmpl, err := template.New(t.Name).Funcs(template.FuncMap{"join": strings.Join}).Parse(t.Text)
+	if err != nil {
+		return "", errors.New(i18n.T("解析提示词模板 %s 失败: %s", source, err.Error()))
+	}
+	var builder strings.Builder
+	if err := tmpl.Execute(&builder, data); err != nil {
+		return "", errors.New(i18n.T("渲染提示词模板 %s 失败: %s", source, err.Error()))
+	}
+	return strings.TrimSpace(builder.String()), nil
+}
+
+// Render 读取并渲染指定名称的提示词模板
+func Render(name string, data Data) (string, error) {
+	tmpl, err := Load(name)
+	if err != nil {
+		return "", err
+	}
+	return tmpl.Render(data)
+}
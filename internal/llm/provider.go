@@ -10,6 +10,14 @@ type ExecutionAuditResult struct {
 	Description string `json:"description"`  // 对执行结果的解释
 }
 
+// RiskReview 模型对命令风险的复核结果
+type RiskReview struct {
+	Level       string `json:"level"`        // 风险等级：safe, caution, dangerous, critical
+	BlastRadius string `json:"blast_radius"` // 影响范围
+	Reversible  bool   `json:"reversible"`   // 操作是否可以恢复
+	Reason      string `json:"reason"`       // 判断理由
+}
+
 // Provider 定义了语言模型提供商的接口
 type Provider interface {
 	// GenerateCommand 根据提示和上下文生成命令和解释
@@ -27,4 +35,18 @@ type Provider interface {
 	
 	// IsLocal 返回是否为本地模型
 	IsLocal() bool
-} 
\ No newline at end of file
+}
+
+// PathFilterSetter 可选接口，支持在工作目录写入提示词之前对其进行处理
+type PathFilterSetter interface {
+	SetPathFilter(filter func(string) string)
+}
+
+// RiskReviewer 可选接口，支持让模型复核静态规则难以判断的命令风险
+type RiskReviewer interface {
+	// ReviewCommandRisk 根据工作目录和用户需求评估命令的影响范围和可恢复性
+	// command: 待执行的命令
+	// cwd: 当前工作目录
+	// prompt: 用户的原始需求
+	ReviewCommandRisk(command string, cwd string, prompt string) (*RiskReview, error)
+}
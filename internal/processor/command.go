@@ -1,10 +1,15 @@
 package processor
 
 import (
+	"bytes"
 	"errors"
 	"os/exec"
 	"runtime"
 	"strings"
+	"sync"
+	"time"
+
+	"github.com/elecmonkey/prompt2cmd/internal/i18n"
 )
 
 // CommandProcessor 命令处理器接口
@@ -14,18 +19,33 @@ type CommandProcessor interface {
 
 	// ExecuteCommand 执行命令，返回执行结果和可能的错误
 	ExecuteCommand(command string) (string, error)
+
+	// Execute 执行命令，返回包含退出码、耗时和分离输出的详细结果
+	Execute(command string) (*ExecutionResult, error)
+}
+
+// ExecutionResult 命令执行的详细结果
+type ExecutionResult struct {
+	Shell    string        // 执行命令使用的shell
+	Output   string        // 标准输出和标准错误按产生顺序合并的内容
+	Stdout   string        // 标准输出
+	Stderr   string        // 标准错误
+	ExitCode int           // 退出码，无法启动命令时为 -1
+	Duration time.Duration // 执行耗时
 }
 
 // OSCommandProcessor 操作系统命令处理器
 type OSCommandProcessor struct {
 	Platform string // windows, linux, darwin
 	UsePS    bool   // Windows下是否使用PowerShell
+	Shell    string // 执行命令使用的shell，默认为 sh
 }
 
 // NewOSCommandProcessor 创建一个新的操作系统命令处理器
 func NewOSCommandProcessor() *OSCommandProcessor {
 	processor := &OSCommandProcessor{
 		Platform: runtime.GOOS,
+		Shell:    "sh",
 	}
 
 	// Windows系统默认使用PowerShell
@@ -39,7 +59,7 @@ func NewOSCommandProcessor() *OSCommandProcessor {
 // ProcessCommand 根据操作系统处理命令
 func (p *OSCommandProcessor) ProcessCommand(command string) (string, error) {
 	if command == "" {
-		return "", errors.New("命令不能为空")
+		return "", errors.New(i18n.T("命令不能为空"))
 	}
 
 	// 命令处理基本上交给LLM完成，这里只做简单调整
@@ -48,8 +68,17 @@ func (p *OSCommandProcessor) ProcessCommand(command string) (string, error) {
 
 // ExecuteCommand 执行命令并返回结果
 func (p *OSCommandProcessor) ExecuteCommand(command string) (string, error) {
+	result, err := p.Execute(command)
+	if result == nil {
+		return "", err
+	}
+	return result.Output, err
+}
+
+// Execute 执行命令并返回详细结果
+func (p *OSCommandProcessor) Execute(command string) (*ExecutionResult, error) {
 	if command == "" {
-		return "", errors.New("命令不能为空")
+		return nil, errors.New(i18n.T("命令不能为空"))
 	}
 
 	// 根据平台选择合适的shell
@@ -64,16 +93,71 @@ func (p *OSCommandProcessor) ExecuteCommand(command string) (string, error) {
 	// 	}
 	// } else {
 	// Linux/macOS 使用标准shell
-	cmd = exec.Command("sh", "-c", command)
+	shell := p.Shell
+	if shell == "" {
+		shell = "sh"
+	}
+	cmd = exec.Command(shell, "-c", command)
 	// }
 
-	// 设置命令的输出
-	output, err := cmd.CombinedOutput()
+	// 设置命令的输出：分别保存标准输出和标准错误，同时按顺序合并
+	var stdout, stderr bytes.Buffer
+	combined := &lockedBuffer{}
+	cmd.Stdout = &teeWriter{buffer: &stdout, combined: combined}
+	cmd.Stderr = &teeWriter{buffer: &stderr, combined: combined}
+
+	start := time.Now()
+	err := cmd.Run()
+	result := &ExecutionResult{
+		Shell:    shell,
+		Output:   combined.String(),
+		Stdout:   stdout.String(),
+		Stderr:   stderr.String(),
+		Duration: time.Since(start),
+	}
+
 	if err != nil {
-		return string(output), err
+		result.ExitCode = -1
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			result.ExitCode = exitErr.ExitCode()
+		}
+		return result, err
 	}
 
-	return string(output), nil
+	return result, nil
+}
+
+// lockedBuffer 可被标准输出和标准错误并发写入的缓冲区
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+// Write 写入数据
+func (b *lockedBuffer) Write(data []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(data)
+}
+
+// String 返回已写入的内容
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// teeWriter 同时写入单独的缓冲区和合并的缓冲区
+type teeWriter struct {
+	buffer   *bytes.Buffer
+	combined *lockedBuffer
+}
+
+// Write 写入数据
+func (w *teeWriter) Write(data []byte) (int, error) {
+	w.buffer.Write(data)
+	return w.combined.Write(data)
 }
 
 // IsCommandSafe 检查命令是否安全（将在安全模块实现更详细的检查）
@@ -0,0 +1,167 @@
+package rank
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// BM25 参数，取常用的默认值
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// stopWords 检索时忽略的常见虚词
+var stopWords = map[string]bool{
+	"a": true, "an": true, "the": true, "on": true, "in": true, "of": true, "to": true, "for": true,
+	"and": true, "or": true, "with": true, "from": true, "at": true, "by": true, "is": true, "it": true,
+	"this": true, "that": true, "my": true, "me": true, "please": true,
+	"的": true, "了": true, "把": true, "在": true, "将": true, "和": true, "与": true, "给": true, "请": true, "我": true,
+}
+
+// Tokenize 将文本切分为用于检索的词
+// 英文、数字按单词切分并转为小写；中文等没有空格分词的文字按相邻两个字切分（单字时保留单字）；
+// 常见的虚词会被忽略
+func Tokenize(text string) []string {
+	var tokens []string
+	var word []rune
+	var cjk []rune
+
+	flushWord := func() {
+		if len(word) > 0 {
+			if token := strings.ToLower(string(word)); !stopWords[token] {
+				tokens = append(tokens, token)
+			}
+			word = word[:0]
+		}
+	}
+	flushCJK := func() {
+		switch {
+		case len(cjk) == 1:
+			if !stopWords[string(cjk)] {
+				tokens = append(tokens, string(cjk))
+			}
+		case len(cjk) > 1:
+			for i := 0; i+1 < len(cjk); i++ {
+				tokens = append(tokens, string(cjk[i:i+2]))
+			}
+		}
+		cjk = cjk[:0]
+	}
+
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r) || unicode.Is(unicode.Hangul, r):
+			flushWord()
+			cjk = append(cjk, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_':
+			flushCJK()
+			word = append(word, r)
+		default:
+			flushWord()
+			flushCJK()
+		}
+	}
+	flushWord()
+	flushCJK()
+	return tokens
+}
+
+// BM25 基于 Okapi BM25 的文档相关度评分
+type BM25 struct {
+	docs      []map[string]int // 每个文档的词频
+	lengths   []int            // 每个文档的词数
+	avgLength float64
+	docFreq   map[string]int // 包含某个词的文档数
+}
+
+// NewBM25 为一组已分词的文档建立索引
+func NewBM25(docs [][]string) *BM25 {
+	index := &BM25{
+		docs:    make([]map[string]int, len(docs)),
+		lengths: make([]int, len(docs)),
+		docFreq: make(map[string]int),
+	}
+
+	total := 0
+	for i, doc := range docs {
+		freq := make(map[string]int)
+		for _, token := range doc {
+			freq[token]++
+		}
+		for token := range freq {
+			index.docFreq[token]++
+		}
+		index.docs[i] = freq
+		index.lengths[i] = len(doc)
+		total += len(doc)
+	}
+	if len(docs) > 0 {
+		index.avgLength = float64(total) / float64(len(docs))
+	}
+	return index
+}
+
+// Score 计算查询与第 i 个文档的相关度，不相关时为 0
+func (b *BM25) Score(query []string, i int) float64 {
+	if i < 0 || i >= len(b.docs) || b.avgLength == 0 {
+		return 0
+	}
+
+	n := float64(len(b.docs))
+	lengthNorm := 1 - bm25B + bm25B*float64(b.lengths[i])/b.avgLength
+	score := 0.0
+	seen := make(map[string]bool)
+	for _, token := range query {
+		if seen[token] {
+			continue
+		}
+		seen[token] = true
+
+		tf := float64(b.docs[i][token])
+		if tf == 0 {
+			continue
+		}
+		df := float64(b.docFreq[token])
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+		score += idf * tf * (bm25K1 + 1) / (tf + bm25K1*lengthNorm)
+	}
+	return score
+}
+
+// Coverage 返回查询中出现在第 i 个文档里的词所占的比例，按 IDF 加权，取值 0 到 1
+// 与 BM25 分数不同，覆盖率不受文档长度影响，适合用阈值判断是否匹配。
+// 计算 IDF 时把查询本身也算作一个文档，避免文档很少时未出现的词权重过高
+func (b *BM25) Coverage(query []string, i int) float64 {
+	if i < 0 || i >= len(b.docs) {
+		return 0
+	}
+
+	n := float64(len(b.docs)) + 1
+	total := 0.0
+	matched := 0.0
+	seen := make(map[string]bool)
+	for _, token := range query {
+		if seen[token] {
+			continue
+		}
+		seen[token] = true
+
+		df := float64(b.docFreq[token]) + 1
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+		total += idf
+		if b.docs[i][token] > 0 {
+			matched += idf
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return matched / total
+}
+
+// Len 返回文档数量
+func (b *BM25) Len() int {
+	return len(b.docs)
+}
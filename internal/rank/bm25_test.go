@@ -0,0 +1,76 @@
+package rank
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"List the Files in /var/log", []string{"list", "files", "var", "log"}},
+		{"docker_compose up -d", []string{"docker_compose", "up", "d"}},
+		{"查找大文件", []string{"查找", "找大", "大文", "文件"}},
+		{"删除 log 文件", []string{"删除", "log", "文件"}},
+		{"删 log", []string{"删", "log"}},
+		{"把日志压缩", []string{"把日", "日志", "志压", "压缩"}},
+		{"我 的", nil},
+	}
+	for _, tt := range tests {
+		if got := Tokenize(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Tokenize(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestBM25Score(t *testing.T) {
+	docs := [][]string{
+		Tokenize("list files ls -la"),
+		Tokenize("find large files find . -size +100M"),
+		Tokenize("compress logs tar czf logs.tgz logs"),
+		Tokenize("查找大文件 find . -size +100M"),
+	}
+	index := NewBM25(docs)
+	if index.Len() != len(docs) {
+		t.Fatalf("Len = %d, want %d", index.Len(), len(docs))
+	}
+
+	query := Tokenize("find large files")
+	if index.Score(query, 1) <= index.Score(query, 0) {
+		t.Errorf("Score(find large files) ranks %q below %q", docs[1], docs[0])
+	}
+	if score := index.Score(query, 2); score != 0 {
+		t.Errorf("Score of an unrelated document = %v, want 0", score)
+	}
+	if index.Score(Tokenize("大文件"), 3) <= 0 {
+		t.Errorf("Score(大文件) = 0, want a match on Chinese bigrams")
+	}
+	if index.Score(query, -1) != 0 || index.Score(query, len(docs)) != 0 {
+		t.Errorf("Score out of range is not 0")
+	}
+	if NewBM25(nil).Score(query, 0) != 0 {
+		t.Errorf("Score on an empty index is not 0")
+	}
+}
+
+func TestBM25Coverage(t *testing.T) {
+	index := NewBM25([][]string{
+		Tokenize("list files"),
+		Tokenize("compress logs"),
+	})
+
+	if got := index.Coverage(Tokenize("list files"), 0); got != 1 {
+		t.Errorf("Coverage of a full match = %v, want 1", got)
+	}
+	if got := index.Coverage(Tokenize("list files"), 1); got != 0 {
+		t.Errorf("Coverage of no match = %v, want 0", got)
+	}
+	if got := index.Coverage(Tokenize("list logs"), 0); got <= 0 || got >= 1 {
+		t.Errorf("Coverage of a partial match = %v, want between 0 and 1", got)
+	}
+	if got := index.Coverage(nil, 0); got != 0 {
+		t.Errorf("Coverage of an empty query = %v, want 0", got)
+	}
+}
@@ -0,0 +1,127 @@
+package redact
+
+import (
+	"errors"
+
+	"github.com/elecmonkey/prompt2cmd/internal/history"
+	"github.com/elecmonkey/prompt2cmd/internal/i18n"
+	"github.com/elecmonkey/prompt2cmd/internal/llm"
+)
+
+// Provider 在请求发送给远程LLM之前对敏感信息脱敏的提供商包装
+// 返回结果中的占位符会被还原为原文，因此生成的命令仍然可以直接执行
+type Provider struct {
+	inner    llm.Provider
+	redactor *Redactor
+	// OnRedact 每次请求脱敏后调用，stage 为 "generate"、"audit" 或 "review"
+	OnRedact func(stage string, report Report)
+	// pathReport 内部提供商写入提示词的工作目录的脱敏统计
+	pathReport Report
+}
+
+// NewProvider 创建一个脱敏包装，inner 为实际的LLM提供商
+// 内部提供商支持 llm.PathFilterSetter 时，写入提示词的工作目录也会脱敏
+func NewProvider(inner llm.Provider) *Provider {
+	p := &Provider{
+		inner:    inner,
+		redactor: NewRedactor(),
+	}
+	if setter, ok := inner.(llm.PathFilterSetter); ok {
+		setter.SetPathFilter(p.redactPath)
+	}
+	return p
+}
+
+// redactPath 脱敏内部提供商写入提示词的工作目录，与历史记录中的目录保持一致
+func (p *Provider) redactPath(path string) string {
+	redacted, report := p.redactor.Redact(path)
+	p.pathReport.Merge(report)
+	return redacted
+}
+
+// IsLocal 返回是否为本地模型
+func (p *Provider) IsLocal() bool {
+	return p.inner.IsLocal()
+}
+
+// GenerateCommand 脱敏提示和历史记录后生成命令
+func (p *Provider) GenerateCommand(prompt string, historyRecords []history.HistoryRecord) (string, string, error) {
+	var report Report
+
+	redactedPrompt, r := p.redactor.Redact(prompt)
+	report.Merge(r)
+
+	redactedRecords := make([]history.HistoryRecord, len(historyRecords))
+	for i, record := range historyRecords {
+		record.Prompt, r = p.redactor.Redact(record.Prompt)
+		report.Merge(r)
+		record.Command, r = p.redactor.Redact(record.Command)
+		report.Merge(r)
+		record.Cwd, r = p.redactor.Redact(record.Cwd)
+		report.Merge(r)
+		redactedRecords[i] = record
+	}
+
+	command, explanation, err := p.inner.GenerateCommand(redactedPrompt, redactedRecords)
+	p.notify("generate", report)
+	if err != nil {
+		return "", "", err
+	}
+	return p.redactor.Restore(command), p.redactor.Restore(explanation), nil
+}
+
+// AuditExecutionResult 脱敏命令、输出和需求后审计执行结果
+func (p *Provider) AuditExecutionResult(command string, result string, prompt string) (*llm.ExecutionAuditResult, error) {
+	var report Report
+
+	redactedCommand, r := p.redactor.Redact(command)
+	report.Merge(r)
+	redactedResult, r := p.redactor.Redact(result)
+	report.Merge(r)
+	redactedPrompt, r := p.redactor.Redact(prompt)
+	report.Merge(r)
+
+	auditResult, err := p.inner.AuditExecutionResult(redactedCommand, redactedResult, redactedPrompt)
+	p.notify("audit", report)
+	if err != nil {
+		return nil, err
+	}
+	// 审计说明只在本地显示，还原占位符方便用户阅读
+	auditResult.Description = p.redactor.Restore(auditResult.Description)
+	return auditResult, nil
+}
+
+// ReviewCommandRisk 脱敏命令和需求后让模型复核风险
+// 内部提供商不支持风险复核时返回错误
+func (p *Provider) ReviewCommandRisk(command string, cwd string, prompt string) (*llm.RiskReview, error) {
+	reviewer, ok := p.inner.(llm.RiskReviewer)
+	if !ok {
+		return nil, errors.New(i18n.T("当前LLM提供商不支持风险复核"))
+	}
+
+	var report Report
+	redactedCommand, r := p.redactor.Redact(command)
+	report.Merge(r)
+	redactedPrompt, r := p.redactor.Redact(prompt)
+	report.Merge(r)
+	redactedCwd, r := p.redactor.Redact(cwd)
+	report.Merge(r)
+
+	review, err := reviewer.ReviewCommandRisk(redactedCommand, redactedCwd, redactedPrompt)
+	p.notify("review", report)
+	if err != nil {
+		return nil, err
+	}
+	review.BlastRadius = p.redactor.Restore(review.BlastRadius)
+	review.Reason = p.redactor.Restore(review.Reason)
+	return review, nil
+}
+
+// notify 有脱敏内容时通知调用方，同时计入请求过程中工作目录的脱敏
+func (p *Provider) notify(stage string, report Report) {
+	report.Merge(p.pathReport)
+	p.pathReport = Report{}
+	if p.OnRedact != nil && report.Total() > 0 {
+		p.OnRedact(stage, report)
+	}
+}
@@ -0,0 +1,304 @@
+package redact
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/elecmonkey/prompt2cmd/internal/i18n"
+)
+
+// detector 一种敏感信息的检测规则
+type detector struct {
+	Kind    string         // 类型标识，用于占位符
+	Label   string         // 显示名称
+	Pattern *regexp.Regexp // 匹配规则
+	// Group 需要脱敏的子匹配序号，0 表示整个匹配
+	Group int
+}
+
+// 检测规则按顺序执行，先匹配结构明确的格式，最后才做高熵字符串检测
+var detectors = []detector{
+	{
+		Kind:    "private-key",
+		Label:   "私钥",
+		Pattern: regexp.MustCompile(`-----BEGIN [A-Z0-9 ]*PRIVATE KEY( BLOCK)?-----[\s\S]*?-----END [A-Z0-9 ]*PRIVATE KEY( BLOCK)?-----`),
+	},
+	{
+		Kind:    "aws-access-key",
+		Label:   "AWS访问密钥",
+		Pattern: regexp.MustCompile(`\b(AKIA|ASIA|AGPA|AIDA|AROA|ANPA)[0-9A-Z]{16}\b`),
+	},
+	{
+		Kind:    "jwt",
+		Label:   "JWT",
+		Pattern: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{5,}\.eyJ[A-Za-z0-9_-]{5,}\.[A-Za-z0-9_-]{10,}`),
+	},
+	{
+		Kind:    "github-token",
+		Label:   "GitHub令牌",
+		Pattern: regexp.MustCompile(`\b(gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`),
+	},
+	{
+		Kind:    "slack-token",
+		Label:   "Slack令牌",
+		Pattern: regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`),
+	},
+	{
+		Kind:    "api-key",
+		Label:   "API密钥",
+		Pattern: regexp.MustCompile(`\bsk-[A-Za-z0-9_-]{20,}`),
+	},
+	{
+		Kind:    "bearer-token",
+		Label:   "Bearer令牌",
+		Pattern: regexp.MustCompile(`(?i)\bbearer\s+([A-Za-z0-9._~+/-]{20,}=*)`),
+		Group:   1,
+	},
+	{
+		Kind:    "url-credential",
+		Label:   "URL中的密码",
+		Pattern: regexp.MustCompile(`[a-zA-Z][a-zA-Z0-9+.-]*://[^\s:/@]+:([^\s@/]+)@`),
+		Group:   1,
+	},
+	{
+		Kind:  "env-secret",
+		Label: "配置中的密钥",
+		// 形如 DB_PASSWORD=xxx、api_key: xxx、"token": "xxx" 的赋值
+		Pattern: regexp.MustCompile(`(?i)\b[A-Z0-9_.-]*(PASSWORD|PASSWD|SECRET|TOKEN|API_?KEY|ACCESS_?KEY|PRIVATE_?KEY|CREDENTIALS?)[A-Z0-9_.-]*["']?\s*[=:]\s*["']?([^\s"',;]{4,})`),
+		Group:   2,
+	},
+}
+
+// 高熵字符串的候选：足够长的类base64/十六进制串
+var highEntropyCandidate = regexp.MustCompile(`[A-Za-z0-9+/_=-]{24,}`)
+
+const highEntropyKind = "high-entropy"
+
+// placeholderPattern 匹配已经生成的占位符，后续规则不能再改写其中的内容
+var placeholderPattern = regexp.MustCompile(`\[REDACTED:[a-z-]+#[0-9]+\]`)
+
+// Report 一次脱敏的统计
+type Report struct {
+	Counts map[string]int // 各类型的脱敏次数
+}
+
+// Total 返回脱敏总数
+func (r Report) Total() int {
+	total := 0
+	for _, count := range r.Counts {
+		total += count
+	}
+	return total
+}
+
+// Merge 合并另一次脱敏的统计
+func (r *Report) Merge(other Report) {
+	if r.Counts == nil {
+		r.Counts = make(map[string]int)
+	}
+	for kind, count := range other.Counts {
+		r.Counts[kind] += count
+	}
+}
+
+// String 返回统计的显示文本，例如 "JWT×1, 私钥×2"
+func (r Report) String() string {
+	kinds := make([]string, 0, len(r.Counts))
+	for kind := range r.Counts {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+
+	parts := make([]string, 0, len(kinds))
+	for _, kind := range kinds {
+		parts = append(parts, fmt.Sprintf("%s×%d", kindLabel(kind), r.Counts[kind]))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// kindLabel 返回类型的显示名称
+func kindLabel(kind string) string {
+	if kind == highEntropyKind {
+		return i18n.T("高熵字符串")
+	}
+	for _, d := range detectors {
+		if d.Kind == kind {
+			return i18n.Translate(d.Label)
+		}
+	}
+	return kind
+}
+
+// Redactor 敏感信息脱敏器
+// 同一个脱敏器会为相同的原文生成相同的占位符，并可以把占位符还原为原文
+type Redactor struct {
+	secrets      map[string]string // 占位符 -> 原文
+	placeholders map[string]string // 原文 -> 占位符
+	counter      int
+}
+
+// NewRedactor 创建一个新的脱敏器
+func NewRedactor() *Redactor {
+	return &Redactor{
+		secrets:      make(map[string]string),
+		placeholders: make(map[string]string),
+	}
+}
+
+// Redact 将文本中的敏感信息替换为占位符
+func (r *Redactor) Redact(text string) (string, Report) {
+	report := Report{Counts: make(map[string]int)}
+	if text == "" {
+		return text, report
+	}
+
+	for _, d := range detectors {
+		d := d
+		text = replaceGroup(d.Pattern, text, d.Group, func(secret string) string {
+			report.Counts[d.Kind]++
+			return r.placeholder(d.Kind, secret)
+		})
+	}
+
+	text = highEntropyCandidate.ReplaceAllStringFunc(text, func(candidate string) string {
+		// 以 / 开头的通常是文件路径
+		if strings.HasPrefix(candidate, "/") || !isHighEntropy(candidate) {
+			return candidate
+		}
+		report.Counts[highEntropyKind]++
+		return r.placeholder(highEntropyKind, candidate)
+	})
+
+	return text, report
+}
+
+// Restore 将文本中的占位符还原为原文
+func (r *Redactor) Restore(text string) string {
+	for placeholder, secret := range r.secrets {
+		text = strings.ReplaceAll(text, placeholder, secret)
+	}
+	return text
+}
+
+// placeholder 为原文生成占位符
+func (r *Redactor) placeholder(kind, secret string) string {
+	if placeholder, ok := r.placeholders[secret]; ok {
+		return placeholder
+	}
+	r.counter++
+	placeholder := fmt.Sprintf("[REDACTED:%s#%d]", kind, r.counter)
+	r.placeholders[secret] = placeholder
+	r.secrets[placeholder] = secret
+	return placeholder
+}
+
+// replaceGroup 替换正则的某个子匹配，group 为 0 时替换整个匹配
+// 与已有占位符重叠的子匹配保持不变
+func replaceGroup(re *regexp.Regexp, text string, group int, replace func(string) string) string {
+	matches := re.FindAllStringSubmatchIndex(text, -1)
+	if len(matches) == 0 {
+		return text
+	}
+	placeholders := placeholderPattern.FindAllStringIndex(text, -1)
+
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		start, end := m[2*group], m[2*group+1]
+		if start < 0 || overlaps(placeholders, start, end) {
+			continue
+		}
+		b.WriteString(text[last:start])
+		b.WriteString(replace(text[start:end]))
+		last = end
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// overlaps 判断区间 [start, end) 是否与任一区间重叠
+func overlaps(spans [][]int, start, end int) bool {
+	for _, span := range spans {
+		if start < span[1] && span[0] < end {
+			return true
+		}
+	}
+	return false
+}
+
+// isHighEntropy 判断字符串是否像随机生成的密钥
+// 纯十六进制串（如git提交哈希）和不含数字的单词不视为密钥
+func isHighEntropy(s string) bool {
+	hasDigit, hasLetter, hexOnly := false, false, true
+	for _, ch := range s {
+		switch {
+		case ch >= '0' && ch <= '9':
+			hasDigit = true
+		case ch >= 'a' && ch <= 'f', ch >= 'A' && ch <= 'F':
+			hasLetter = true
+		case ch >= 'g' && ch <= 'z', ch >= 'G' && ch <= 'Z':
+			hasLetter = true
+			hexOnly = false
+		default:
+			hexOnly = false
+		}
+	}
+	if !hasDigit || !hasLetter || hexOnly {
+		return false
+	}
+	return shannonEntropy(s) >= 4.3
+}
+
+// shannonEntropy 计算字符串每个字符的香农熵（比特）
+func shannonEntropy(s string) float64 {
+	freq := make(map[rune]int)
+	for _, ch := range s {
+		freq[ch]++
+	}
+	length := float64(len([]rune(s)))
+	entropy := 0.0
+	for _, count := range freq {
+		p := float64(count) / length
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// commandSecretPatterns 命令行中常见的明文密码参数，检测规则无法识别较短的密码
+var commandSecretPatterns = []*regexp.Regexp{
+	// --password=xxx、--token xxx、--api-key xxx 等
+	regexp.MustCompile(`(?i)--?[a-z0-9-]*(password|passwd|passphrase|secret|token|api-?key|access-?key)(=|\s+)\S+`),
+	// mysql -pxxx
+	regexp.MustCompile(`\bmysql(dump|admin)?\b.*\s-p\S+`),
+	// curl -u user:pass
+	regexp.MustCompile(`\bcurl\b.*\s(-u|--user)\s+\S+:\S+`),
+	// sshpass -p xxx、htpasswd -b file user pass
+	regexp.MustCompile(`\bsshpass\s+-p\s*\S+`),
+	regexp.MustCompile(`\bhtpasswd\s+-\w*b`),
+	// 请求头中的 Authorization: xxx
+	regexp.MustCompile(`(?i)authorization:\s*\S+`),
+}
+
+// LooksSensitive 判断命令中是否可能包含密钥、密码等敏感信息
+// 除了脱敏使用的检测规则，还会识别命令行中常见的明文密码参数
+func LooksSensitive(command string) bool {
+	for _, d := range detectors {
+		if d.Pattern.MatchString(command) {
+			return true
+		}
+	}
+	for _, candidate := range highEntropyCandidate.FindAllString(command, -1) {
+		if !strings.HasPrefix(candidate, "/") && isHighEntropy(candidate) {
+			return true
+		}
+	}
+	for _, pattern := range commandSecretPatterns {
+		if pattern.MatchString(command) {
+			return true
+		}
+	}
+	return false
+}
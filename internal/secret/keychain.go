@@ -0,0 +1,66 @@
+package secret
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/elecmonkey/prompt2cmd/internal/i18n"
+)
+
+// keychainNotFound security 命令在找不到条目时的退出码
+const keychainNotFound = 44
+
+// Keychain 通过 security 命令访问 macOS 钥匙串
+type Keychain struct{}
+
+// Name 返回密钥环的名称
+func (k *Keychain) Name() string {
+	return i18n.T("macOS 钥匙串")
+}
+
+// Get 读取账户对应的密钥
+func (k *Keychain) Get(account string) (string, error) {
+	output, err := exec.Command("security", "find-generic-password", "-s", Service, "-a", account, "-w").Output()
+	if err != nil {
+		return "", keychainError(err)
+	}
+	return strings.TrimRight(string(output), "\n"), nil
+}
+
+// Set 保存账户对应的密钥，已存在时覆盖
+// 命令通过 security -i 的标准输入传递，密钥不会出现在进程列表中
+func (k *Keychain) Set(account, value string) error {
+	cmd := exec.Command("security", "-i")
+	cmd.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -U -s %s -a %s -w %s\n", quote(Service), quote(account), quote(value)))
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return errors.New(i18n.T("保存密钥失败: %s %s", err.Error(), strings.TrimSpace(string(output))))
+	}
+	return nil
+}
+
+// Delete 删除账户对应的密钥
+func (k *Keychain) Delete(account string) error {
+	if err := exec.Command("security", "delete-generic-password", "-s", Service, "-a", account).Run(); err != nil {
+		return keychainError(err)
+	}
+	return nil
+}
+
+// keychainError 转换 security 命令的错误
+func keychainError(err error) error {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == keychainNotFound {
+		return ErrNotFound
+	}
+	if errors.Is(err, exec.ErrNotFound) {
+		return ErrUnsupported
+	}
+	return errors.New(i18n.T("访问钥匙串失败: %s", err.Error()))
+}
+
+// quote 为 security -i 的参数加上单引号
+func quote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'"'"'`) + "'"
+}
@@ -0,0 +1,89 @@
+package secret
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/elecmonkey/prompt2cmd/internal/i18n"
+)
+
+// Service 在系统密钥环中保存密钥时使用的服务名
+const Service = "prompt2cmd"
+
+// helperTimeout 凭据命令的超时时间，留出输入主密码或解锁的时间
+const helperTimeout = 2 * time.Minute
+
+// ErrNotFound 密钥环中没有对应的密钥
+var ErrNotFound = i18n.Error("密钥环中没有对应的密钥")
+
+// ErrUnsupported 当前系统没有可用的密钥环
+var ErrUnsupported = i18n.Error("当前系统不支持系统密钥环")
+
+// Keyring 系统密钥环，按账户名保存 prompt2cmd 的密钥
+type Keyring interface {
+	// Name 返回密钥环的名称
+	Name() string
+	// Get 读取密钥，不存在时返回 ErrNotFound
+	Get(account string) (string, error)
+	// Set 保存密钥，已存在时覆盖
+	Set(account, secret string) error
+	// Delete 删除密钥，不存在时返回 ErrNotFound
+	Delete(account string) error
+}
+
+// DefaultKeyring 返回当前系统的密钥环
+// macOS 使用钥匙串，Windows 暂不支持，其他系统使用 freedesktop Secret Service（GNOME Keyring、KWallet 等）
+func DefaultKeyring() Keyring {
+	switch runtime.GOOS {
+	case "darwin":
+		return &Keychain{}
+	case "windows":
+		return unsupportedKeyring{}
+	default:
+		return &SecretService{}
+	}
+}
+
+// unsupportedKeyring 不支持密钥环的系统
+type unsupportedKeyring struct{}
+
+func (unsupportedKeyring) Name() string               { return i18n.T("无") }
+func (unsupportedKeyring) Get(string) (string, error) { return "", ErrUnsupported }
+func (unsupportedKeyring) Set(string, string) error   { return ErrUnsupported }
+func (unsupportedKeyring) Delete(string) error        { return ErrUnsupported }
+
+// RunHelper 运行凭据命令（例如 pass show deepseek 或 op read op://...），返回其输出的第一行
+// 命令通过 shell 执行，可以使用终端输入主密码；标准错误直接显示给用户
+func RunHelper(command string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), helperTimeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	cmd.Stdin = os.Stdin
+	cmd.Stderr = os.Stderr
+	output, err := cmd.Output()
+	if ctx.Err() == context.DeadlineExceeded {
+		return "", errors.New(i18n.T("凭据命令超过 %s 没有完成", helperTimeout))
+	}
+	if err != nil {
+		return "", err
+	}
+
+	// pass 等工具约定第一行是密码，后面的行是附加信息
+	line, _, _ := strings.Cut(string(output), "\n")
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return "", errors.New(i18n.T("凭据命令没有输出密钥"))
+	}
+	return line, nil
+}
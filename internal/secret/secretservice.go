@@ -0,0 +1,315 @@
+package secret
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+
+	"github.com/elecmonkey/prompt2cmd/internal/i18n"
+)
+
+// freedesktop Secret Service 的 D-Bus 接口
+const (
+	secretsDest       = "org.freedesktop.secrets"
+	secretsPath       = dbus.ObjectPath("/org/freedesktop/secrets")
+	serviceInterface  = "org.freedesktop.Secret.Service"
+	collectionIface   = "org.freedesktop.Secret.Collection"
+	itemInterface     = "org.freedesktop.Secret.Item"
+	sessionInterface  = "org.freedesktop.Secret.Session"
+	promptInterface   = "org.freedesktop.Secret.Prompt"
+	defaultCollection = dbus.ObjectPath("/org/freedesktop/secrets/aliases/default")
+	noPrompt          = dbus.ObjectPath("/")
+)
+
+// promptTimeout 等待用户在解锁对话框中输入密码的时间
+const promptTimeout = 2 * time.Minute
+
+// secretValue Secret Service 中的密钥结构 (oayays)
+type secretValue struct {
+	Session     dbus.ObjectPath
+	Parameters  []byte
+	Value       []byte
+	ContentType string
+}
+
+// secretBus Secret Service 的 D-Bus 调用
+// 默认实现通过会话总线调用真实的服务，测试时替换为内存中的实现
+type secretBus interface {
+	// OpenSession 使用 plain 算法打开会话，密钥只在本机的会话总线上传输
+	OpenSession() (dbus.ObjectPath, error)
+	// CloseSession 关闭会话
+	CloseSession(session dbus.ObjectPath)
+	// SearchItems 按属性查找条目，分别返回已解锁和锁定的条目
+	SearchItems(attributes map[string]string) (unlocked, locked []dbus.ObjectPath, err error)
+	// Unlock 解锁条目或集合，需要用户确认时返回确认对话框的路径
+	Unlock(objects []dbus.ObjectPath) (prompt dbus.ObjectPath, err error)
+	// Prompt 显示确认对话框并等待结果，返回用户是否取消
+	Prompt(prompt dbus.ObjectPath) (dismissed bool, err error)
+	// GetSecret 读取条目的密钥
+	GetSecret(item, session dbus.ObjectPath) (secretValue, error)
+	// CreateItem 在集合中创建条目，replace 为 true 时覆盖属性相同的条目
+	CreateItem(collection dbus.ObjectPath, properties map[string]dbus.Variant, secret secretValue, replace bool) (prompt dbus.ObjectPath, err error)
+	// DeleteItem 删除条目
+	DeleteItem(item dbus.ObjectPath) (prompt dbus.ObjectPath, err error)
+	// Close 断开连接
+	Close()
+}
+
+// SecretService 通过会话总线访问 freedesktop Secret Service
+// 连接地址来自 DBUS_SESSION_BUS_ADDRESS，因此可以指向用于测试的私有总线
+type SecretService struct {
+	// connect 连接 Secret Service，为 nil 时连接会话总线
+	connect func() (secretBus, error)
+}
+
+// Name 返回密钥环的名称
+func (s *SecretService) Name() string {
+	return "Secret Service"
+}
+
+// ssSession 一次打开的 Secret Service 会话
+type ssSession struct {
+	bus  secretBus
+	path dbus.ObjectPath
+}
+
+// open 连接 Secret Service 并打开会话
+func (s *SecretService) open() (*ssSession, error) {
+	connect := s.connect
+	if connect == nil {
+		connect = connectSessionBus
+	}
+	bus, err := connect()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupported, i18n.T("无法连接D-Bus会话总线: %s", err.Error()))
+	}
+	path, err := bus.OpenSession()
+	if err != nil {
+		bus.Close()
+		return nil, fmt.Errorf("%w: %s", ErrUnsupported, i18n.T("无法打开Secret Service会话: %s", err.Error()))
+	}
+	return &ssSession{bus: bus, path: path}, nil
+}
+
+// close 关闭会话和连接
+func (ss *ssSession) close() {
+	ss.bus.CloseSession(ss.path)
+	ss.bus.Close()
+}
+
+// attributes 用于查找密钥的属性，与 secret-tool 的用法兼容：
+// secret-tool lookup service prompt2cmd username deepseek
+func attributes(account string) map[string]string {
+	return map[string]string{"service": Service, "username": account}
+}
+
+// search 查找账户对应的条目，锁定的条目会先解锁
+func (ss *ssSession) search(account string) ([]dbus.ObjectPath, error) {
+	unlocked, locked, err := ss.bus.SearchItems(attributes(account))
+	if err != nil {
+		return nil, errors.New(i18n.T("查找密钥失败: %s", err.Error()))
+	}
+	if len(unlocked) == 0 && len(locked) > 0 {
+		if err := ss.unlock(locked); err != nil {
+			return nil, err
+		}
+		unlocked = locked
+	}
+	return unlocked, nil
+}
+
+// unlock 解锁条目或集合，需要时等待用户在解锁对话框中输入密码
+func (ss *ssSession) unlock(objects []dbus.ObjectPath) error {
+	prompt, err := ss.bus.Unlock(objects)
+	if err != nil {
+		return errors.New(i18n.T("解锁密钥环失败: %s", err.Error()))
+	}
+	return ss.prompt(prompt)
+}
+
+// prompt 显示 Secret Service 的确认对话框并等待结果
+func (ss *ssSession) prompt(path dbus.ObjectPath) error {
+	if path == noPrompt || path == "" {
+		return nil
+	}
+	dismissed, err := ss.bus.Prompt(path)
+	if err != nil {
+		return err
+	}
+	if dismissed {
+		return errors.New(i18n.T("已取消解锁密钥环"))
+	}
+	return nil
+}
+
+// Get 读取账户对应的密钥
+func (s *SecretService) Get(account string) (string, error) {
+	ss, err := s.open()
+	if err != nil {
+		return "", err
+	}
+	defer ss.close()
+
+	items, err := ss.search(account)
+	if err != nil {
+		return "", err
+	}
+	if len(items) == 0 {
+		return "", ErrNotFound
+	}
+	secret, err := ss.bus.GetSecret(items[0], ss.path)
+	if err != nil {
+		return "", errors.New(i18n.T("读取密钥失败: %s", err.Error()))
+	}
+	return string(secret.Value), nil
+}
+
+// Set 在默认集合中保存账户对应的密钥，已存在时覆盖
+func (s *SecretService) Set(account, value string) error {
+	ss, err := s.open()
+	if err != nil {
+		return err
+	}
+	defer ss.close()
+
+	if err := ss.unlock([]dbus.ObjectPath{defaultCollection}); err != nil {
+		return err
+	}
+	properties := map[string]dbus.Variant{
+		itemInterface + ".Label":      dbus.MakeVariant(i18n.T("%s 的 %s API密钥", Service, account)),
+		itemInterface + ".Attributes": dbus.MakeVariant(attributes(account)),
+	}
+	secret := secretValue{
+		Session:     ss.path,
+		Parameters:  []byte{},
+		Value:       []byte(value),
+		ContentType: "text/plain; charset=utf8",
+	}
+	prompt, err := ss.bus.CreateItem(defaultCollection, properties, secret, true)
+	if err != nil {
+		return errors.New(i18n.T("保存密钥失败: %s", err.Error()))
+	}
+	return ss.prompt(prompt)
+}
+
+// Delete 删除账户对应的密钥
+func (s *SecretService) Delete(account string) error {
+	ss, err := s.open()
+	if err != nil {
+		return err
+	}
+	defer ss.close()
+
+	items, err := ss.search(account)
+	if err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		return ErrNotFound
+	}
+	for _, item := range items {
+		prompt, err := ss.bus.DeleteItem(item)
+		if err != nil {
+			return errors.New(i18n.T("删除密钥失败: %s", err.Error()))
+		}
+		if err := ss.prompt(prompt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dbusBus 通过 D-Bus 会话总线调用 Secret Service
+type dbusBus struct {
+	conn    *dbus.Conn
+	service dbus.BusObject
+}
+
+// connectSessionBus 连接会话总线
+func connectSessionBus() (secretBus, error) {
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return nil, err
+	}
+	return &dbusBus{conn: conn, service: conn.Object(secretsDest, secretsPath)}, nil
+}
+
+func (b *dbusBus) OpenSession() (dbus.ObjectPath, error) {
+	var output dbus.Variant
+	var path dbus.ObjectPath
+	err := b.service.Call(serviceInterface+".OpenSession", 0, "plain", dbus.MakeVariant("")).Store(&output, &path)
+	return path, err
+}
+
+func (b *dbusBus) CloseSession(session dbus.ObjectPath) {
+	b.conn.Object(secretsDest, session).Call(sessionInterface+".Close", 0)
+}
+
+func (b *dbusBus) SearchItems(attributes map[string]string) ([]dbus.ObjectPath, []dbus.ObjectPath, error) {
+	var unlocked, locked []dbus.ObjectPath
+	err := b.service.Call(serviceInterface+".SearchItems", 0, attributes).Store(&unlocked, &locked)
+	return unlocked, locked, err
+}
+
+func (b *dbusBus) Unlock(objects []dbus.ObjectPath) (dbus.ObjectPath, error) {
+	var unlocked []dbus.ObjectPath
+	var prompt dbus.ObjectPath
+	err := b.service.Call(serviceInterface+".Unlock", 0, objects).Store(&unlocked, &prompt)
+	return prompt, err
+}
+
+// Prompt 调用确认对话框并等待 Completed 信号
+func (b *dbusBus) Prompt(path dbus.ObjectPath) (bool, error) {
+	if err := b.conn.AddMatchSignal(
+		dbus.WithMatchObjectPath(path),
+		dbus.WithMatchInterface(promptInterface),
+		dbus.WithMatchMember("Completed"),
+	); err != nil {
+		return false, errors.New(i18n.T("等待密钥环确认失败: %s", err.Error()))
+	}
+	signals := make(chan *dbus.Signal, 1)
+	b.conn.Signal(signals)
+	defer b.conn.RemoveSignal(signals)
+
+	if err := b.conn.Object(secretsDest, path).Call(promptInterface+".Prompt", 0, "").Err; err != nil {
+		return false, errors.New(i18n.T("显示密钥环确认对话框失败: %s", err.Error()))
+	}
+
+	timeout := time.After(promptTimeout)
+	for {
+		select {
+		case signal := <-signals:
+			if signal.Path != path || len(signal.Body) == 0 {
+				continue
+			}
+			dismissed, _ := signal.Body[0].(bool)
+			return dismissed, nil
+		case <-timeout:
+			return false, errors.New(i18n.T("等待密钥环确认超时"))
+		}
+	}
+}
+
+func (b *dbusBus) GetSecret(item, session dbus.ObjectPath) (secretValue, error) {
+	var secret secretValue
+	err := b.conn.Object(secretsDest, item).Call(itemInterface+".GetSecret", 0, session).Store(&secret)
+	return secret, err
+}
+
+func (b *dbusBus) CreateItem(collection dbus.ObjectPath, properties map[string]dbus.Variant, secret secretValue, replace bool) (dbus.ObjectPath, error) {
+	var item, prompt dbus.ObjectPath
+	err := b.conn.Object(secretsDest, collection).Call(collectionIface+".CreateItem", 0, properties, secret, replace).Store(&item, &prompt)
+	return prompt, err
+}
+
+func (b *dbusBus) DeleteItem(item dbus.ObjectPath) (dbus.ObjectPath, error) {
+	var prompt dbus.ObjectPath
+	err := b.conn.Object(secretsDest, item).Call(itemInterface+".Delete", 0).Store(&prompt)
+	return prompt, err
+}
+
+func (b *dbusBus) Close() {
+	b.conn.Close()
+}
@@ -0,0 +1,220 @@
+package secret
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"testing"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// fakeItem 内存中的 Secret Service 条目
+type fakeItem struct {
+	attributes map[string]string
+	value      []byte
+	locked     bool
+}
+
+// fakeBus 在内存中模拟 Secret Service，记录确认对话框的调用
+type fakeBus struct {
+	items    map[dbus.ObjectPath]*fakeItem
+	next     int
+	sessions int
+	// dismiss 为 true 时用户取消所有确认对话框
+	dismiss bool
+	// pending 等待确认后解锁的条目
+	pending map[dbus.ObjectPath][]dbus.ObjectPath
+	prompts int
+	closed  bool
+}
+
+func newFakeBus() *fakeBus {
+	return &fakeBus{
+		items:   make(map[dbus.ObjectPath]*fakeItem),
+		pending: make(map[dbus.ObjectPath][]dbus.ObjectPath),
+	}
+}
+
+func (b *fakeBus) service() *SecretService {
+	return &SecretService{connect: func() (secretBus, error) {
+		b.closed = false
+		return b, nil
+	}}
+}
+
+func (b *fakeBus) OpenSession() (dbus.ObjectPath, error) {
+	b.sessions++
+	return dbus.ObjectPath(fmt.Sprintf("/org/freedesktop/secrets/session/%d", b.sessions)), nil
+}
+
+func (b *fakeBus) CloseSession(dbus.ObjectPath) {}
+
+func (b *fakeBus) SearchItems(attributes map[string]string) (unlocked, locked []dbus.ObjectPath, err error) {
+	for path, item := range b.items {
+		if !maps.Equal(item.attributes, attributes) {
+			continue
+		}
+		if item.locked {
+			locked = append(locked, path)
+		} else {
+			unlocked = append(unlocked, path)
+		}
+	}
+	return unlocked, locked, nil
+}
+
+func (b *fakeBus) Unlock(objects []dbus.ObjectPath) (dbus.ObjectPath, error) {
+	var needPrompt []dbus.ObjectPath
+	for _, object := range objects {
+		if item, ok := b.items[object]; ok && item.locked {
+			needPrompt = append(needPrompt, object)
+		}
+	}
+	if len(needPrompt) == 0 {
+		return noPrompt, nil
+	}
+	prompt := b.newPath("prompt")
+	b.pending[prompt] = needPrompt
+	return prompt, nil
+}
+
+func (b *fakeBus) Prompt(prompt dbus.ObjectPath) (bool, error) {
+	b.prompts++
+	objects, ok := b.pending[prompt]
+	if !ok {
+		return false, errors.New("unknown prompt")
+	}
+	delete(b.pending, prompt)
+	if b.dismiss {
+		return true, nil
+	}
+	for _, object := range objects {
+		b.items[object].locked = false
+	}
+	return false, nil
+}
+
+func (b *fakeBus) GetSecret(item, session dbus.ObjectPath) (secretValue, error) {
+	found, ok := b.items[item]
+	if !ok {
+		return secretValue{}, errors.New("no such item")
+	}
+	if found.locked {
+		return secretValue{}, errors.New("item is locked")
+	}
+	return secretValue{Session: session, Value: found.value, ContentType: "text/plain"}, nil
+}
+
+func (b *fakeBus) CreateItem(collection dbus.ObjectPath, properties map[string]dbus.Variant, secret secretValue, replace bool) (dbus.ObjectPath, error) {
+	if collection != defaultCollection {
+		return "", errors.New("no such collection")
+	}
+	attributes, ok := properties[itemInterface+".Attributes"].Value().(map[string]string)
+	if !ok {
+		return "", errors.New("missing attributes")
+	}
+	if replace {
+		for path, item := range b.items {
+			if maps.Equal(item.attributes, attributes) {
+				delete(b.items, path)
+			}
+		}
+	}
+	b.items[b.newPath("item")] = &fakeItem{attributes: attributes, value: secret.Value}
+	return noPrompt, nil
+}
+
+func (b *fakeBus) DeleteItem(item dbus.ObjectPath) (dbus.ObjectPath, error) {
+	delete(b.items, item)
+	return noPrompt, nil
+}
+
+func (b *fakeBus) Close() {
+	b.closed = true
+}
+
+func (b *fakeBus) newPath(kind string) dbus.ObjectPath {
+	b.next++
+	return dbus.ObjectPath(fmt.Sprintf("/org/freedesktop/secrets/%s/%d", kind, b.next))
+}
+
+func TestSecretServiceRoundTrip(t *testing.T) {
+	bus := newFakeBus()
+	keyring := bus.service()
+
+	if _, err := keyring.Get("deepseek"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get before Set: err = %v, want ErrNotFound", err)
+	}
+	if err := keyring.Set("deepseek", "sk-first"); err != nil {
+		t.Fatal(err)
+	}
+	if err := keyring.Set("deepseek", "sk-second"); err != nil {
+		t.Fatal(err)
+	}
+	if err := keyring.Set("moonshot", "sk-moon"); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := keyring.Get("deepseek"); err != nil || got != "sk-second" {
+		t.Fatalf("Get = %q, %v; want sk-second", got, err)
+	}
+	if len(bus.items) != 2 {
+		t.Errorf("got %d items, want Set to replace the existing one", len(bus.items))
+	}
+
+	if err := keyring.Delete("deepseek"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := keyring.Get("deepseek"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get after Delete: err = %v, want ErrNotFound", err)
+	}
+	if err := keyring.Delete("deepseek"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("second Delete: err = %v, want ErrNotFound", err)
+	}
+	if got, err := keyring.Get("moonshot"); err != nil || got != "sk-moon" {
+		t.Errorf("Get(moonshot) = %q, %v; want sk-moon", got, err)
+	}
+	if !bus.closed {
+		t.Error("connection was not closed")
+	}
+}
+
+func TestSecretServiceUnlocksLockedItems(t *testing.T) {
+	bus := newFakeBus()
+	bus.items["/org/freedesktop/secrets/item/locked"] = &fakeItem{
+		attributes: attributes("deepseek"),
+		value:      []byte("sk-locked"),
+		locked:     true,
+	}
+
+	got, err := bus.service().Get("deepseek")
+	if err != nil || got != "sk-locked" {
+		t.Fatalf("Get = %q, %v; want sk-locked", got, err)
+	}
+	if bus.prompts != 1 {
+		t.Errorf("prompted %d times, want 1", bus.prompts)
+	}
+}
+
+func TestSecretServiceDismissedPrompt(t *testing.T) {
+	bus := newFakeBus()
+	bus.dismiss = true
+	bus.items["/org/freedesktop/secrets/item/locked"] = &fakeItem{
+		attributes: attributes("deepseek"),
+		value:      []byte("sk-locked"),
+		locked:     true,
+	}
+
+	if _, err := bus.service().Get("deepseek"); err == nil || errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get with a dismissed prompt: err = %v, want a cancellation error", err)
+	}
+}
+
+func TestSecretServiceUnavailable(t *testing.T) {
+	keyring := &SecretService{connect: func() (secretBus, error) {
+		return nil, errors.New("no session bus")
+	}}
+	if _, err := keyring.Get("deepseek"); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("err = %v, want ErrUnsupported", err)
+	}
+}
@@ -1,14 +1,22 @@
 package security
 
 import (
+	"fmt"
+	"os"
 	"strings"
+
+	"github.com/elecmonkey/prompt2cmd/internal/config"
+	"github.com/elecmonkey/prompt2cmd/internal/i18n"
 )
 
 // SecurityChecker 安全检查器接口
 type SecurityChecker interface {
 	// IsDangerousCommand 检查命令是否危险
 	IsDangerousCommand(command string) bool
-	
+
+	// AssessCommand 评估命令的风险等级，并给出判定理由
+	AssessCommand(command string) *RiskAssessment
+
 	// GetWarningMessage 获取警告信息
 	GetWarningMessage(command string) string
 }
@@ -16,6 +24,10 @@ type SecurityChecker interface {
 // DefaultSecurityChecker 默认安全检查器实现
 type DefaultSecurityChecker struct {
 	DangerousCommands []string
+	// Policy 声明式安全策略，为 nil 时只使用内置规则
+	Policy *config.SecurityPolicy
+	// PathGuard 受保护路径检查器，为 nil 时只统计受影响的路径
+	PathGuard *PathGuard
 }
 
 // NewSecurityChecker 创建一个新的安全检查器
@@ -27,59 +39,159 @@ func NewSecurityChecker(dangerousCommands []string) *DefaultSecurityChecker {
 
 // IsDangerousCommand 检查命令是否危险
 func (c *DefaultSecurityChecker) IsDangerousCommand(command string) bool {
-	if command == "" {
-		return false
+	return c.AssessCommand(command).Level >= RiskDangerous
+}
+
+// AssessCommand 评估命令的风险等级
+func (c *DefaultSecurityChecker) AssessCommand(command string) *RiskAssessment {
+	assessment := &RiskAssessment{Level: RiskSafe}
+	if strings.TrimSpace(command) == "" {
+		return assessment
 	}
 
-	// 转换为小写以进行不区分大小写的比较
-	cmdLower := strings.ToLower(command)
-	
-	for _, dangerous := range c.DangerousCommands {
-		if strings.Contains(cmdLower, strings.ToLower(dangerous)) {
-			return true
+	// 检查是否包含危险的shell操作
+	if strings.Contains(strings.ReplaceAll(command, " ", ""), ":(){:|:&};:") { // fork炸弹
+		assessment.escalate(RiskCritical, i18n.T("检测到 fork 炸弹，会耗尽系统资源"))
+	}
+
+	parsed := parseCommandLine(command)
+	commands := expandNested(parsed)
+	cwd, _ := os.Getwd()
+
+	// 检测远程代码执行、反弹shell、数据外传和提权
+	for _, detection := range detectThreats(parsed, command) {
+		assessment.Detections = append(assessment.Detections, detection)
+		assessment.escalate(detection.Level, fmt.Sprintf("%s: %s", i18n.Translate(detection.Category), detection.Explanation))
+	}
+	readOnly := len(commands) > 0
+	dirs := commandDirs(commands, cwd)
+	for i, cmd := range commands {
+		dir := dirs[i]
+		if dir == "" {
+			dir = cwd
+		}
+		if match := evaluatePolicy(c.Policy, cmd, command, dir); match != nil {
+			applyPolicyMatch(assessment, match)
+			if match.Action == config.PolicyAllow {
+				// 策略明确放行的命令不再使用内置规则检查
+				if !isReadOnly(cmd) {
+					readOnly = false
+				}
+				continue
+			}
+		}
+
+		assessBuiltinRules(cmd, assessment)
+
+		// 用户配置的危险命令
+		if entry := c.matchDangerousCommand(cmd); entry != "" {
+			assessment.escalate(RiskDangerous, i18n.T("命令包含配置的危险命令: %s", entry))
+		}
+
+		if !isReadOnly(cmd) {
+			readOnly = false
 		}
 	}
-	
-	// 其他安全检查规则
-	// 检查是否包含管道重定向到特权文件
-	if strings.Contains(cmdLower, "> /etc/") ||
-	   strings.Contains(cmdLower, ">> /etc/") {
-		return true
+
+	// 检查命令将写入或删除的路径
+	paths := c.PathGuard.Check(commands, cwd)
+	if len(paths.Targets) > 0 {
+		assessment.Paths = paths
 	}
-	
-	// 检查是否包含危险的shell操作
-	if strings.Contains(cmdLower, ":(){ :|:& };:") { // fork炸弹
-		return true
+	if len(paths.Protected) > 0 {
+		protected := paths.Protected
+		if len(protected) > 5 {
+			protected = append(protected[:5:5], i18n.T("等 %d 个路径", len(paths.Protected)))
+		}
+		reason := i18n.T("将写入或删除受保护路径: %s", strings.Join(protected, ", "))
+		assessment.escalate(RiskCritical, reason)
+		if c.PathGuard.Block {
+			assessment.Blocked = true
+		} else {
+			assessment.setConfirmPhrase(paths.Protected[0])
+		}
+	}
+
+	if len(paths.Unresolved) > 0 {
+		assessment.escalate(RiskCritical, i18n.T("切换目录后无法确定操作的位置: %s", strings.Join(paths.Unresolved, ", ")))
+		if c.PathGuard.Block {
+			assessment.Blocked = true
+		}
+	}
+
+	assessment.ReadOnly = readOnly && assessment.Level == RiskSafe
+	if !assessment.ReadOnly && assessment.Level == RiskSafe {
+		assessment.Level = RiskCaution
+		assessment.Reasons = append(assessment.Reasons, i18n.T("命令可能修改文件或系统状态"))
+	}
+	if assessment.Level == RiskCritical && assessment.ConfirmPhrase == "" {
+		assessment.ConfirmPhrase = "yes"
+	}
+
+	return assessment
+}
+
+// matchDangerousCommand 按单词匹配配置的危险命令，返回命中的条目
+// 例如条目 "rm -rf" 会匹配 "rm -rf build"，但不会匹配 "format"
+func (c *DefaultSecurityChecker) matchDangerousCommand(cmd *simpleCommand) string {
+	program := strings.ToLower(cmd.Program())
+	if program == "" {
+		return ""
+	}
+
+	for _, dangerous := range c.DangerousCommands {
+		words := strings.Fields(strings.ToLower(dangerous))
+		if len(words) == 0 || words[0] != program {
+			continue
+		}
+		matched := true
+		for _, word := range words[1:] {
+			if !containsFold(cmd.Params(), word) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return dangerous
+		}
+	}
+	return ""
+}
+
+// containsFold 不区分大小写地检查列表中是否包含某个单词
+func containsFold(list []string, word string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, word) {
+			return true
+		}
 	}
-	
 	return false
 }
 
 // GetWarningMessage 获取警告信息
 func (c *DefaultSecurityChecker) GetWarningMessage(command string) string {
-	if !c.IsDangerousCommand(command) {
-		return ""
-	}
-	
-	var dangerType string
-	cmdLower := strings.ToLower(command)
-	
+	assessment := c.AssessCommand(command)
+	var message string
 	switch {
-	case strings.Contains(cmdLower, "rm"):
-		dangerType = "文件删除"
-	case strings.Contains(cmdLower, "chmod"):
-		dangerType = "权限修改"
-	case strings.Contains(cmdLower, "chown"):
-		dangerType = "所有权修改"
-	case strings.Contains(cmdLower, "mkfs"):
-		dangerType = "文件系统格式化"
-	case strings.Contains(cmdLower, "dd"):
-		dangerType = "磁盘操作"
-	case strings.Contains(cmdLower, "> /etc/") || strings.Contains(cmdLower, ">> /etc/"):
-		dangerType = "系统配置修改"
+	case assessment.Blocked:
+		message = i18n.T("禁止：此命令被安全策略禁止执行：%s。", strings.Join(assessment.Reasons, i18n.T("；")))
+	case len(assessment.Detections) > 0:
+		var b strings.Builder
+		b.WriteString(i18n.T("警告：此命令的风险等级为「%s」，检测到以下危险模式：", assessment.Level.Label()))
+		for _, detection := range assessment.Detections {
+			fmt.Fprintf(&b, "\n  [%s] %s", i18n.Translate(detection.Category), detection.Explanation)
+		}
+		b.WriteString("\n" + i18n.T("请确认您了解此命令的影响后再继续。"))
+		message = b.String()
+	case assessment.Level >= RiskDangerous:
+		message = i18n.T("警告：此命令的风险等级为「%s」：%s。可能会导致数据丢失或系统问题，请确认您了解此命令的影响后再继续。",
+			assessment.Level.Label(), strings.Join(assessment.Reasons, i18n.T("；")))
 	default:
-		dangerType = "潜在危险操作"
+		return ""
 	}
-	
-	return "警告：此命令包含" + dangerType + "操作，可能会导致数据丢失或系统问题。请确认您了解此命令的影响后再继续。"
-} 
\ No newline at end of file
+
+	if assessment.Paths != nil {
+		message += "\n" + assessment.Paths.Summary(20)
+	}
+	return message
+}
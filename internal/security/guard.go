@@ -0,0 +1,361 @@
+package security
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/elecmonkey/prompt2cmd/internal/i18n"
+)
+
+// maxCountedFiles 统计受影响文件时的上限，避免遍历过大的目录树
+const maxCountedFiles = 10000
+
+// countBudget 统计受影响文件的总耗时上限，超时后按已统计的数量截断
+const countBudget = 200 * time.Millisecond
+
+// PathGuard 受保护路径检查器
+// 以 = 开头的条目只保护路径本身及“目录/*”形式的整体操作，
+// 不含 / 的条目（如 .git）匹配任意同名的路径组成部分，其余条目保护目录本身及其下所有内容
+type PathGuard struct {
+	Protected []string // 受保护路径条目
+	Block     bool     // 命中时禁止执行；为 false 时升级为严重风险，要求完整输入路径确认
+}
+
+// PathGuardResult 受保护路径检查结果
+type PathGuardResult struct {
+	Targets       []string // 展开通配符后将被写入或删除的路径
+	AffectedFiles int      // 受影响的文件总数（递归操作包含目录下的全部文件）
+	Truncated     bool     // 受影响文件数超过统计上限
+	Protected     []string // 位于受保护范围内的目标
+	Unresolved    []string // 切换到无法静态确定的目录后操作的相对路径
+}
+
+// NewPathGuard 创建受保护路径检查器
+// protectMounts 为 true 时会把当前挂载的卷也加入保护范围
+func NewPathGuard(protected []string, block bool, protectMounts bool) *PathGuard {
+	entries := append([]string{}, protected...)
+	if protectMounts {
+		for _, mount := range mountPoints() {
+			entries = append(entries, "="+mount)
+		}
+	}
+	return &PathGuard{
+		Protected: entries,
+		Block:     block,
+	}
+}
+
+// Check 解析命令将写入或删除的路径，并检查是否命中受保护路径
+func (g *PathGuard) Check(commands []*simpleCommand, cwd string) *PathGuardResult {
+	result := &PathGuardResult{}
+	seen := make(map[string]bool)
+	deadline := time.Now().Add(countBudget)
+
+	dirs := commandDirs(commands, cwd)
+	for i, cmd := range commands {
+		recursive := isRecursiveWrite(cmd)
+		destination := destinationOperand(cmd)
+		for _, pattern := range writeTargets(cmd) {
+			dir := dirs[i]
+			if dir == "" && !isAbsolutePattern(pattern) {
+				if g != nil {
+					// 前面的 cd 目标无法确定，不能排除目标位于受保护路径下
+					result.Targets = append(result.Targets, pattern)
+					result.Unresolved = append(result.Unresolved, pattern)
+					continue
+				}
+				dir = cwd
+			}
+			resolvedPattern := resolvePath(pattern, dir)
+			for _, target := range expandGlob(resolvedPattern) {
+				if seen[target] {
+					continue
+				}
+				seen[target] = true
+				result.Targets = append(result.Targets, target)
+
+				if g != nil && g.isProtected(target, resolvedPattern, dir) {
+					result.Protected = append(result.Protected, target)
+				}
+
+				if result.Truncated {
+					continue
+				}
+				if pattern == destination {
+					// 目标位置不存在时不影响已有文件，存在时只覆盖目标本身
+					if _, err := os.Lstat(target); err == nil {
+						result.AffectedFiles++
+					}
+					continue
+				}
+				count, truncated := countFiles(target, recursive, maxCountedFiles-result.AffectedFiles, deadline)
+				result.AffectedFiles += count
+				result.Truncated = truncated
+			}
+		}
+	}
+
+	return result
+}
+
+// commandDirs 按命令列表中的 cd、pushd 推算每条命令执行时的工作目录
+// 目录无法静态确定时（例如 cd -、cd "$DIR"）对应位置为空字符串
+func commandDirs(commands []*simpleCommand, cwd string) []string {
+	dirs := make([]string, len(commands))
+	dir := cwd
+	for i, cmd := range commands {
+		dirs[i] = dir
+		switch cmd.Program() {
+		case "cd", "pushd":
+			operands := cmd.Operands()
+			switch {
+			case len(operands) == 0:
+				dir = resolvePath("~", dir)
+			case operands[0] == "-" || hasGlobMeta(operands[0]):
+				dir = ""
+			case dir == "" && !isAbsolutePattern(operands[0]):
+				// 从未知目录出发的相对路径仍然未知
+			default:
+				dir = resolvePath(operands[0], dir)
+				if strings.ContainsAny(dir, "$`") {
+					dir = ""
+				}
+			}
+		case "popd":
+			dir = ""
+		}
+	}
+	return dirs
+}
+
+// isAbsolutePattern 判断路径是否不依赖工作目录，包括 ~ 和 $HOME 开头的路径
+func isAbsolutePattern(path string) bool {
+	return filepath.IsAbs(path) || path == "~" || strings.HasPrefix(path, "~/") ||
+		path == "$HOME" || strings.HasPrefix(path, "$HOME/") ||
+		path == "${HOME}" || strings.HasPrefix(path, "${HOME}/")
+}
+
+// isProtected 判断目标路径是否位于保护范围内
+// pattern 是展开通配符前的路径，用于识别 ~/* 这类对整个目录内容的操作
+func (g *PathGuard) isProtected(target string, pattern string, cwd string) bool {
+	for _, entry := range g.Protected {
+		switch {
+		case strings.HasPrefix(entry, "="):
+			dir := resolvePath(strings.TrimPrefix(entry, "="), cwd)
+			if target == dir {
+				return true
+			}
+			if hasGlobMeta(filepath.Base(pattern)) && filepath.Dir(pattern) == dir && strings.Trim(filepath.Base(pattern), "*.") == "" {
+				return true
+			}
+		case !strings.ContainsRune(entry, '/') && !strings.HasPrefix(entry, "~"):
+			for _, part := range strings.Split(target, string(filepath.Separator)) {
+				if part == entry {
+					return true
+				}
+			}
+		default:
+			if isWithin(target, resolvePath(entry, cwd)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// writeTargets 返回命令将写入、修改或删除的路径（未展开通配符）
+func writeTargets(cmd *simpleCommand) []string {
+	targets := cmd.OutputRedirects()
+	operands := cmd.Operands()
+
+	switch cmd.Program() {
+	case "rm", "rmdir", "unlink", "shred", "truncate", "touch", "mkdir", "tee", "mv":
+		targets = append(targets, operands...)
+	case "cp", "install", "ln", "rsync", "scp":
+		if len(operands) > 1 {
+			targets = append(targets, operands[len(operands)-1])
+		}
+	case "chmod", "chown", "chgrp":
+		// 第一个操作数是权限或所有者
+		if len(operands) > 1 {
+			targets = append(targets, operands[1:]...)
+		}
+	case "dd":
+		if of, ok := cmd.KeyValue("of"); ok {
+			targets = append(targets, of)
+		}
+	case "sed":
+		if cmd.HasFlag('i', "--in-place") || hasPrefixArg(cmd, "--in-place=") {
+			// 没有 -e 时第一个操作数是脚本
+			if !cmd.HasFlag('e', "--expression") && len(operands) > 0 {
+				operands = operands[1:]
+			}
+			targets = append(targets, operands...)
+		}
+	case "find":
+		if containsFold(cmd.Params(), "-delete") {
+			targets = append(targets, findRoots(cmd)...)
+		}
+	}
+
+	return targets
+}
+
+// destinationOperand 返回移动、复制类命令的目标位置，其他命令返回空字符串
+func destinationOperand(cmd *simpleCommand) string {
+	switch cmd.Program() {
+	case "mv", "cp", "install", "ln", "rsync", "scp":
+		if operands := cmd.Operands(); len(operands) > 1 {
+			return operands[len(operands)-1]
+		}
+	}
+	return ""
+}
+
+// isRecursiveWrite 判断命令是否会递归作用于目录下的全部内容
+func isRecursiveWrite(cmd *simpleCommand) bool {
+	switch cmd.Program() {
+	case "rm":
+		return cmd.HasFlag('r', "--recursive") || cmd.HasFlag('R', "")
+	case "mv":
+		// 移动目录会带走其中的全部文件
+		return true
+	case "chmod", "chown", "chgrp":
+		return cmd.HasFlag('R', "--recursive")
+	case "find":
+		return true
+	case "rsync":
+		return hasPrefixArg(cmd, "--delete")
+	}
+	return false
+}
+
+// findRoots 返回 find 命令的搜索起点
+func findRoots(cmd *simpleCommand) []string {
+	var roots []string
+	for _, arg := range cmd.Params() {
+		if strings.HasPrefix(arg, "-") || arg == "(" || arg == "!" {
+			break
+		}
+		roots = append(roots, arg)
+	}
+	if len(roots) == 0 {
+		roots = append(roots, ".")
+	}
+	return roots
+}
+
+// hasGlobMeta 判断路径是否包含通配符
+func hasGlobMeta(path string) bool {
+	return strings.ContainsAny(path, "*?[")
+}
+
+// expandGlob 按真实文件系统展开通配符，没有匹配时保留原路径
+func expandGlob(pattern string) []string {
+	if !hasGlobMeta(pattern) {
+		return []string{pattern}
+	}
+	matches, err := filepath.Glob(pattern)
+	if err != nil || len(matches) == 0 {
+		return []string{pattern}
+	}
+
+	// 与shell一致，通配符不匹配以 . 开头的隐藏文件，除非模式本身以 . 开头
+	if strings.HasPrefix(filepath.Base(pattern), ".") {
+		return matches
+	}
+	var visible []string
+	for _, match := range matches {
+		if !strings.HasPrefix(filepath.Base(match), ".") {
+			visible = append(visible, match)
+		}
+	}
+	if len(visible) == 0 {
+		return []string{pattern}
+	}
+	return visible
+}
+
+// countFiles 统计路径涉及的文件数量，递归时包含目录下的全部文件
+// 达到 limit 个文件或超过 deadline 时停止遍历并返回截断标记
+func countFiles(path string, recursive bool, limit int, deadline time.Time) (int, bool) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		// 路径尚不存在（例如新建文件），按一个文件计算
+		return 1, false
+	}
+	if !info.IsDir() || !recursive {
+		return 1, false
+	}
+
+	count := 0
+	truncated := false
+	_ = filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if !d.IsDir() {
+			count++
+		}
+		if count >= limit || time.Now().After(deadline) {
+			truncated = true
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	return count, truncated
+}
+
+// 不需要保护的伪文件系统类型
+var pseudoFilesystems = map[string]bool{
+	"proc": true, "sysfs": true, "devpts": true, "tmpfs": true, "devtmpfs": true, "cgroup": true,
+	"cgroup2": true, "securityfs": true, "debugfs": true, "tracefs": true, "mqueue": true,
+	"pstore": true, "bpf": true, "configfs": true, "fusectl": true, "hugetlbfs": true,
+	"autofs": true, "binfmt_misc": true, "overlay": true, "nsfs": true, "efivarfs": true,
+}
+
+// mountPoints 读取当前挂载的卷（仅Linux，其他系统返回空）
+func mountPoints() []string {
+	file, err := os.Open("/proc/mounts")
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	var mounts []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 3 || pseudoFilesystems[fields[2]] {
+			continue
+		}
+		// /proc/mounts 中的空格等字符使用八进制转义
+		mount := strings.ReplaceAll(fields[1], `\040`, " ")
+		mounts = append(mounts, mount)
+	}
+	return mounts
+}
+
+// Summary 生成受影响路径的说明，最多列出 limit 个路径
+func (r *PathGuardResult) Summary(limit int) string {
+	var b strings.Builder
+	switch {
+	case r.Truncated:
+		b.WriteString(i18n.T("将影响 %d 个路径，共超过 %d 个文件", len(r.Targets), r.AffectedFiles))
+	default:
+		b.WriteString(i18n.T("将影响 %d 个路径，共 %d 个文件", len(r.Targets), r.AffectedFiles))
+	}
+	for i, target := range r.Targets {
+		if i >= limit {
+			fmt.Fprintf(&b, "\n  %s", i18n.T("... 以及另外 %d 个路径", len(r.Targets)-limit))
+			break
+		}
+		fmt.Fprintf(&b, "\n  %s", target)
+	}
+	return b.String()
+}
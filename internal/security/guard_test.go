@@ -0,0 +1,96 @@
+package security
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPathGuardStopsAtProtectedPath(t *testing.T) {
+	guard := NewPathGuard([]string{"=/"}, false, false)
+
+	start := time.Now()
+	result := guard.Check(parseCommandLine("rm -rf /"), "/")
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Check took %s, want an early return", elapsed)
+	}
+	if len(result.Protected) != 1 || result.Protected[0] != "/" {
+		t.Errorf("Protected = %v, want [/]", result.Protected)
+	}
+	if result.AffectedFiles == 0 {
+		t.Errorf("AffectedFiles = 0, want the files counted before the limit")
+	}
+}
+
+func TestPathGuardCountsFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a", "b", "sub/c"} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		command string
+		want    int
+	}{
+		{"rm -rf " + dir, 3},
+		{"rm " + filepath.Join(dir, "a"), 1},
+		{"mv " + dir + " " + filepath.Join(t.TempDir(), "elsewhere"), 3},
+		{"mv " + filepath.Join(dir, "a") + " " + filepath.Join(dir, "b"), 2},
+		{"cp " + filepath.Join(dir, "a") + " " + filepath.Join(dir, "new"), 0},
+	}
+	for _, tt := range tests {
+		result := (*PathGuard)(nil).Check(parseCommandLine(tt.command), dir)
+		if result.AffectedFiles != tt.want || result.Truncated {
+			t.Errorf("Check(%q) = %d files (truncated %v), want %d", tt.command, result.AffectedFiles, result.Truncated, tt.want)
+		}
+	}
+}
+
+func TestPathGuardFollowsCd(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "sub", "a"), nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	guard := NewPathGuard([]string{"=/", "=" + filepath.Join(dir, "sub")}, true, false)
+
+	tests := []struct {
+		command   string
+		protected bool
+	}{
+		{"cd / && rm -rf *", true},
+		{"cd /; rm -rf ./*", true},
+		{"cd sub && rm -rf *", true},
+		{"cd " + dir + " && cd sub && rm -rf *", true},
+		{"cd \"$TARGET\" && rm -rf *", true},
+		{"cd - && rm -rf *", true},
+		{"cd sub && rm a", false},
+		{"cd \"$TARGET\" && rm -rf /tmp/x", false},
+	}
+	for _, tt := range tests {
+		result := guard.Check(parseCommandLine(tt.command), dir)
+		got := len(result.Protected) > 0 || len(result.Unresolved) > 0
+		if got != tt.protected {
+			t.Errorf("Check(%q) protected = %v (targets %v), want %v", tt.command, got, result.Targets, tt.protected)
+		}
+	}
+
+	result := guard.Check(parseCommandLine("cd sub && rm a"), dir)
+	if want := filepath.Join(dir, "sub", "a"); len(result.Targets) != 1 || result.Targets[0] != want {
+		t.Errorf("Targets = %v, want [%s]", result.Targets, want)
+	}
+
+	checker := &DefaultSecurityChecker{PathGuard: guard}
+	if a := checker.AssessCommand("cd / && rm -rf *"); !a.Blocked || a.Level != RiskCritical {
+		t.Errorf("AssessCommand(cd / && rm -rf *) = %v (blocked %v), want blocked critical", a.Level, a.Blocked)
+	}
+}
@@ -0,0 +1,589 @@
+package security
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// redirect 表示一个输出或输入重定向
+type redirect struct {
+	Op     string // >, >>, <, 2>, &> 等
+	Target string
+}
+
+// simpleCommand 表示命令行中被管道或控制符分隔的单条命令
+type simpleCommand struct {
+	Args      []string   // 原始参数列表（不含重定向）
+	Redirects []redirect // 重定向
+	PipeIn    bool       // 是否从上一条命令的管道读取输入
+	PipeOut   bool       // 是否将输出通过管道交给下一条命令
+	Sudo      bool       // 是否通过 sudo/doas 等提权执行
+	// Substitutions 参数中 $(...)、`...`、<(...) 等替换的命令文本
+	Substitutions []string
+	// ArgsFromInput 部分参数由 xargs 从标准输入或文件读取，实际操作的目标无法静态确定
+	ArgsFromInput bool
+}
+
+// 会把真实命令包裹起来执行的前缀命令
+var wrapperCommands = map[string]bool{
+	"sudo":    true,
+	"doas":    true,
+	"env":     true,
+	"nohup":   true,
+	"time":    true,
+	"nice":    true,
+	"command": true,
+	"exec":    true,
+	"builtin": true,
+	"timeout": true,
+	"stdbuf":  true,
+	"ionice":  true,
+	"chroot":  true,
+	"flock":   true,
+}
+
+// 包裹命令中需要单独参数的选项，例如 sudo -u root、timeout -s KILL
+var wrapperOptionArgs = map[string]map[string]bool{
+	"sudo":    {"-u": true, "-g": true, "-C": true, "-D": true, "-h": true, "-p": true, "-r": true, "-t": true, "-U": true},
+	"doas":    {"-u": true, "-C": true},
+	"env":     {"-u": true, "-C": true, "--unset": true, "--chdir": true},
+	"nice":    {"-n": true, "--adjustment": true},
+	"timeout": {"-s": true, "-k": true, "--signal": true, "--kill-after": true},
+	"stdbuf":  {"-i": true, "-o": true, "-e": true},
+	"ionice":  {"-c": true, "-n": true, "--class": true, "--classdata": true},
+	"chroot":  {"--userspec": true, "--groups": true},
+	"flock":   {"-w": true, "-E": true, "--timeout": true, "--wait": true, "--conflict-exit-code": true},
+}
+
+// 包裹命令在选项之后、真实命令之前的位置参数个数
+// 例如 timeout 5 cmd 的时长、chroot /mnt cmd 的根目录、flock /tmp/lock cmd 的锁文件
+var wrapperPositionals = map[string]int{
+	"timeout": 1,
+	"chroot":  1,
+	"flock":   1,
+}
+
+// 提权类前缀命令
+var privilegeCommands = map[string]bool{
+	"sudo": true,
+	"doas": true,
+}
+
+// 出现在命令位置时不是真正命令的shell保留字
+var reservedWords = map[string]bool{
+	"if":    true,
+	"then":  true,
+	"else":  true,
+	"elif":  true,
+	"fi":    true,
+	"while": true,
+	"until": true,
+	"do":    true,
+	"done":  true,
+	"esac":  true,
+	"{":     true,
+	"}":     true,
+	"!":     true,
+}
+
+// 后面直到分隔符都不是命令的保留字，例如 for x in a b
+var clauseWords = map[string]bool{
+	"for":    true,
+	"select": true,
+	"case":   true,
+}
+
+// parseCommandLine 将一条shell命令解析为若干简单命令
+// 这里只做足够安全检查使用的近似解析，不追求完整的shell语法
+func parseCommandLine(command string) []*simpleCommand {
+	tokens := tokenize(command)
+
+	var commands []*simpleCommand
+	current := &simpleCommand{}
+	pipeIn := false
+	// skipWords 为 true 时丢弃到下一个分隔符为止的单词（for/case 子句）
+	skipWords := false
+	// skipNext 为 true 时丢弃下一个单词（function 后的函数名）
+	skipNext := false
+	depth := 0
+
+	flush := func(pipeOut bool) {
+		if len(current.Args) > 0 || len(current.Redirects) > 0 || len(current.Substitutions) > 0 {
+			current.PipeIn = pipeIn
+			current.PipeOut = pipeOut
+			current.normalize()
+			commands = append(commands, current)
+		}
+		current = &simpleCommand{}
+		pipeIn = pipeOut
+		skipWords = false
+	}
+
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		current.Substitutions = append(current.Substitutions, tok.subs...)
+		if !tok.operator {
+			atStart := len(current.Args) == 0 && len(current.Redirects) == 0
+			switch {
+			case skipWords:
+			case skipNext:
+				skipNext = false
+			case atStart && reservedWords[tok.value]:
+			case atStart && clauseWords[tok.value]:
+				skipWords = true
+			case atStart && tok.value == "function":
+				skipNext = true
+			default:
+				current.Args = append(current.Args, tok.value)
+			}
+			continue
+		}
+
+		switch tok.value {
+		case "|", "|&":
+			flush(true)
+		case ";", "&&", "||", "&", "\n":
+			flush(false)
+		case "(":
+			if i+1 < len(tokens) && tokens[i+1].value == ")" && tokens[i+1].operator {
+				// name() { ...; } 形式的函数定义，函数名不是命令
+				current.Args = nil
+				i++
+				continue
+			}
+			depth++
+			flush(false)
+		case ")":
+			if depth == 0 {
+				// 没有对应左括号的 ) 是 case 分支的模式结尾
+				current.Args = nil
+			} else {
+				depth--
+			}
+			flush(false)
+		case ">", ">>", "<", "2>", "2>>", "&>", "&>>", ">|":
+			target := ""
+			if i+1 < len(tokens) && !tokens[i+1].operator {
+				target = tokens[i+1].value
+				current.Substitutions = append(current.Substitutions, tokens[i+1].subs...)
+				i++
+			}
+			current.Redirects = append(current.Redirects, redirect{Op: tok.value, Target: target})
+		}
+	}
+	flush(false)
+
+	return commands
+}
+
+// normalize 去掉环境变量赋值和包裹命令，记录是否提权
+func (c *simpleCommand) normalize() {
+	args := c.Args
+	for len(args) > 0 {
+		first := args[0]
+		// 跳过 VAR=value 形式的环境变量赋值
+		if isAssignment(first) {
+			args = args[1:]
+			continue
+		}
+		name := filepath.Base(first)
+		if !wrapperCommands[name] {
+			break
+		}
+		if privilegeCommands[name] {
+			c.Sudo = true
+		}
+		args = args[1:]
+		// 跳过包裹命令自身的选项，例如 sudo -u root
+		for len(args) > 0 && strings.HasPrefix(args[0], "-") {
+			opt := args[0]
+			args = args[1:]
+			if opt == "--" {
+				break
+			}
+			if wrapperOptionArgs[name][opt] && len(args) > 0 {
+				args = args[1:]
+			}
+		}
+		for n := wrapperPositionals[name]; n > 0 && len(args) > 0; n-- {
+			args = args[1:]
+		}
+		// flock FILE -c 'cmd' 通过shell执行命令，按 sh -c 处理
+		if name == "flock" && len(args) > 0 && (args[0] == "-c" || args[0] == "--command") {
+			args = append([]string{"sh", "-c"}, args[1:]...)
+		}
+	}
+	c.Args = args
+}
+
+// Program 返回命令名（不含路径）
+func (c *simpleCommand) Program() string {
+	if len(c.Args) == 0 {
+		return ""
+	}
+	return filepath.Base(c.Args[0])
+}
+
+// Params 返回命令的参数（不含命令名）
+func (c *simpleCommand) Params() []string {
+	if len(c.Args) <= 1 {
+		return nil
+	}
+	return c.Args[1:]
+}
+
+// HasFlag 检查是否包含某个短选项字母或长选项
+// 例如 HasFlag('r', "--recursive") 会匹配 -r、-rf、-fr 和 --recursive
+func (c *simpleCommand) HasFlag(short byte, long string) bool {
+	for _, arg := range c.Params() {
+		if arg == "--" {
+			break
+		}
+		if long != "" && arg == long {
+			return true
+		}
+		if short != 0 && len(arg) > 1 && arg[0] == '-' && arg[1] != '-' {
+			if strings.IndexByte(arg[1:], short) >= 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Operands 返回非选项参数
+func (c *simpleCommand) Operands() []string {
+	var operands []string
+	afterDoubleDash := false
+	for _, arg := range c.Params() {
+		if !afterDoubleDash {
+			if arg == "--" {
+				afterDoubleDash = true
+				continue
+			}
+			if strings.HasPrefix(arg, "-") && arg != "-" {
+				continue
+			}
+		}
+		operands = append(operands, arg)
+	}
+	return operands
+}
+
+// KeyValue 返回 key=value 形式参数的值，例如 dd 的 of=/dev/sda
+func (c *simpleCommand) KeyValue(key string) (string, bool) {
+	prefix := key + "="
+	for _, arg := range c.Params() {
+		if strings.HasPrefix(arg, prefix) {
+			return strings.TrimPrefix(arg, prefix), true
+		}
+	}
+	return "", false
+}
+
+// OutputRedirects 返回写文件的重定向目标
+func (c *simpleCommand) OutputRedirects() []string {
+	var targets []string
+	for _, r := range c.Redirects {
+		if r.Op == "<" || r.Target == "" {
+			continue
+		}
+		// 重定向到文件描述符（如 2>&1）不算写文件
+		if strings.HasPrefix(r.Target, "&") {
+			continue
+		}
+		targets = append(targets, r.Target)
+	}
+	return targets
+}
+
+// isAssignment 判断是否为 VAR=value 形式的赋值
+func isAssignment(word string) bool {
+	eq := strings.IndexByte(word, '=')
+	if eq <= 0 {
+		return false
+	}
+	for i, ch := range word[:eq] {
+		if ch == '_' || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') || (i > 0 && ch >= '0' && ch <= '9') {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+// token 表示词法分析得到的单词或操作符
+type token struct {
+	value    string
+	operator bool
+	subs     []string // 单词中命令替换和进程替换的命令文本
+}
+
+// tokenize 按shell的引号和操作符规则切分命令
+func tokenize(command string) []token {
+	var tokens []token
+	runes := []rune(command)
+	var word strings.Builder
+	var subs []string
+	inWord := false
+
+	emitWord := func() {
+		if inWord {
+			tokens = append(tokens, token{value: word.String(), subs: subs})
+			word.Reset()
+			subs = nil
+			inWord = false
+		}
+	}
+	// substitute 把 runes[start:end+1] 原样写入单词，并记录其中要执行的命令
+	substitute := func(start, end int, body string) {
+		word.WriteString(string(runes[start:min(end+1, len(runes))]))
+		subs = append(subs, body)
+		inWord = true
+	}
+	emitOp := func(op string) {
+		emitWord()
+		tokens = append(tokens, token{value: op, operator: true})
+	}
+
+	for i := 0; i < len(runes); i++ {
+		ch := runes[i]
+		next := rune(0)
+		if i+1 < len(runes) {
+			next = runes[i+1]
+		}
+
+		switch {
+		case ch == '\\' && next != 0:
+			if next != '\n' {
+				word.WriteRune(next)
+				inWord = true
+			}
+			i++
+		case ch == '\'':
+			inWord = true
+			for i++; i < len(runes) && runes[i] != '\''; i++ {
+				word.WriteRune(runes[i])
+			}
+		case ch == '"':
+			inWord = true
+			for i++; i < len(runes) && runes[i] != '"'; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) && strings.ContainsRune("\"\\$`", runes[i+1]) {
+					i++
+				} else if end, body, ok := substitution(runes, i); ok {
+					substitute(i, end, body)
+					i = end
+					continue
+				}
+				word.WriteRune(runes[i])
+			}
+		case ch == '$' || ch == '`':
+			if end, body, ok := substitution(runes, i); ok {
+				substitute(i, end, body)
+				i = end
+			} else {
+				word.WriteRune(ch)
+				inWord = true
+			}
+		case (ch == '<' || ch == '>') && next == '(' && !inWord:
+			// 进程替换 <(...) 和 >(...)
+			end := matchParen(runes, i+1)
+			substitute(i, end, string(runes[i+2:min(end, len(runes))]))
+			i = end
+		case ch == '(' && inWord && strings.HasSuffix(word.String(), "="):
+			// 数组赋值 a=(x y)
+			end := matchParen(runes, i)
+			word.WriteString(string(runes[i:min(end+1, len(runes))]))
+			i = end
+		case ch == '(' || ch == ')':
+			emitOp(string(ch))
+		case ch == '#' && !inWord:
+			// 注释，忽略到行尾
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+			i--
+		case ch == ' ' || ch == '\t':
+			emitWord()
+		case ch == '\n':
+			emitOp("\n")
+		case ch == ';':
+			emitOp(";")
+		case ch == '|':
+			switch next {
+			case '|':
+				emitOp("||")
+				i++
+			case '&':
+				emitOp("|&")
+				i++
+			default:
+				emitOp("|")
+			}
+		case ch == '&':
+			switch next {
+			case '&':
+				emitOp("&&")
+				i++
+			case '>':
+				if i+2 < len(runes) && runes[i+2] == '>' {
+					emitOp("&>>")
+					i += 2
+				} else {
+					emitOp("&>")
+					i++
+				}
+			default:
+				emitOp("&")
+			}
+		case ch == '>':
+			// 2> 这样的写法：数字紧贴在 > 前面
+			prefix := ""
+			if inWord && word.Len() == 1 && (word.String() == "1" || word.String() == "2") {
+				if word.String() == "2" {
+					prefix = "2"
+				}
+				word.Reset()
+				inWord = false
+			}
+			switch next {
+			case '>':
+				emitOp(prefix + ">>")
+				i++
+			case '|':
+				emitOp(">|")
+				i++
+			case '&':
+				// 2>&1 这类文件描述符复制，作为普通重定向处理
+				emitOp(prefix + ">")
+				word.WriteRune('&')
+				inWord = true
+				i++
+			default:
+				emitOp(prefix + ">")
+			}
+		case ch == '<':
+			emitOp("<")
+		default:
+			word.WriteRune(ch)
+			inWord = true
+		}
+	}
+	emitWord()
+
+	return tokens
+}
+
+// substitution 识别从 runes[i] 开始的 $(...) 或 `...` 命令替换
+// 返回替换结尾的位置和其中的命令文本；$((...)) 算术展开不算命令替换
+func substitution(runes []rune, i int) (int, string, bool) {
+	switch {
+	case runes[i] == '`':
+		end := i + 1
+		for end < len(runes) && runes[end] != '`' {
+			if runes[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		return end, string(runes[i+1 : min(end, len(runes))]), true
+	case runes[i] == '$' && i+1 < len(runes) && runes[i+1] == '(':
+		end := matchParen(runes, i+1)
+		if i+2 < len(runes) && runes[i+2] == '(' {
+			return end, "", false
+		}
+		return end, string(runes[i+2 : min(end, len(runes))]), true
+	}
+	return i, "", false
+}
+
+// matchParen 返回与 runes[open] 处左括号匹配的右括号位置，跳过引号中的内容
+// 没有匹配的右括号时返回 len(runes)
+func matchParen(runes []rune, open int) int {
+	depth := 0
+	for i := open; i < len(runes); i++ {
+		switch runes[i] {
+		case '\\':
+			i++
+		case '\'':
+			for i++; i < len(runes) && runes[i] != '\''; i++ {
+			}
+		case '"':
+			for i++; i < len(runes) && runes[i] != '"'; i++ {
+				if runes[i] == '\\' {
+					i++
+				}
+			}
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(runes)
+}
+
+// nestedCommands 返回被当前命令间接执行的命令
+// 包括命令替换、find -exec、xargs 以及 sh -c 中的命令
+func (c *simpleCommand) nestedCommands() []*simpleCommand {
+	var nested []*simpleCommand
+	for _, body := range c.Substitutions {
+		nested = append(nested, parseCommandLine(body)...)
+	}
+	params := c.Params()
+
+	switch c.Program() {
+	case "find":
+		for i := 0; i < len(params); i++ {
+			switch params[i] {
+			case "-exec", "-execdir", "-ok", "-okdir":
+				inner := &simpleCommand{Sudo: c.Sudo}
+				for i++; i < len(params) && params[i] != ";" && params[i] != "+"; i++ {
+					inner.Args = append(inner.Args, params[i])
+				}
+				inner.normalize()
+				nested = append(nested, inner)
+			}
+		}
+	case "xargs":
+		i := 0
+		for i < len(params) && strings.HasPrefix(params[i], "-") {
+			// 跳过带参数的选项，例如 -I {}、-n 1
+			switch params[i] {
+			case "-I", "-n", "-P", "-d", "-L", "-s", "-E", "-a", "--arg-file", "--delimiter", "--max-args", "--max-procs":
+				i++
+			}
+			i++
+		}
+		if i < len(params) {
+			inner := &simpleCommand{Args: append([]string(nil), params[i:]...), Sudo: c.Sudo, ArgsFromInput: true}
+			inner.normalize()
+			nested = append(nested, inner)
+		}
+	case "sh", "bash", "zsh", "dash", "ksh":
+		for i := 0; i+1 < len(params); i++ {
+			if params[i] == "-c" {
+				for _, inner := range parseCommandLine(params[i+1]) {
+					inner.Sudo = inner.Sudo || c.Sudo
+					nested = append(nested, inner)
+				}
+				break
+			}
+		}
+	}
+
+	return nested
+}
+
+// expandNested 展开命令列表中所有间接执行的命令
+func expandNested(commands []*simpleCommand) []*simpleCommand {
+	var all []*simpleCommand
+	for _, cmd := range commands {
+		all = append(all, cmd)
+		all = append(all, expandNested(cmd.nestedCommands())...)
+	}
+	return all
+}
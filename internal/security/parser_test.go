@@ -0,0 +1,140 @@
+package security
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/elecmonkey/prompt2cmd/internal/config"
+)
+
+func TestParseCommandLinePrograms(t *testing.T) {
+	tests := []struct {
+		command string
+		want    []string
+	}{
+		{"ls -la", []string{"ls"}},
+		{"cat a | grep b && echo ok", []string{"cat", "grep", "echo"}},
+		{"sudo -u root rm -rf /tmp/x", []string{"rm"}},
+		{"(cd /tmp && rm -rf build)", []string{"cd", "rm"}},
+		{"{ rm -rf /; }", []string{"rm"}},
+		{"if true; then rm -rf /; fi", []string{"true", "rm"}},
+		{"if ! grep -q x f; then echo no; else echo yes; fi", []string{"grep", "echo", "echo"}},
+		{"while true; do sleep 1; done", []string{"true", "sleep"}},
+		{"for f in a b; do rm $f; done", []string{"rm"}},
+		{"case $x in a) rm -rf /;; b) ls;; esac", []string{"rm", "ls"}},
+		{"f() { rm -rf /; }", []string{"rm"}},
+		{"function f { ls; }", []string{"ls"}},
+		{"arr=(a b) ls", []string{"ls"}},
+		{"echo '(rm -rf /)'", []string{"echo"}},
+		{"timeout 5 rm -rf /", []string{"rm"}},
+		{"timeout -s KILL -k 2 10s make test", []string{"make"}},
+		{"stdbuf -o L -eL tail -f log", []string{"tail"}},
+		{"ionice -c 3 nice -n 10 tar czf a.tgz dir", []string{"tar"}},
+		{"chroot /mnt/root rm -rf /", []string{"rm"}},
+		{"flock -w 5 /tmp/lock rm -rf /", []string{"rm"}},
+		{"flock /tmp/lock -c 'ls'", []string{"sh"}},
+		{"env -u HOME -- rm x", []string{"rm"}},
+	}
+
+	for _, tt := range tests {
+		var got []string
+		for _, cmd := range parseCommandLine(tt.command) {
+			if cmd.Program() != "" {
+				got = append(got, cmd.Program())
+			}
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseCommandLine(%q) programs = %v, want %v", tt.command, got, tt.want)
+		}
+	}
+}
+
+func TestParseCommandLineSubstitutions(t *testing.T) {
+	tests := []struct {
+		command string
+		want    []string
+	}{
+		{"echo $(rm -rf /)", []string{"rm -rf /"}},
+		{"echo `rm -rf /`", []string{"rm -rf /"}},
+		{`echo "today is $(date)"`, []string{"date"}},
+		{"diff <(ls a) <(ls b)", []string{"ls a", "ls b"}},
+		{"tee >(gzip > out.gz)", []string{"gzip > out.gz"}},
+		{"echo $(echo $(whoami))", []string{"echo $(whoami)"}},
+		{"echo $((1 + 2))", nil},
+		{"echo '$(rm -rf /)'", nil},
+		{`echo \$(ls)`, nil},
+		{"cat < $(mktemp)", []string{"mktemp"}},
+	}
+
+	for _, tt := range tests {
+		var got []string
+		for _, cmd := range parseCommandLine(tt.command) {
+			got = append(got, cmd.Substitutions...)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseCommandLine(%q) substitutions = %q, want %q", tt.command, got, tt.want)
+		}
+	}
+}
+
+func TestAssessCommandGroupedAndSubstituted(t *testing.T) {
+	checker := NewSecurityChecker(config.DefaultDangerousCommands)
+
+	tests := []struct {
+		command string
+		want    RiskLevel
+	}{
+		{"rm -rf /", RiskCritical},
+		{"(rm -rf /)", RiskCritical},
+		{"{ rm -rf /; }", RiskCritical},
+		{"echo $(rm -rf /)", RiskCritical},
+		{"echo `rm -rf /`", RiskCritical},
+		{`echo "$(rm -rf /)"`, RiskCritical},
+		{"diff <(rm -rf /) file", RiskCritical},
+		{"if true; then rm -rf /; fi", RiskCritical},
+		{"while true; do rm -rf /; done", RiskCritical},
+		{"for f in $(rm -rf /); do echo $f; done", RiskCritical},
+		{"case x in x) rm -rf /;; esac", RiskCritical},
+		{"bash -c 'echo $(rm -rf /)'", RiskCritical},
+		{"echo $(echo $(rm -rf /))", RiskCritical},
+		{"timeout 5 rm -rf /", RiskCritical},
+		{"timeout --signal KILL 1m rm -rf /", RiskCritical},
+		{"stdbuf -oL rm -rf /", RiskCritical},
+		{"ionice -c 3 rm -rf /", RiskCritical},
+		{"chroot / rm -rf /", RiskCritical},
+		{"flock /tmp/lock rm -rf /", RiskCritical},
+		{"flock /tmp/lock -c 'rm -rf /'", RiskCritical},
+		{"sudo timeout 5 dd if=/dev/zero of=/dev/sda", RiskCritical},
+		{"timeout 5 mkfs.ext4 /dev/sdb1", RiskCritical},
+		{"xargs -a list rm", RiskDangerous},
+		{"xargs --arg-file list rm", RiskDangerous},
+		{"find . -name '*.tmp' | xargs rm", RiskDangerous},
+		{"timeout 5 ls", RiskSafe},
+		{"(ls -la)", RiskSafe},
+		{"echo $(date)", RiskSafe},
+		{"if true; then ls; fi", RiskSafe},
+		{"echo '$(rm -rf /)'", RiskSafe},
+	}
+
+	for _, tt := range tests {
+		if got := checker.AssessCommand(tt.command).Level; got != tt.want {
+			t.Errorf("AssessCommand(%q) = %s, want %s", tt.command, got, tt.want)
+		}
+	}
+}
+
+func TestGetWarningMessage(t *testing.T) {
+	checker := NewSecurityChecker(config.DefaultDangerousCommands)
+
+	if message := checker.GetWarningMessage("ls -la"); message != "" {
+		t.Errorf("GetWarningMessage(ls -la) = %q, want empty", message)
+	}
+	assessment := checker.AssessCommand("rm -rf /")
+	message := checker.GetWarningMessage("rm -rf /")
+	for _, reason := range assessment.Reasons {
+		if !strings.Contains(message, reason) {
+			t.Errorf("GetWarningMessage(rm -rf /) = %q, missing reason %q", message, reason)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package security
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/elecmonkey/prompt2cmd/internal/config"
+	"github.com/elecmonkey/prompt2cmd/internal/i18n"
+)
+
+// PolicyMatch 命中的安全策略规则
+type PolicyMatch struct {
+	Rule    string              // 规则名称
+	Action  config.PolicyAction // 规则动作
+	Message string              // 规则说明
+	Command string              // 命中规则的命令片段
+}
+
+// evaluatePolicy 返回第一条匹配单条命令的策略规则，没有匹配时返回 nil
+func evaluatePolicy(policy *config.SecurityPolicy, cmd *simpleCommand, fullCommand string, cwd string) *PolicyMatch {
+	if policy == nil {
+		return nil
+	}
+
+	for i := range policy.Rules {
+		rule := &policy.Rules[i]
+		if !ruleMatches(rule, cmd, fullCommand, cwd) {
+			continue
+		}
+		return &PolicyMatch{
+			Rule:    rule.Name,
+			Action:  rule.Action,
+			Message: rule.Message,
+			Command: strings.Join(cmd.Args, " "),
+		}
+	}
+	return nil
+}
+
+// ruleMatches 判断规则的所有条件是否都满足
+func ruleMatches(rule *config.PolicyRule, cmd *simpleCommand, fullCommand string, cwd string) bool {
+	if rule.Program != "" {
+		matched, err := filepath.Match(rule.Program, cmd.Program())
+		if err != nil || !matched {
+			return false
+		}
+	}
+	if rule.ArgsRegexp != nil && !rule.ArgsRegexp.MatchString(strings.Join(cmd.Params(), " ")) {
+		return false
+	}
+	if rule.CommandRegexp != nil && !rule.CommandRegexp.MatchString(fullCommand) {
+		return false
+	}
+	if rule.Paths != nil && !pathScopeMatches(rule.Paths, commandPaths(cmd), cwd) {
+		return false
+	}
+	return true
+}
+
+// commandPaths 返回命令中可能是路径的参数，包括重定向目标
+func commandPaths(cmd *simpleCommand) []string {
+	paths := append([]string{}, cmd.Operands()...)
+	return append(paths, cmd.OutputRedirects()...)
+}
+
+// pathScopeMatches 判断路径参数是否满足规则的路径范围
+func pathScopeMatches(scope *config.PathScope, paths []string, cwd string) bool {
+	if len(paths) == 0 {
+		return false
+	}
+
+	if len(scope.Inside) > 0 {
+		for _, path := range paths {
+			if !insideAny(resolvePath(path, cwd), scope.Inside, cwd) {
+				return false
+			}
+		}
+	}
+
+	if len(scope.Outside) > 0 {
+		outside := false
+		for _, path := range paths {
+			if !insideAny(resolvePath(path, cwd), scope.Outside, cwd) {
+				outside = true
+				break
+			}
+		}
+		if !outside {
+			return false
+		}
+	}
+
+	return true
+}
+
+// insideAny 判断路径是否位于任一目录内（包括目录本身）
+func insideAny(path string, dirs []string, cwd string) bool {
+	for _, dir := range dirs {
+		if isWithin(path, resolvePath(dir, cwd)) {
+			return true
+		}
+	}
+	return false
+}
+
+// isWithin 判断 path 是否等于 dir 或位于 dir 之下
+func isWithin(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))
+}
+
+// resolvePath 将路径展开为绝对路径，支持 ~、$HOME 和 $REPO
+func resolvePath(path string, cwd string) string {
+	homeDir, _ := os.UserHomeDir()
+
+	switch {
+	case path == "~" || strings.HasPrefix(path, "~/"):
+		path = homeDir + path[1:]
+	case path == "$HOME" || strings.HasPrefix(path, "$HOME/"):
+		path = homeDir + strings.TrimPrefix(path, "$HOME")
+	case path == "${HOME}" || strings.HasPrefix(path, "${HOME}/"):
+		path = homeDir + strings.TrimPrefix(path, "${HOME}")
+	case path == "$REPO" || strings.HasPrefix(path, "$REPO/"):
+		path = findRepoRoot(cwd) + strings.TrimPrefix(path, "$REPO")
+	}
+
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(cwd, path)
+	}
+	return filepath.Clean(path)
+}
+
+// findRepoRoot 向上查找包含 .git 的目录，找不到时返回当前目录
+func findRepoRoot(cwd string) string {
+	dir := cwd
+	for {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return cwd
+		}
+		dir = parent
+	}
+}
+
+// applyPolicyMatch 将命中的策略规则作用到评估结果上
+func applyPolicyMatch(a *RiskAssessment, match *PolicyMatch) {
+	// 匹配整条命令的规则可能在多个片段上重复命中，只记录一次
+	for _, existing := range a.PolicyMatches {
+		if existing.Rule == match.Rule {
+			return
+		}
+	}
+	a.PolicyMatches = append(a.PolicyMatches, *match)
+
+	message := match.Message
+	if message == "" {
+		message = i18n.T("命中安全策略规则")
+	}
+	reason := i18n.T("策略规则 %s: %s", match.Rule, message)
+
+	switch match.Action {
+	case config.PolicyDeny:
+		a.Blocked = true
+		a.escalate(RiskCritical, reason)
+	case config.PolicyConfirm:
+		a.escalate(RiskCritical, reason)
+		a.setConfirmPhrase("yes")
+	case config.PolicyWarn:
+		a.escalate(RiskDangerous, reason)
+	}
+}
@@ -0,0 +1,300 @@
+package security
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/elecmonkey/prompt2cmd/internal/i18n"
+)
+
+// RiskLevel 命令风险等级
+type RiskLevel int
+
+const (
+	// RiskSafe 安全：只读或无副作用的命令
+	RiskSafe RiskLevel = iota
+	// RiskCaution 注意：会修改状态，但影响范围有限
+	RiskCaution
+	// RiskDangerous 危险：可能造成数据丢失或系统问题
+	RiskDangerous
+	// RiskCritical 严重：可能造成不可恢复的破坏
+	RiskCritical
+)
+
+// String 返回风险等级的英文标识
+func (l RiskLevel) String() string {
+	switch l {
+	case RiskSafe:
+		return "safe"
+	case RiskCaution:
+		return "caution"
+	case RiskDangerous:
+		return "dangerous"
+	case RiskCritical:
+		return "critical"
+	default:
+		return "unknown"
+	}
+}
+
+// Label 返回风险等级的显示名称
+func (l RiskLevel) Label() string {
+	switch l {
+	case RiskSafe:
+		return i18n.T("安全")
+	case RiskCaution:
+		return i18n.T("注意")
+	case RiskDangerous:
+		return i18n.T("危险")
+	case RiskCritical:
+		return i18n.T("严重")
+	default:
+		return i18n.T("未知")
+	}
+}
+
+// ParseRiskLevel 将英文标识解析为风险等级
+func ParseRiskLevel(s string) (RiskLevel, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "safe":
+		return RiskSafe, nil
+	case "caution":
+		return RiskCaution, nil
+	case "dangerous":
+		return RiskDangerous, nil
+	case "critical":
+		return RiskCritical, nil
+	default:
+		return RiskSafe, errors.New(i18n.T("无效的风险等级: %s", s))
+	}
+}
+
+// RiskAssessment 命令风险评估结果
+type RiskAssessment struct {
+	Level    RiskLevel // 风险等级
+	Reasons  []string  // 判定理由
+	ReadOnly bool      // 是否为只读命令
+	// ConfirmPhrase 严重级别命令需要用户完整输入的确认内容（目标路径或 yes）
+	ConfirmPhrase string
+	// Blocked 命令被安全策略禁止执行
+	Blocked bool
+	// PolicyMatches 命中的安全策略规则
+	PolicyMatches []PolicyMatch
+	// Detections 检测到的远程代码执行、数据外传、提权等模式
+	Detections []Detection
+	// Paths 命令将写入或删除的路径，命令不涉及写入时为 nil
+	Paths *PathGuardResult
+}
+
+// escalate 提升风险等级并记录理由，等级只升不降
+func (a *RiskAssessment) escalate(level RiskLevel, reason string) {
+	if level > a.Level {
+		a.Level = level
+	}
+	if reason != "" {
+		a.Reasons = append(a.Reasons, reason)
+	}
+}
+
+// CombineReview 合并模型复核的结论，两者不一致时以更严重的等级为准
+func (a *RiskAssessment) CombineReview(level RiskLevel, reason string) {
+	a.Escalate(level, i18n.T("模型复核: ")+reason)
+}
+
+// Escalate 按外部来源（模型复核、团队配方声明等）提升风险等级，等级只升不降
+// 高于安全等级时命令不再视为只读，升到严重等级时要求输入 yes 确认
+func (a *RiskAssessment) Escalate(level RiskLevel, reason string) {
+	a.escalate(level, reason)
+	if level > RiskSafe {
+		a.ReadOnly = false
+	}
+	if a.Level == RiskCritical && a.ConfirmPhrase == "" {
+		a.ConfirmPhrase = "yes"
+	}
+}
+
+// setConfirmPhrase 设置严重级别的确认内容，只保留第一个
+func (a *RiskAssessment) setConfirmPhrase(phrase string) {
+	if a.ConfirmPhrase == "" {
+		a.ConfirmPhrase = phrase
+	}
+}
+
+// 只读命令，不带写入选项时不会修改系统状态
+var readOnlyPrograms = map[string]bool{
+	"ls": true, "ll": true, "la": true, "cat": true, "pwd": true, "echo": true, "printf": true,
+	"grep": true, "egrep": true, "fgrep": true, "rg": true, "ag": true,
+	"head": true, "tail": true, "wc": true, "less": true, "more": true,
+	"du": true, "df": true, "ps": true, "whoami": true, "id": true, "groups": true,
+	"date": true, "cal": true, "uname": true, "hostname": true, "uptime": true, "free": true,
+	"which": true, "whereis": true, "type": true, "file": true, "stat": true, "tree": true,
+	"find": true, "fd": true, "locate": true, "mdfind": true,
+	"sort": true, "uniq": true, "cut": true, "tr": true, "column": true, "nl": true,
+	"diff": true, "cmp": true, "comm": true, "md5sum": true, "sha1sum": true, "sha256sum": true, "shasum": true,
+	"basename": true, "dirname": true, "realpath": true, "readlink": true,
+	"jq": true, "yq": true, "sed": true, "lsof": true, "netstat": true, "ss": true,
+	"env": true, "printenv": true, "history": true, "true": true, "false": true, "test": true,
+}
+
+// 只读的 git 子命令
+var readOnlyGitSubcommands = map[string]bool{
+	"status": true, "log": true, "diff": true, "show": true, "blame": true,
+	"describe": true, "shortlog": true, "ls-files": true, "rev-parse": true, "grep": true,
+}
+
+// isReadOnly 判断单条命令是否只读
+func isReadOnly(c *simpleCommand) bool {
+	for _, target := range c.OutputRedirects() {
+		if target != "/dev/null" {
+			return false
+		}
+	}
+
+	program := c.Program()
+	if program == "" {
+		return true
+	}
+	if c.Sudo {
+		return false
+	}
+
+	switch program {
+	case "git":
+		operands := c.Operands()
+		return len(operands) > 0 && readOnlyGitSubcommands[operands[0]]
+	case "find":
+		for _, arg := range c.Params() {
+			switch arg {
+			case "-delete", "-fprint", "-fprintf", "-fls":
+				return false
+			}
+		}
+		// -exec 执行的命令会被单独评估
+		return true
+	case "xargs", "sh", "bash", "zsh", "dash", "ksh":
+		// 间接执行的命令会被单独评估，这里只看自身是否带脚本文件
+		return len(c.nestedCommands()) > 0
+	case "sed":
+		return !c.HasFlag('i', "--in-place") && !hasPrefixArg(c, "--in-place=")
+	}
+
+	return readOnlyPrograms[program]
+}
+
+// hasPrefixArg 判断是否存在以指定前缀开头的参数
+func hasPrefixArg(c *simpleCommand, prefix string) bool {
+	for _, arg := range c.Params() {
+		if strings.HasPrefix(arg, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// 递归删除或修改时视为严重的目标
+var criticalTargets = map[string]bool{
+	"/": true, "/*": true, "~": true, "~/": true, "~/*": true,
+	"$HOME": true, "$HOME/": true, "$HOME/*": true, "${HOME}": true,
+	"/etc": true, "/usr": true, "/bin": true, "/sbin": true, "/lib": true, "/boot": true,
+	"/var": true, "/home": true, "/root": true, "/System": true, "/Users": true,
+	".": true, "./": true, "*": true, "..": true,
+}
+
+// assessBuiltinRules 使用内置规则评估单条命令
+func assessBuiltinRules(c *simpleCommand, a *RiskAssessment) {
+	program := c.Program()
+
+	switch {
+	case strings.HasPrefix(program, "mkfs") || program == "wipefs" || program == "mkswap":
+		a.escalate(RiskCritical, i18n.T("%s 会格式化或擦除文件系统", program))
+		a.setConfirmPhrase(lastOperand(c))
+	case program == "dd":
+		if of, ok := c.KeyValue("of"); ok && strings.HasPrefix(of, "/dev/") && of != "/dev/null" {
+			a.escalate(RiskCritical, i18n.T("dd 将直接写入设备 %s", of))
+			a.setConfirmPhrase(of)
+		} else {
+			a.escalate(RiskDangerous, i18n.T("dd 会直接按块复制或覆盖数据"))
+		}
+	case program == "shred":
+		a.escalate(RiskDangerous, i18n.T("shred 会不可恢复地覆盖文件内容"))
+		for _, target := range c.Operands() {
+			if strings.HasPrefix(target, "/dev/") {
+				a.escalate(RiskCritical, i18n.T("shred 将擦除设备 %s", target))
+				a.setConfirmPhrase(target)
+			}
+		}
+	case program == "fdisk" || program == "parted" || program == "sfdisk" || program == "gdisk":
+		a.escalate(RiskDangerous, i18n.T("%s 会修改磁盘分区表", program))
+	case program == "rm":
+		assessRemove(c, a)
+	case program == "find" && containsFold(c.Params(), "-delete"):
+		a.escalate(RiskDangerous, i18n.T("find -delete 会删除所有匹配的文件"))
+	case program == "chmod" || program == "chown" || program == "chgrp":
+		if c.HasFlag('R', "--recursive") {
+			a.escalate(RiskDangerous, i18n.T("%s -R 会递归修改权限或所有权", program))
+			for _, target := range c.Operands() {
+				if criticalTargets[target] {
+					a.escalate(RiskCritical, i18n.T("%s -R 的目标是 %s", program, target))
+					a.setConfirmPhrase(target)
+				}
+			}
+		}
+	case program == "shutdown" || program == "reboot" || program == "halt" || program == "poweroff":
+		a.escalate(RiskDangerous, i18n.T("%s 会关闭或重启系统", program))
+	case program == "kill" || program == "pkill" || program == "killall":
+		for _, arg := range c.Params() {
+			if arg == "-1" {
+				a.escalate(RiskCritical, i18n.T("kill -1 会向所有进程发送信号"))
+				a.setConfirmPhrase("yes")
+			}
+		}
+	}
+
+	for _, target := range c.OutputRedirects() {
+		switch {
+		case strings.HasPrefix(target, "/dev/sd") || strings.HasPrefix(target, "/dev/nvme") ||
+			strings.HasPrefix(target, "/dev/disk") || strings.HasPrefix(target, "/dev/hd") ||
+			strings.HasPrefix(target, "/dev/vd") || strings.HasPrefix(target, "/dev/mmcblk"):
+			a.escalate(RiskCritical, i18n.T("重定向将直接覆盖设备 %s", target))
+			a.setConfirmPhrase(target)
+		case strings.HasPrefix(target, "/etc/") || strings.HasPrefix(target, "/boot/"):
+			a.escalate(RiskDangerous, i18n.T("重定向将修改系统配置文件 %s", target))
+		}
+	}
+}
+
+// assessRemove 评估 rm 命令
+func assessRemove(c *simpleCommand, a *RiskAssessment) {
+	recursive := c.HasFlag('r', "--recursive") || c.HasFlag('R', "")
+	force := c.HasFlag('f', "--force")
+
+	if hasPrefixArg(c, "--no-preserve-root") {
+		a.escalate(RiskCritical, i18n.T("rm 使用了 --no-preserve-root"))
+		a.setConfirmPhrase("yes")
+	}
+
+	if c.ArgsFromInput {
+		a.escalate(RiskDangerous, i18n.T("xargs 交给 rm 的删除目标要在执行时才能确定"))
+	}
+
+	if recursive {
+		a.escalate(RiskDangerous, i18n.T("rm -r 会递归删除目录及其全部内容"))
+		for _, target := range c.Operands() {
+			if criticalTargets[target] {
+				a.escalate(RiskCritical, i18n.T("rm -r 的目标是 %s", target))
+				a.setConfirmPhrase(target)
+			}
+		}
+	} else if force {
+		a.escalate(RiskDangerous, i18n.T("rm -f 会不经确认强制删除文件"))
+	}
+}
+
+// lastOperand 返回最后一个非选项参数，没有时返回 yes
+func lastOperand(c *simpleCommand) string {
+	operands := c.Operands()
+	if len(operands) == 0 {
+		return "yes"
+	}
+	return operands[len(operands)-1]
+}
@@ -0,0 +1,230 @@
+package snippet
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/elecmonkey/prompt2cmd/internal/i18n"
+	"github.com/elecmonkey/prompt2cmd/internal/rank"
+	"github.com/elecmonkey/prompt2cmd/internal/security"
+)
+
+// DefaultMatchThreshold 需求与配方说明的默认匹配阈值（按 IDF 加权的词覆盖率）
+const DefaultMatchThreshold = 0.6
+
+// maxRecipeMatches 最多提供的候选配方数
+const maxRecipeMatches = 3
+
+// Recipe 团队共享的命令配方，保存在配方目录下的 YAML 文件中
+// 一个文件可以是单个配方，也可以是配方列表
+type Recipe struct {
+	Name        string   `yaml:"name"`
+	Description string   `yaml:"description"`
+	Command     string   `yaml:"command"`            // 命令模板，参数语法与个人片段相同
+	Keywords    []string `yaml:"keywords,omitempty"` // 额外的匹配关键词
+	Requires    []string `yaml:"requires,omitempty"` // 命令依赖的程序
+	Risk        string   `yaml:"risk,omitempty"`     // 配方声明的风险等级：safe、caution、dangerous、critical
+
+	// File 配方所在的文件
+	File string `yaml:"-"`
+}
+
+// RiskLevel 返回配方声明的风险等级，未声明时返回 false
+func (r *Recipe) RiskLevel() (security.RiskLevel, bool) {
+	if r.Risk == "" {
+		return security.RiskSafe, false
+	}
+	level, err := security.ParseRiskLevel(r.Risk)
+	return level, err == nil
+}
+
+// MissingTools 返回当前系统中找不到的依赖程序
+func (r *Recipe) MissingTools() []string {
+	var missing []string
+	for _, tool := range r.Requires {
+		if _, err := exec.LookPath(tool); err != nil {
+			missing = append(missing, tool)
+		}
+	}
+	return missing
+}
+
+// validate 检查配方的必填字段
+func (r *Recipe) validate() error {
+	if err := ValidateName(r.Name); err != nil {
+		return err
+	}
+	if strings.TrimSpace(r.Description) == "" {
+		return errors.New(i18n.T("配方 %s 缺少 description", r.Name))
+	}
+	if strings.TrimSpace(r.Command) == "" {
+		return errors.New(i18n.T("配方 %s 缺少 command", r.Name))
+	}
+	if r.Risk != "" {
+		if _, err := security.ParseRiskLevel(r.Risk); err != nil {
+			return errors.New(i18n.T("配方 %s: %s", r.Name, err.Error()))
+		}
+	}
+	return nil
+}
+
+// Catalog 从配方目录加载的团队配方
+type Catalog struct {
+	Recipes []Recipe
+	index   *rank.BM25
+}
+
+// LoadCatalog 加载目录（含子目录）中所有 .yaml/.yml 文件里的配方
+// 目录不存在时返回空的目录；无效的文件和配方会跳过，并在 problems 中说明原因
+func LoadCatalog(dir string) (*Catalog, []error) {
+	var recipes []Recipe
+	var problems []error
+	names := make(map[string]string)
+
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == dir {
+				return filepath.SkipDir
+			}
+			problems = append(problems, err)
+			return nil
+		}
+		if entry.IsDir() {
+			if path != dir && strings.HasPrefix(entry.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		ext := strings.ToLower(filepath.Ext(path))
+		if ext != ".yaml" && ext != ".yml" {
+			return nil
+		}
+
+		loaded, err := loadRecipeFile(path)
+		if err != nil {
+			problems = append(problems, fmt.Errorf("%s: %s", path, err.Error()))
+			return nil
+		}
+		for _, recipe := range loaded {
+			if err := recipe.validate(); err != nil {
+				problems = append(problems, fmt.Errorf("%s: %s", path, err.Error()))
+				continue
+			}
+			if previous, ok := names[recipe.Name]; ok {
+				problems = append(problems, errors.New(i18n.T("%s: 配方名称 %s 与 %s 重复，已跳过", path, recipe.Name, previous)))
+				continue
+			}
+			names[recipe.Name] = path
+			recipes = append(recipes, recipe)
+		}
+		return nil
+	})
+	if err != nil {
+		problems = append(problems, err)
+	}
+
+	sort.Slice(recipes, func(i, j int) bool {
+		return recipes[i].Name < recipes[j].Name
+	})
+	catalog := &Catalog{Recipes: recipes}
+	docs := make([][]string, len(recipes))
+	for i, recipe := range recipes {
+		docs[i] = rank.Tokenize(recipe.Name + " " + recipe.Description + " " + strings.Join(recipe.Keywords, " "))
+	}
+	catalog.index = rank.NewBM25(docs)
+	return catalog, problems
+}
+
+// loadRecipeFile 解析一个配方文件
+func loadRecipeFile(path string) ([]Recipe, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+	if len(node.Content) == 0 {
+		return nil, nil
+	}
+
+	var recipes []Recipe
+	switch node.Content[0].Kind {
+	case yaml.SequenceNode:
+		if err := node.Content[0].Decode(&recipes); err != nil {
+			return nil, err
+		}
+	case yaml.MappingNode:
+		var recipe Recipe
+		if err := node.Content[0].Decode(&recipe); err != nil {
+			return nil, err
+		}
+		recipes = append(recipes, recipe)
+	default:
+		return nil, errors.New(i18n.T("配方文件应为一个配方或配方列表"))
+	}
+	for i := range recipes {
+		recipes[i].File = path
+	}
+	return recipes, nil
+}
+
+// Get 按名称获取配方
+func (c *Catalog) Get(name string) (*Recipe, bool) {
+	for i := range c.Recipes {
+		if c.Recipes[i].Name == name {
+			return &c.Recipes[i], true
+		}
+	}
+	return nil, false
+}
+
+// Match 返回与需求匹配的配方，按匹配程度由高到低排列，最多 3 个
+// 匹配程度为需求中的词在配方名称、说明和关键词中出现的比例（按 IDF 加权），低于 threshold 的配方不返回
+func (c *Catalog) Match(prompt string, threshold float64) []Recipe {
+	if len(c.Recipes) == 0 {
+		return nil
+	}
+	query := rank.Tokenize(prompt)
+	if len(query) == 0 {
+		return nil
+	}
+
+	type scored struct {
+		position int
+		coverage float64
+		score    float64
+	}
+	var candidates []scored
+	for i := range c.Recipes {
+		coverage := c.index.Coverage(query, i)
+		if coverage < threshold {
+			continue
+		}
+		candidates = append(candidates, scored{position: i, coverage: coverage, score: c.index.Score(query, i)})
+	}
+	sort.SliceStable(candidates, func(a, b int) bool {
+		if candidates[a].coverage != candidates[b].coverage {
+			return candidates[a].coverage > candidates[b].coverage
+		}
+		return candidates[a].score > candidates[b].score
+	})
+	if len(candidates) > maxRecipeMatches {
+		candidates = candidates[:maxRecipeMatches]
+	}
+
+	matches := make([]Recipe, len(candidates))
+	for i, candidate := range candidates {
+		matches[i] = c.Recipes[candidate.position]
+	}
+	return matches
+}
@@ -0,0 +1,216 @@
+package snippet
+
+import (
+	"errors"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/elecmonkey/prompt2cmd/internal/i18n"
+)
+
+// Snippet 一条保存的命令片段，命令中可以包含 {{名称}} 或 {{名称:默认值}} 形式的参数
+type Snippet struct {
+	Name        string `json:"name"`
+	Command     string `json:"command"`               // 命令模板
+	Description string `json:"description,omitempty"` // 说明，通常是生成该命令时的需求
+	CreatedAt   string `json:"created_at"`
+	UpdatedAt   string `json:"updated_at,omitempty"`
+}
+
+// Param 命令模板中的一个参数
+type Param struct {
+	Name       string
+	Default    string
+	HasDefault bool
+}
+
+// placeholderPattern 匹配 {{名称}} 和 {{名称:默认值}}
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([\p{L}_][\p{L}\p{N}_-]*)\s*(?::([^{}]*))?\}\}`)
+
+// namePattern 片段名称只能包含字母、数字、下划线、连字符和点
+var namePattern = regexp.MustCompile(`^[\p{L}\p{N}_][\p{L}\p{N}_.-]*$`)
+
+// ValidateName 检查片段名称是否有效
+func ValidateName(name string) error {
+	if !namePattern.MatchString(name) {
+		return errors.New(i18n.T("无效的片段名称 %q：只能包含字母、数字、下划线、连字符和点", name))
+	}
+	return nil
+}
+
+// Params 返回命令模板中的参数，按首次出现的顺序排列，同名参数只返回一次
+func Params(command string) []Param {
+	var params []Param
+	seen := make(map[string]bool)
+	for _, match := range placeholderPattern.FindAllStringSubmatchIndex(command, -1) {
+		name := command[match[2]:match[3]]
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		param := Param{Name: name}
+		if match[4] >= 0 {
+			param.Default = strings.TrimSpace(command[match[4]:match[5]])
+			param.HasDefault = true
+		}
+		params = append(params, param)
+	}
+	return params
+}
+
+// MissingParams 返回没有提供值且没有默认值的参数
+func MissingParams(command string, values map[string]string) []string {
+	var missing []string
+	for _, param := range Params(command) {
+		if _, ok := values[param.Name]; !ok && !param.HasDefault {
+			missing = append(missing, param.Name)
+		}
+	}
+	return missing
+}
+
+// Render 用参数值替换命令模板中的占位符
+// 参数值中含有空格或 shell 特殊字符时会被加上引号，避免改变命令的结构；
+// 占位符已经位于引号中时（如 grep "{{pattern}}"）只转义在该引号中有特殊含义的字符。
+// 缺少参数或提供了模板中没有的参数时返回错误
+func Render(command string, values map[string]string) (string, error) {
+	if missing := MissingParams(command, values); len(missing) > 0 {
+		return "", errors.New(i18n.T("缺少参数: %s", strings.Join(missing, ", ")))
+	}
+
+	known := make(map[string]bool)
+	for _, param := range Params(command) {
+		known[param.Name] = true
+	}
+	var unknown []string
+	for name := range values {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return "", errors.New(i18n.T("片段中没有这些参数: %s", strings.Join(unknown, ", ")))
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, match := range placeholderPattern.FindAllStringSubmatchIndex(command, -1) {
+		value, ok := values[command[match[2]:match[3]]]
+		if !ok && match[4] >= 0 {
+			value = strings.TrimSpace(command[match[4]:match[5]])
+		}
+		b.WriteString(command[last:match[0]])
+		b.WriteString(quoteIn(value, quoteAt(command, match[0])))
+		last = match[1]
+	}
+	b.WriteString(command[last:])
+	return b.String(), nil
+}
+
+// quoteAt 返回命令中 pos 位置所在的引号（' 或 "），不在引号中时返回 0
+func quoteAt(command string, pos int) rune {
+	var quote rune
+	escaped := false
+	for _, r := range command[:pos] {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		}
+	}
+	return quote
+}
+
+// quoteIn 按占位符所在的引号转义参数值
+func quoteIn(value string, quote rune) string {
+	switch {
+	case quote == '"' && runtime.GOOS == "windows":
+		return strings.ReplaceAll(value, `"`, `""`)
+	case quote == '"':
+		return doubleQuoteEscaper.Replace(value)
+	case quote == '\'' && runtime.GOOS != "windows":
+		// 先结束单引号，加上转义的单引号后再重新开始
+		return strings.ReplaceAll(value, "'", `'\''`)
+	default:
+		return QuoteValue(value)
+	}
+}
+
+// doubleQuoteEscaper 转义在双引号中仍有特殊含义的字符
+var doubleQuoteEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "`", "\\`")
+
+// SplitArgs 按空白分割参数，单引号或双引号中的空白不分割，引号本身会被去掉
+func SplitArgs(input string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	inArg := false
+	var quote rune
+	for _, r := range input {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inArg = true
+		case unicode.IsSpace(r):
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, errors.New(i18n.T("引号没有闭合: %s", input))
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
+
+// ParseAssignments 解析 key=value 形式的参数
+func ParseAssignments(args []string) (map[string]string, error) {
+	values := make(map[string]string)
+	for _, arg := range args {
+		name, value, ok := strings.Cut(arg, "=")
+		if !ok || name == "" {
+			return nil, errors.New(i18n.T("无效的参数 %q，请使用 名称=值 的形式", arg))
+		}
+		values[name] = value
+	}
+	return values, nil
+}
+
+// safeValuePattern 不需要加引号的参数值
+var safeValuePattern = regexp.MustCompile(`^[\p{L}\p{N}_@%+=:,./-]+$`)
+
+// QuoteValue 在需要时为参数值加上引号，使其在命令中作为一个完整的参数
+// Windows 使用双引号，其他系统使用单引号
+func QuoteValue(value string) string {
+	if safeValuePattern.MatchString(value) {
+		return value
+	}
+	if runtime.GOOS == "windows" {
+		return `"` + strings.ReplaceAll(value, `"`, `""`) + `"`
+	}
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
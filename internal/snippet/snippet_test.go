@@ -0,0 +1,122 @@
+package snippet
+
+import (
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func TestParams(t *testing.T) {
+	got := Params(`grep -rn {{ pattern }} {{dir:.}} | head -n {{count: 20 }} && echo {{pattern}} {{dir:/tmp}}`)
+	want := []Param{
+		{Name: "pattern"},
+		{Name: "dir", Default: ".", HasDefault: true},
+		{Name: "count", Default: "20", HasDefault: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Params = %+v, want %+v", got, want)
+	}
+
+	if got := Params("echo {{}} {{1abc}} {{ }} plain"); len(got) != 0 {
+		t.Errorf("Params of invalid placeholders = %+v, want none", got)
+	}
+	if got := Params("echo {{名称}} {{empty:}}"); len(got) != 2 || got[0].Name != "名称" || !got[1].HasDefault || got[1].Default != "" {
+		t.Errorf("Params with a Unicode name and an empty default = %+v", got)
+	}
+}
+
+func TestRender(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("quoting rules differ on Windows")
+	}
+
+	tests := []struct {
+		command string
+		values  map[string]string
+		want    string
+	}{
+		{"ls {{dir}}", map[string]string{"dir": "/var/log"}, "ls /var/log"},
+		{"ls {{dir}}", map[string]string{"dir": "my files"}, "ls 'my files'"},
+		{"ls {{dir}}", map[string]string{"dir": "it's; rm -rf /"}, `ls 'it'\''s; rm -rf /'`},
+		{"ls {{dir:.}}", nil, "ls ."},
+		{"ls {{dir:my files}}", nil, "ls 'my files'"},
+		{"ls {{dir:.}}", map[string]string{"dir": ""}, "ls ''"},
+		{`grep "{{pattern}}" log`, map[string]string{"pattern": "a b"}, `grep "a b" log`},
+		{`grep "{{pattern}}" log`, map[string]string{"pattern": `$(id) "x" \ y`}, `grep "\$(id) \"x\" \\ y" log`},
+		{`grep "prefix {{pattern}} suffix" log`, map[string]string{"pattern": "a`b`"}, "grep \"prefix a\\`b\\` suffix\" log"},
+		{`echo '{{x}}'`, map[string]string{"x": "a b"}, `echo 'a b'`},
+		{`echo '{{x}}'`, map[string]string{"x": "it's"}, `echo 'it'\''s'`},
+		{`echo "it's" {{x}}`, map[string]string{"x": "a b"}, `echo "it's" 'a b'`},
+		{`echo 'say "hi"' {{x}}`, map[string]string{"x": "a b"}, `echo 'say "hi"' 'a b'`},
+		{`echo \"{{x}}`, map[string]string{"x": "a b"}, `echo \"'a b'`},
+		{`echo "a\"{{x}}"`, map[string]string{"x": "a b"}, `echo "a\"a b"`},
+		{"cp {{src}} {{dst}} && ls {{dst}}", map[string]string{"src": "a", "dst": "b c"}, "cp a 'b c' && ls 'b c'"},
+	}
+	for _, tt := range tests {
+		got, err := Render(tt.command, tt.values)
+		if err != nil {
+			t.Errorf("Render(%q) error: %v", tt.command, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Render(%q, %v) = %s, want %s", tt.command, tt.values, got, tt.want)
+		}
+	}
+}
+
+func TestRenderErrors(t *testing.T) {
+	if _, err := Render("ls {{dir}}", nil); err == nil {
+		t.Errorf("Render with a missing parameter succeeded, want an error")
+	}
+	if _, err := Render("ls {{dir}}", map[string]string{"dir": ".", "extra": "x"}); err == nil {
+		t.Errorf("Render with an unknown parameter succeeded, want an error")
+	}
+	if missing := MissingParams("cp {{src}} {{dst:.}}", map[string]string{}); !reflect.DeepEqual(missing, []string{"src"}) {
+		t.Errorf("MissingParams = %v, want [src]", missing)
+	}
+}
+
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"", nil},
+		{"  a   b\tc  ", []string{"a", "b", "c"}},
+		{`dir="my files" count=3`, []string{"dir=my files", "count=3"}},
+		{`msg='say "hi"' x=""`, []string{`msg=say "hi"`, "x="}},
+		{`a"b c"d`, []string{"ab cd"}},
+		{`''`, []string{""}},
+	}
+	for _, tt := range tests {
+		got, err := SplitArgs(tt.input)
+		if err != nil {
+			t.Errorf("SplitArgs(%q) error: %v", tt.input, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("SplitArgs(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+
+	if _, err := SplitArgs(`dir="unterminated`); err == nil {
+		t.Errorf("SplitArgs with an unterminated quote succeeded, want an error")
+	}
+}
+
+func TestParseAssignments(t *testing.T) {
+	got, err := ParseAssignments([]string{"dir=/tmp", "query=a=b", "empty="})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"dir": "/tmp", "query": "a=b", "empty": ""}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseAssignments = %v, want %v", got, want)
+	}
+
+	for _, args := range [][]string{{"novalue"}, {"=value"}} {
+		if _, err := ParseAssignments(args); err == nil {
+			t.Errorf("ParseAssignments(%q) succeeded, want an error", args)
+		}
+	}
+}
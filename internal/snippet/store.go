@@ -0,0 +1,198 @@
+package snippet
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/elecmonkey/prompt2cmd/internal/filelock"
+	"github.com/elecmonkey/prompt2cmd/internal/i18n"
+	"github.com/elecmonkey/prompt2cmd/internal/xdg"
+)
+
+// fileVersion 片段文件的格式版本
+const fileVersion = 1
+
+// ErrNotFound 片段不存在
+var ErrNotFound = i18n.Error("片段不存在")
+
+// snippetFile 片段文件的结构
+type snippetFile struct {
+	Version  int       `json:"version"`
+	Snippets []Snippet `json:"snippets"`
+}
+
+// Store 保存在 JSON 文件中的个人片段
+// 每次读写都直接访问文件，并在修改时加文件锁，多个会话可以同时使用
+type Store struct {
+	path string
+}
+
+// DefaultPath 返回默认的片段文件路径 $XDG_DATA_HOME/prompt2cmd/snippets.json
+// 旧版本的 ~/.prompt2cmd/snippets.json 存在时继续使用
+func DefaultPath() string {
+	return xdg.DataFile("snippets.json")
+}
+
+// NewStore 创建片段存储，path 为空时使用默认路径
+func NewStore(path string) *Store {
+	if path == "" {
+		path = DefaultPath()
+	}
+	return &Store{path: path}
+}
+
+// Path 返回片段文件路径
+func (s *Store) Path() string {
+	return s.path
+}
+
+// List 返回全部片段，按名称排序
+func (s *Store) List() ([]Snippet, error) {
+	snippets, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(snippets, func(i, j int) bool {
+		return snippets[i].Name < snippets[j].Name
+	})
+	return snippets, nil
+}
+
+// Get 按名称获取片段，不存在时返回 ErrNotFound
+func (s *Store) Get(name string) (*Snippet, error) {
+	snippets, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	for i := range snippets {
+		if snippets[i].Name == name {
+			return &snippets[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+}
+
+// Save 保存片段，同名片段会被覆盖，返回是否覆盖了已有的片段
+func (s *Store) Save(snippet Snippet) (bool, error) {
+	if err := ValidateName(snippet.Name); err != nil {
+		return false, err
+	}
+	if snippet.Command == "" {
+		return false, errors.New(i18n.T("片段的命令不能为空"))
+	}
+
+	replaced := false
+	err := s.update(func(snippets []Snippet) ([]Snippet, error) {
+		now := time.Now().Format(time.RFC3339)
+		for i := range snippets {
+			if snippets[i].Name == snippet.Name {
+				snippet.CreatedAt = snippets[i].CreatedAt
+				snippet.UpdatedAt = now
+				snippets[i] = snippet
+				replaced = true
+				return snippets, nil
+			}
+		}
+		snippet.CreatedAt = now
+		return append(snippets, snippet), nil
+	})
+	return replaced, err
+}
+
+// Delete 按名称删除片段，不存在时返回 ErrNotFound
+func (s *Store) Delete(name string) error {
+	return s.update(func(snippets []Snippet) ([]Snippet, error) {
+		for i := range snippets {
+			if snippets[i].Name == name {
+				return append(snippets[:i], snippets[i+1:]...), nil
+			}
+		}
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	})
+}
+
+// read 读取片段文件，文件不存在时返回空列表
+func (s *Store) read() ([]Snippet, error) {
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return []Snippet{}, nil
+	}
+	if err != nil {
+		return nil, errors.New(i18n.T("读取片段文件失败: %s", err.Error()))
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return []Snippet{}, nil
+	}
+
+	var file snippetFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, errors.New(i18n.T("解析片段文件失败: %s", err.Error()))
+	}
+	if file.Version > fileVersion {
+		return nil, errors.New(i18n.T("片段文件版本 %d 高于当前程序支持的版本 %d，请升级 prompt2cmd", file.Version, fileVersion))
+	}
+	if file.Snippets == nil {
+		file.Snippets = []Snippet{}
+	}
+	return file.Snippets, nil
+}
+
+// update 在文件锁内读取最新的片段，应用修改后原子地写回
+func (s *Store) update(modify func(snippets []Snippet) ([]Snippet, error)) error {
+	lock := filelock.New(s.path + ".lock")
+	if err := lock.Lock(); err != nil {
+		return errors.New(i18n.T("锁定片段文件失败: %s", err.Error()))
+	}
+	defer lock.Unlock()
+
+	snippets, err := s.read()
+	if err != nil {
+		return err
+	}
+	snippets, err = modify(snippets)
+	if err != nil {
+		return err
+	}
+	return s.write(snippets)
+}
+
+// write 先写入同目录的临时文件再重命名，避免写入中途崩溃损坏文件
+func (s *Store) write(snippets []Snippet) error {
+	data, err := json.MarshalIndent(snippetFile{Version: fileVersion, Snippets: snippets}, "", "  ")
+	if err != nil {
+		return errors.New(i18n.T("序列化片段失败: %s", err.Error()))
+	}
+
+	dir := filepath.Dir(s.path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return errors.New(i18n.T("创建片段目录失败: %s", err.Error()))
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(s.path)+".tmp.*")
+	if err != nil {
+		return errors.New(i18n.T("创建临时片段文件失败: %s", err.Error()))
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return errors.New(i18n.T("写入片段文件失败: %s", err.Error()))
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return errors.New(i18n.T("写入片段文件失败: %s", err.Error()))
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.New(i18n.T("写入片段文件失败: %s", err.Error()))
+	}
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		return errors.New(i18n.T("写入片段文件失败: %s", err.Error()))
+	}
+	return nil
+}
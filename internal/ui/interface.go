@@ -2,6 +2,9 @@ package ui
 
 import (
 	"errors"
+
+	"github.com/elecmonkey/prompt2cmd/internal/i18n"
+	"github.com/elecmonkey/prompt2cmd/internal/security"
 )
 
 // UserInterface 用户界面接口
@@ -14,6 +17,12 @@ type UserInterface interface {
 	
 	// GetUserConfirmation 获取用户确认
 	GetUserConfirmation() (bool, error)
+
+	// DisplayRiskAssessment 显示命令的风险评估
+	DisplayRiskAssessment(assessment *security.RiskAssessment)
+
+	// GetRiskConfirmation 根据风险等级获取用户确认，等级越高要求越严格
+	GetRiskConfirmation(assessment *security.RiskAssessment) (bool, error)
 	
 	// DisplayExecutionResult 显示执行结果
 	DisplayExecutionResult(result string)
@@ -33,7 +42,7 @@ type DefaultInputValidator struct{}
 // ValidateInput 验证用户输入
 func (v *DefaultInputValidator) ValidateInput(input string) error {
 	if input == "" {
-		return errors.New("输入不能为空")
+		return errors.New(i18n.T("输入不能为空"))
 	}
 	return nil
 } 
\ No newline at end of file
@@ -7,6 +7,9 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/elecmonkey/prompt2cmd/internal/i18n"
+	"github.com/elecmonkey/prompt2cmd/internal/security"
 )
 
 // TerminalUI 终端用户界面
@@ -28,7 +31,7 @@ func (ui *TerminalUI) GetUserInput() (string, error) {
 	// 获取当前路径
 	currentPath, err := os.Getwd()
 	if err != nil {
-		currentPath = "未知路径"
+		currentPath = i18n.T("未知路径")
 	}
 	
 	// 获取用户主目录
@@ -38,10 +41,10 @@ func (ui *TerminalUI) GetUserInput() (string, error) {
 		currentPath = filepath.Join("~", strings.TrimPrefix(currentPath, homeDir))
 	}
 	
-	fmt.Printf("\n🤖 (%s) 你想要：", currentPath)
+	fmt.Printf("\n🤖 (%s) %s", currentPath, i18n.T("你想要："))
 	input, err := ui.reader.ReadString('\n')
 	if err != nil {
-		return "", errors.New("读取输入失败: " + err.Error())
+		return "", errors.New(i18n.T("读取输入失败: %s", err.Error()))
 	}
 	
 	// 清理输入
@@ -58,18 +61,18 @@ func (ui *TerminalUI) GetUserInput() (string, error) {
 
 // DisplayGeneratedCommand 显示生成的命令和解释
 func (ui *TerminalUI) DisplayGeneratedCommand(command string, explanation string) {
-	fmt.Println("\n📝 生成的命令:")
+	fmt.Println("\n📝 " + i18n.T("生成的命令:"))
 	fmt.Printf("   \033[1;36m%s\033[0m\n", command)
-	fmt.Println("\n📋 命令解释:")
+	fmt.Println("\n📋 " + i18n.T("命令解释:"))
 	fmt.Printf("   %s\n", explanation)
 }
 
 // GetUserConfirmation 获取用户确认
 func (ui *TerminalUI) GetUserConfirmation() (bool, error) {
-	fmt.Print("\n❓ 是否执行此命令? (y/n/e[编辑]): ")
+	fmt.Print("\n❓ " + i18n.T("是否执行此命令? (y/n/e[编辑]): "))
 	input, err := ui.reader.ReadString('\n')
 	if err != nil {
-		return false, errors.New("读取输入失败: " + err.Error())
+		return false, errors.New(i18n.T("读取输入失败: %s", err.Error()))
 	}
 	
 	input = strings.TrimSpace(strings.ToLower(input))
@@ -83,13 +86,78 @@ func (ui *TerminalUI) GetUserConfirmation() (bool, error) {
 		// 返回特殊错误，表示用户想编辑命令
 		return false, errors.New("EDIT_COMMAND")
 	default:
-		return false, errors.New("无效输入，请输入 y/n/e")
+		return false, errors.New(i18n.T("无效输入，请输入 y/n/e"))
+	}
+}
+
+// DisplayRiskAssessment 显示命令的风险评估
+func (ui *TerminalUI) DisplayRiskAssessment(assessment *security.RiskAssessment) {
+	if assessment == nil || assessment.Level == security.RiskSafe {
+		return
+	}
+
+	color := "\033[1;33m" // 黄色
+	emoji := "⚠️"
+	if assessment.Level >= security.RiskDangerous {
+		color = "\033[1;31m" // 红色
+		emoji = "🚨"
+	}
+	if assessment.Level == security.RiskCaution {
+		emoji = "ℹ️"
+	}
+
+	if assessment.Blocked {
+		emoji = "⛔"
+		fmt.Printf("\n%s %s%s\033[0m\n", emoji, color, i18n.T("命令被安全策略禁止执行"))
+	} else {
+		fmt.Printf("\n%s %s%s%s\033[0m\n", emoji, i18n.T("风险等级: "), color, assessment.Level.Label())
+	}
+	for _, reason := range assessment.Reasons {
+		fmt.Printf("   - %s\n", reason)
+	}
+
+	// 危险命令列出将被写入或删除的路径
+	if assessment.Paths != nil && assessment.Level >= security.RiskDangerous {
+		fmt.Printf("\n📂 %s\n", assessment.Paths.Summary(20))
+	}
+}
+
+// GetRiskConfirmation 根据风险等级获取用户确认
+// 安全、注意和危险级别输入 y 即可，严重级别需要完整输入目标路径或 yes
+func (ui *TerminalUI) GetRiskConfirmation(assessment *security.RiskAssessment) (bool, error) {
+	if assessment == nil || assessment.Level < security.RiskCritical {
+		return ui.GetUserConfirmation()
+	}
+
+	phrase := assessment.ConfirmPhrase
+	if phrase == "" {
+		phrase = "yes"
+	}
+
+	fmt.Print("\n❗ " + i18n.T("这是一个严重风险操作。请输入 %s 确认执行 (n取消/e编辑): ", "\033[1;31m"+phrase+"\033[0m"))
+	input, err := ui.reader.ReadString('\n')
+	if err != nil {
+		return false, errors.New(i18n.T("读取输入失败: %s", err.Error()))
+	}
+
+	input = strings.TrimSpace(input)
+
+	switch strings.ToLower(input) {
+	case "n", "no", "否":
+		return false, nil
+	case "e", "edit", "编辑":
+		return false, errors.New("EDIT_COMMAND")
+	}
+
+	if input == phrase {
+		return true, nil
 	}
+	return false, errors.New(i18n.T("确认内容不匹配，请完整输入 %s", phrase))
 }
 
 // DisplayExecutionResult 显示执行结果
 func (ui *TerminalUI) DisplayExecutionResult(result string) {
-	fmt.Println("\n🔍 执行结果:")
+	fmt.Println("\n🔍 " + i18n.T("执行结果:"))
 	fmt.Println("--------------------------------------------------")
 	fmt.Println(result)
 	fmt.Println("--------------------------------------------------")
@@ -101,5 +169,5 @@ func (ui *TerminalUI) DisplayError(err error) {
 		return
 	}
 	
-	fmt.Printf("\n❌ 错误: %s\n", err.Error())
+	fmt.Printf("\n❌ %s%s\n", i18n.T("错误: "), err.Error())
 } 
\ No newline at end of file
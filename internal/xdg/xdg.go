@@ -0,0 +1,104 @@
+package xdg
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// appName 各个基础目录下的程序目录名
+const appName = "prompt2cmd"
+
+// baseDir 返回环境变量指定的基础目录，未设置或不是绝对路径时使用主目录下的默认目录
+// 按照 XDG 基础目录规范，相对路径的设置应当被忽略
+func baseDir(env string, fallback ...string) string {
+	if dir := os.Getenv(env); dir != "" && filepath.IsAbs(dir) {
+		return dir
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(fallback...)
+	}
+	return filepath.Join(append([]string{homeDir}, fallback...)...)
+}
+
+// ConfigHome 返回 $XDG_CONFIG_HOME，默认为 ~/.config
+func ConfigHome() string {
+	return baseDir("XDG_CONFIG_HOME", ".config")
+}
+
+// DataHome 返回 $XDG_DATA_HOME，默认为 ~/.local/share
+func DataHome() string {
+	return baseDir("XDG_DATA_HOME", ".local", "share")
+}
+
+// StateHome 返回 $XDG_STATE_HOME，默认为 ~/.local/state
+func StateHome() string {
+	return baseDir("XDG_STATE_HOME", ".local", "state")
+}
+
+// ConfigDirs 返回 $XDG_CONFIG_DIRS 中的系统配置目录，按优先级从高到低排列，默认为 /etc/xdg
+func ConfigDirs() []string {
+	var dirs []string
+	for _, dir := range strings.Split(os.Getenv("XDG_CONFIG_DIRS"), string(os.PathListSeparator)) {
+		if dir != "" && filepath.IsAbs(dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+	if len(dirs) == 0 {
+		dirs = []string{"/etc/xdg"}
+	}
+	return dirs
+}
+
+// LegacyDir 返回旧版本使用的目录 ~/.prompt2cmd
+func LegacyDir() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ".prompt2cmd"
+	}
+	return filepath.Join(homeDir, ".prompt2cmd")
+}
+
+// ConfigDir 返回程序的配置目录 $XDG_CONFIG_HOME/prompt2cmd
+func ConfigDir() string {
+	return filepath.Join(ConfigHome(), appName)
+}
+
+// DataDir 返回程序的数据目录 $XDG_DATA_HOME/prompt2cmd
+func DataDir() string {
+	return filepath.Join(DataHome(), appName)
+}
+
+// StateDir 返回程序的状态目录 $XDG_STATE_HOME/prompt2cmd
+func StateDir() string {
+	return filepath.Join(StateHome(), appName)
+}
+
+// ConfigFile 返回配置目录中的文件路径，旧目录 ~/.prompt2cmd 中已有同名文件时继续使用旧文件
+func ConfigFile(name string) string {
+	return resolve(ConfigDir(), name)
+}
+
+// DataFile 返回数据目录中的文件路径，旧目录 ~/.prompt2cmd 中已有同名文件时继续使用旧文件
+func DataFile(name string) string {
+	return resolve(DataDir(), name)
+}
+
+// StateFile 返回状态目录中的文件路径，旧目录 ~/.prompt2cmd 中已有同名文件时继续使用旧文件
+func StateFile(name string) string {
+	return resolve(StateDir(), name)
+}
+
+// resolve 优先使用 XDG 目录中已存在的文件，其次是旧目录中已存在的文件，都不存在时使用 XDG 目录
+func resolve(dir, name string) string {
+	path := filepath.Join(dir, name)
+	if _, err := os.Stat(path); err == nil {
+		return path
+	}
+	legacy := filepath.Join(LegacyDir(), name)
+	if _, err := os.Stat(legacy); err == nil {
+		return legacy
+	}
+	return path
+}